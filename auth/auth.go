@@ -1,41 +1,67 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"todo/constants"
+
 	"github.com/dgrijalva/jwt-go"
 )
 
-const hello string = "key"
-
-//CreateToken creates a jwt token for the user
-func CreateToken(user_id int) (string, error) {
+//CreateToken creates a jwt token for the user, bound to the session it was issued for and carrying the role
+func CreateToken(user_id int, session_id int, role string) (string, error) {
 	claims := jwt.MapClaims{}
 	claims["authorized"] = true
 	claims["user_id"] = user_id
-	claims["exp"] = time.Now().Add(time.Hour * 12).Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(hello))
+	claims["session_id"] = session_id
+	claims["role"] = role
+	claims["exp"] = time.Now().Add(constants.AccessTokenTTL).Unix()
+	return signToken(claims)
 
 }
 
+//CreateChallengeToken creates a short lived token proving the password was checked,
+//it is exchanged for a real token once the second factor is verified
+func CreateChallengeToken(user_id int) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["purpose"] = "2fa"
+	claims["user_id"] = user_id
+	claims["exp"] = time.Now().Add(constants.ChallengeTokenTTL).Unix()
+	return signToken(claims)
+}
+
+//ParseChallengeToken returns the user id of a valid challenge token
+func ParseChallengeToken(tokenString string) (int, error) {
+	token, err := jwt.Parse(tokenString, keyFunc)
+	if err != nil {
+		return 0, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["purpose"] != "2fa" {
+		return 0, errors.New("invalid challenge token")
+	}
+	uid, err := strconv.ParseUint(fmt.Sprintf("%.0f", claims["user_id"]), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int(uid), nil
+}
+
 // TokenValid checks the token validity
 func TokenValid(r *http.Request) error {
-	tokenString := ExtractToken(r)
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(hello), nil
-	})
+	_, err := parseToken(r)
 	if err != nil {
 		return err
 	}
-	_ = token
 	return nil
 }
 
@@ -49,23 +75,63 @@ func ExtractToken(r *http.Request) string {
 
 //ExtractTokenID extracts Id from  token
 func ExtractTokenID(r *http.Request) (int, error) {
+	return extractIntClaim(r, "user_id")
+}
+
+//ExtractTokenSessionID extracts the session id from token
+func ExtractTokenSessionID(r *http.Request) (int, error) {
+	return extractIntClaim(r, "session_id")
+}
+
+//ExtractTokenRole extracts the role of the user from token
+func ExtractTokenRole(r *http.Request) (string, error) {
+	token, err := parseToken(r)
+	if err != nil {
+		return "", err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if ok && token.Valid {
+		role, _ := claims["role"].(string)
+		return role, nil
+	}
+	return "", nil
+}
+
+//NewRandomToken generates an opaque url safe token, used for refresh tokens
+func NewRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+//HashToken hashes an opaque token before it is saved into or looked up in the database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseToken(r *http.Request) (*jwt.Token, error) {
 	tokenString := ExtractToken(r)
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(hello), nil
-	})
+	return jwt.Parse(tokenString, keyFunc)
+}
+
+func extractIntClaim(r *http.Request, name string) (int, error) {
+	token, err := parseToken(r)
 	if err != nil {
 		return 0, err
 	}
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if ok && token.Valid {
-		uid, err := strconv.ParseUint(fmt.Sprintf("%.0f", claims["user_id"]), 10, 32)
+		if _, found := claims[name]; !found {
+			return 0, errors.New("token has no " + name)
+		}
+		value, err := strconv.ParseUint(fmt.Sprintf("%.0f", claims[name]), 10, 32)
 		if err != nil {
 			return 0, err
 		}
-		return int(uid), nil
+		return int(value), nil
 	}
 	return 0, nil
 }
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"todo/constants"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// legacyKey is the secret every token was signed with before keys became configurable,
+// it is only used when nothing is configured so local setups keep working
+const legacyKey string = "key"
+
+// keyringFile is the format of the file named by JWT_KEYRING_FILE, e.g.
+//
+//	{
+//		"current": "2021-06",
+//		"keys": [
+//			{"kid": "2021-06", "alg": "EdDSA", "privateKey": "ed25519.pem", "publicKey": "ed25519.pub.pem"},
+//			{"kid": "2021-01", "alg": "HS256", "secret": "retired but still accepted"}
+//		]
+//	}
+//
+// New tokens are signed with the current key, every key listed is accepted for verification.
+// Key file paths are relative to the keyring file.
+type keyringFile struct {
+	Current string        `json:"current"`
+	Keys    []keyringItem `json:"keys"`
+}
+
+type keyringItem struct {
+	Kid        string `json:"kid"`
+	Alg        string `json:"alg"`
+	Secret     string `json:"secret"`
+	PrivateKey string `json:"privateKey"`
+	PublicKey  string `json:"publicKey"`
+}
+
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type keyring struct {
+	current *signingKey
+	keys    map[string]*signingKey
+}
+
+// JWK is the public part of an asymmetric key, as published to other services
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+var keys *keyring
+
+//InitKeys loads the jwt keyring from configuration
+func InitKeys() error {
+	var err error
+	if path := os.Getenv(constants.JWTKeyringFileEnv); path != "" {
+		keys, err = loadKeyring(path)
+		return err
+	}
+	secret := os.Getenv(constants.JWTSecretEnv)
+	if secret == "" {
+		log.Printf("%s and %s are not set, signing tokens with the insecure development key", constants.JWTKeyringFileEnv, constants.JWTSecretEnv)
+		secret = legacyKey
+	}
+	key := &signingKey{
+		kid:       "default",
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	keys = &keyring{
+		current: key,
+		keys:    map[string]*signingKey{key.kid: key},
+	}
+	return nil
+}
+
+//PublicKeys returns the verification keys that can be shared, symmetric keys are never published
+func PublicKeys() []JWK {
+	jwks := []JWK{}
+	if keys == nil {
+		return jwks
+	}
+	for _, key := range keys.keys {
+		switch verifyKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kid: key.kid,
+				Kty: "RSA",
+				Alg: key.method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(verifyKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(verifyKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				Kid: key.kid,
+				Kty: "OKP",
+				Alg: key.method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(verifyKey),
+			})
+		}
+	}
+	return jwks
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	if keys == nil {
+		return "", errors.New("signing keys are not initialised")
+	}
+	token := jwt.NewWithClaims(keys.current.method, claims)
+	token.Header["kid"] = keys.current.kid
+	return token.SignedString(keys.current.signKey)
+}
+
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	if keys == nil {
+		return nil, errors.New("signing keys are not initialised")
+	}
+	key := keys.current
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = keys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %v", kid)
+		}
+	}
+	// the alg header must match the key, otherwise a public key could be used as an hmac secret
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+func loadKeyring(path string) (*keyring, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyringFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid keyring %s: %v", path, err)
+	}
+	ring := &keyring{keys: map[string]*signingKey{}}
+	dir := filepath.Dir(path)
+	for _, item := range file.Keys {
+		if item.Kid == "" {
+			return nil, errors.New("keyring: every key needs a kid")
+		}
+		if _, exists := ring.keys[item.Kid]; exists {
+			return nil, fmt.Errorf("keyring: duplicate kid %s", item.Kid)
+		}
+		key, err := loadKey(dir, item)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %s: %v", item.Kid, err)
+		}
+		ring.keys[item.Kid] = key
+	}
+	current, ok := ring.keys[file.Current]
+	if !ok {
+		return nil, fmt.Errorf("keyring: current key %q not found", file.Current)
+	}
+	if current.signKey == nil {
+		return nil, fmt.Errorf("keyring: current key %q has no private key", file.Current)
+	}
+	ring.current = current
+	return ring, nil
+}
+
+func loadKey(dir string, item keyringItem) (*signingKey, error) {
+	key := &signingKey{kid: item.Kid}
+	switch item.Alg {
+	case "HS256", "HS384", "HS512":
+		if item.Secret == "" {
+			return nil, errors.New("secret is empty")
+		}
+		key.method = jwt.GetSigningMethod(item.Alg)
+		key.signKey = []byte(item.Secret)
+		key.verifyKey = []byte(item.Secret)
+	case "RS256", "RS384", "RS512":
+		key.method = jwt.GetSigningMethod(item.Alg)
+		if item.PrivateKey != "" {
+			pemBytes, err := readKeyFile(dir, item.PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = privateKey
+			key.verifyKey = &privateKey.PublicKey
+		}
+		if item.PublicKey != "" {
+			pemBytes, err := readKeyFile(dir, item.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = publicKey
+		}
+	case "EdDSA":
+		key.method = SigningMethodEdDSA
+		if item.PrivateKey != "" {
+			parsed, err := parsePEM(dir, item.PrivateKey, x509.ParsePKCS8PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			privateKey, ok := parsed.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("private key is not an ed25519 key")
+			}
+			key.signKey = privateKey
+			key.verifyKey = privateKey.Public()
+		}
+		if item.PublicKey != "" {
+			parsed, err := parsePEM(dir, item.PublicKey, x509.ParsePKIXPublicKey)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, ok := parsed.(ed25519.PublicKey)
+			if !ok {
+				return nil, errors.New("public key is not an ed25519 key")
+			}
+			key.verifyKey = publicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", item.Alg)
+	}
+	if key.verifyKey == nil {
+		return nil, errors.New("no key material")
+	}
+	return key, nil
+}
+
+func readKeyFile(dir, name string) ([]byte, error) {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	return ioutil.ReadFile(name)
+}
+
+func parsePEM(dir, name string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	pemBytes, err := readKeyFile(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not pem encoded", name)
+	}
+	return parse(block.Bytes)
+}
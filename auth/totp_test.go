@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the ascii key 12345678901234567890 of the RFC 6238 test vectors, base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestValidateTOTP runs the SHA1 vectors of RFC 6238, cut to six digits, through the skew window
+func TestValidateTOTP(t *testing.T) {
+	at := func(unix int64) time.Time {
+		return time.Unix(unix, 0)
+	}
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		now    time.Time
+		step   int64
+		ok     bool
+	}{
+		{"vector 59", rfcSecret, "287082", at(59), 1, true},
+		{"vector 1111111109", rfcSecret, "081804", at(1111111109), 37037036, true},
+		{"vector 1234567890", rfcSecret, "005924", at(1234567890), 41152263, true},
+		{"lower case secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "005924", at(1234567890), 41152263, true},
+		// one period on either side is accepted for clocks running a little off
+		{"one period early", rfcSecret, "005924", at(1234567890 - 30), 41152263, true},
+		{"one period late", rfcSecret, "005924", at(1234567890 + 30), 41152263, true},
+		{"two periods early", rfcSecret, "005924", at(1234567890 - 60), 0, false},
+		{"two periods late", rfcSecret, "005924", at(1234567890 + 60), 0, false},
+		{"wrong code", rfcSecret, "005925", at(1234567890), 0, false},
+		{"eight digits", rfcSecret, "89005924", at(1234567890), 0, false},
+		{"empty code", rfcSecret, "", at(1234567890), 0, false},
+		{"invalid secret", "not base32!", "005924", at(1234567890), 0, false},
+	}
+	for _, test := range tests {
+		step, ok := ValidateTOTP(test.secret, test.code, test.now)
+		if step != test.step || ok != test.ok {
+			t.Errorf("%s: ValidateTOTP = %d, %v, want %d, %v", test.name, step, ok, test.step, test.ok)
+		}
+	}
+}
+
+func TestTOTPRoundTrip(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	step := now.Unix() / totpPeriod
+	if got, ok := ValidateTOTP(secret, totpCode(key, step), now); !ok || got != step {
+		t.Fatalf("ValidateTOTP of the current code = %d, %v, want %d", got, ok, step)
+	}
+}
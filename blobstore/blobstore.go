@@ -0,0 +1,150 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"todo/constants"
+	"todo/utils"
+)
+
+// ErrNotFound is returned when there is no blob under the key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps the content of attachments under keys chosen by the caller, keys are
+// slash separated paths of letters, digits, dots, dashes and underscores
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob, a missing blob is no error
+	Delete(ctx context.Context, key string) error
+}
+
+//NewBlobStore creates the blob store selected by the BLOB_STORE environment variable, it defaults
+//to the local file system
+func NewBlobStore() (BlobStore, error) {
+	switch kind := os.Getenv(constants.BlobStoreEnv); kind {
+	case "", "local":
+		dir := os.Getenv(constants.BlobDirEnv)
+		if dir == "" {
+			dir = "blobs"
+		}
+		return NewLocalStore(dir)
+	case "s3":
+		endpoint := os.Getenv(constants.S3EndpointEnv)
+		bucket := os.Getenv(constants.S3BucketEnv)
+		if endpoint == "" || bucket == "" {
+			return nil, fmt.Errorf("%s and %s are required for the s3 blob store", constants.S3EndpointEnv, constants.S3BucketEnv)
+		}
+		region := os.Getenv(constants.S3RegionEnv)
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewS3Store(S3Config{
+			Endpoint:  endpoint,
+			Region:    region,
+			Bucket:    bucket,
+			AccessKey: os.Getenv(constants.S3AccessKeyEnv),
+			SecretKey: os.Getenv(constants.S3SecretKeyEnv),
+			PathStyle: utils.EnvBool(constants.S3PathStyleEnv, true),
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", kind)
+	}
+}
+
+// checkKey refuses keys which could leave the store, like ../ or absolute paths
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+				return fmt.Errorf("invalid blob key %q", key)
+			}
+		}
+	}
+	return nil
+}
+
+type localStore struct {
+	dir string
+}
+
+//NewLocalStore creates a blob store keeping every blob as a file below dir
+func NewLocalStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return localStore{dir: dir}, nil
+}
+
+func (s localStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the blob to a temporary file first, so a failed upload never leaves half a blob
+func (s localStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), ".upload-")
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written != size {
+		err = fmt.Errorf("blob %s has %d bytes, expected %d", key, written, size)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+func (s localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s localStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -1 +1,242 @@
 package constants
+
+import "time"
+
+const (
+	// AccessTokenTTL is the lifetime of the jwt handed out on signin and refresh
+	AccessTokenTTL = time.Hour * 12
+	// ChallengeTokenTTL is how long a user has to enter the second factor after the password
+	ChallengeTokenTTL = time.Minute * 5
+	// RefreshTokenTTL is the lifetime of a refresh token, it is extended on every rotation
+	RefreshTokenTTL = time.Hour * 24 * 30
+	// PasswordResetTTL is how long a password reset token can be used
+	PasswordResetTTL = time.Hour
+	// EmailVerificationTTL is how long an email verification link can be used
+	EmailVerificationTTL = time.Hour * 48
+	// EmailVerificationGrace is how long an unverified account may still write todos, when verification is required
+	EmailVerificationGrace = time.Hour * 24
+	// LoginLockoutDuration is how long an account or client ip stays locked after too many failed signins
+	LoginLockoutDuration = time.Minute * 15
+	// PasswordResetLockoutDuration is how long an email or client ip may not ask for password resets
+	// once it asked for too many
+	PasswordResetLockoutDuration = time.Hour
+)
+
+const (
+	// JWTKeyringFileEnv points to a json keyring holding the jwt signing and verification keys
+	JWTKeyringFileEnv = "JWT_KEYRING_FILE"
+	// JWTSecretEnv is a single HS256 secret, used when no keyring file is configured
+	JWTSecretEnv = "JWT_SECRET"
+	// RequireEmailVerificationEnv blocks unverified accounts from writing once the grace period is over
+	RequireEmailVerificationEnv = "REQUIRE_EMAIL_VERIFICATION"
+	EmailVerificationGraceEnv   = "EMAIL_VERIFICATION_GRACE"
+	// LockoutStoreEnv selects where failed signins are tracked: sqlite (default) or memory
+	LockoutStoreEnv         = "LOCKOUT_STORE"
+	LoginMaxAttemptsEnv     = "LOGIN_MAX_ATTEMPTS"
+	LoginIPMaxAttemptsEnv   = "LOGIN_IP_MAX_ATTEMPTS"
+	LoginFreeAttemptsEnv    = "LOGIN_FREE_ATTEMPTS"
+	LoginBackoffBaseEnv     = "LOGIN_BACKOFF_BASE"
+	LoginLockoutDurationEnv = "LOGIN_LOCKOUT_DURATION"
+	// PasswordResetMaxRequestsEnv is the number of password reset requests per email or client ip before they are refused
+	PasswordResetMaxRequestsEnv = "PASSWORD_RESET_MAX_REQUESTS"
+)
+
+const (
+	// BaseURLEnv is the public url of the server, used to build links sent by mail
+	BaseURLEnv = "APP_BASE_URL"
+	// MailerEnv selects the mailer: log (default), file or smtp
+	MailerEnv       = "MAILER"
+	MailerFileEnv   = "MAILER_FILE"
+	MailFromEnv     = "MAIL_FROM"
+	SMTPAddrEnv     = "SMTP_ADDR"
+	SMTPUserEnv     = "SMTP_USER"
+	SMTPPasswordEnv = "SMTP_PASSWORD"
+	// BlobStoreEnv selects where attachments are stored: local (default) or s3
+	BlobStoreEnv = "BLOB_STORE"
+	// BlobDirEnv is the directory of the local blob store, blobs by default
+	BlobDirEnv = "BLOB_DIR"
+	// the s3 blob store talks to S3_ENDPOINT, like https://s3.eu-central-1.amazonaws.com or
+	// http://localhost:9000 for MinIO, and keeps the blobs in S3_BUCKET
+	S3EndpointEnv  = "S3_ENDPOINT"
+	S3RegionEnv    = "S3_REGION"
+	S3BucketEnv    = "S3_BUCKET"
+	S3AccessKeyEnv = "S3_ACCESS_KEY"
+	S3SecretKeyEnv = "S3_SECRET_KEY"
+	// S3PathStyleEnv puts the bucket in the path rather than the host name, as MinIO expects, true by default
+	S3PathStyleEnv = "S3_PATH_STYLE"
+)
+
+const (
+	// PersonalAccessTokenPrefix marks personal access tokens, so they can be told apart from jwt tokens
+	PersonalAccessTokenPrefix = "todo_pat_"
+
+	ScopeTodosRead       = "todos:read"
+	ScopeTodosWrite      = "todos:write"
+	ScopeCategoriesRead  = "categories:read"
+	ScopeCategoriesWrite = "categories:write"
+	ScopeTagsRead        = "tags:read"
+	ScopeTagsWrite       = "tags:write"
+	ScopeListsRead       = "lists:read"
+	ScopeListsWrite      = "lists:write"
+)
+
+// Scopes are the scopes a personal access token can be granted
+var Scopes = []string{ScopeTodosRead, ScopeTodosWrite, ScopeCategoriesRead, ScopeCategoriesWrite, ScopeTagsRead, ScopeTagsWrite, ScopeListsRead, ScopeListsWrite}
+
+const (
+	// TOTPIssuer is the account issuer shown in authenticator apps
+	TOTPIssuer = "todo"
+	// RecoveryCodeCount is the number of recovery codes handed out when two factor authentication is enabled
+	RecoveryCodeCount = 10
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// AdminEmailsEnv is a comma separated list of accounts promoted to admin on startup
+	AdminEmailsEnv = "ADMIN_EMAILS"
+)
+
+const (
+	// ExportVersion is the version of the personal data export format, bump it when the format changes
+	ExportVersion = 2
+	// MaxImportSize is the largest export archive accepted for import
+	MaxImportSize = 20 << 20
+)
+
+const (
+	// MaxTodoDepth is the default number of levels a todo tree may have, a todo without subtasks is one level
+	MaxTodoDepth = 5
+	// MaxTodoDepthEnv overrides MaxTodoDepth
+	MaxTodoDepthEnv = "MAX_TODO_DEPTH"
+)
+
+// DefaultTagColor is the color of a tag created without one
+const DefaultTagColor = "#808080"
+
+const (
+	// DateLayout is the RFC 3339 full date, used for all day due dates
+	DateLayout = "2006-01-02"
+	// DefaultTimeZone is the time zone of users who did not pick one
+	DefaultTimeZone = "UTC"
+	// RecurrenceAnchorDue counts the next occurrence of a recurring todo from its due date
+	RecurrenceAnchorDue = "due"
+	// RecurrenceAnchorCompletion counts the next occurrence of a recurring todo from the day it was completed
+	RecurrenceAnchorCompletion = "completion"
+)
+
+const (
+	ReminderPending = "pending"
+	ReminderSending = "sending"
+	ReminderSent    = "sent"
+	ReminderFailed  = "failed"
+	// ReminderNotifiersEnv is a comma separated list of the notifiers delivering reminders: log, email and webhook
+	ReminderNotifiersEnv = "REMINDER_NOTIFIERS"
+	// ReminderWebhookURLEnv is where the webhook notifier posts reminders
+	ReminderWebhookURLEnv = "REMINDER_WEBHOOK_URL"
+	// ReminderWebhookSecretEnv signs the webhook body when set
+	ReminderWebhookSecretEnv = "REMINDER_WEBHOOK_SECRET"
+	// ReminderPollInterval is how often the scheduler looks for due reminders
+	ReminderPollInterval = time.Second * 30
+	// ReminderPollIntervalEnv overrides ReminderPollInterval
+	ReminderPollIntervalEnv = "REMINDER_POLL_INTERVAL"
+	// AssignmentNotifiersEnv lists the notifiers telling users about todos assigned to them, none by default
+	AssignmentNotifiersEnv = "ASSIGNMENT_NOTIFIERS"
+	// AssignmentWebhookURLEnv is where the webhook notifier posts assignments
+	AssignmentWebhookURLEnv = "ASSIGNMENT_WEBHOOK_URL"
+	// AssignmentWebhookSecretEnv signs the webhook body of assignments when set
+	AssignmentWebhookSecretEnv = "ASSIGNMENT_WEBHOOK_SECRET"
+)
+
+const (
+	// the kinds of entries in the activity feed of a todo
+	ActivityCreated  = "created"
+	ActivityComment  = "comment"
+	ActivityStatus   = "status"
+	ActivityAssignee = "assignee"
+	ActivityCategory = "category"
+	ActivityTrashed  = "trashed"
+	ActivityRestored = "restored"
+	// MaxCommentLength limits the markdown of a comment
+	MaxCommentLength = 10000
+)
+
+const (
+	// the kinds of notifications, a due reminder or a todo assigned to the user
+	NotificationReminder   = "reminder"
+	NotificationAssignment = "assignment"
+)
+
+const (
+	// the orders of the todo listing
+	TodoSortDue      = "due"
+	TodoSortPriority = "priority"
+	TodoSortCreated  = "created"
+	TodoSortTitle    = "title"
+	// DefaultPageSize is the number of todos listed when no limit is given
+	DefaultPageSize = 50
+	// MaxPageSize is the largest limit accepted for the todo listing
+	MaxPageSize = 200
+)
+
+const (
+	// SearchHighlightStart and SearchHighlightEnd enclose the matching words in search highlights and snippets
+	SearchHighlightStart = "<mark>"
+	SearchHighlightEnd   = "</mark>"
+	// DefaultSearchLimit is the number of search results returned when no limit is given
+	DefaultSearchLimit = 20
+)
+
+const (
+	// the states of the default workflow, todos in StatusDone and StatusCancelled are completed
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+	StatusCancelled  = "cancelled"
+	// MaxWorkflowStates limits the number of states of a workflow
+	MaxWorkflowStates = 20
+)
+
+const (
+	// the roles of the members of a shared category, viewers read its todos, editors change them too
+	// and admins also manage the members and the workflow. The owner of the category can do everything.
+	MemberViewer = "viewer"
+	MemberEditor = "editor"
+	MemberAdmin  = "admin"
+	MemberOwner  = "owner"
+)
+
+const (
+	// AttachmentMaxSize is the largest file that can be attached to a todo, 10 MiB
+	AttachmentMaxSize = 10 << 20
+	// AttachmentMaxSizeEnv overrides AttachmentMaxSize, in bytes
+	AttachmentMaxSizeEnv = "ATTACHMENT_MAX_SIZE"
+	// AttachmentQuota is the total size of the files a user may upload, 100 MiB
+	AttachmentQuota = 100 << 20
+	// AttachmentQuotaEnv overrides AttachmentQuota, in bytes
+	AttachmentQuotaEnv = "ATTACHMENT_QUOTA"
+)
+
+// AttachmentTypes are the content types accepted for attachments, as sniffed from the file content
+var AttachmentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain; charset=utf-8",
+	"text/plain; charset=utf-16be",
+	"text/plain; charset=utf-16le",
+}
+
+const (
+	// TrashRetention is how long deleted todos and categories stay in the trash before they are purged
+	TrashRetention = time.Hour * 24 * 30
+	// TrashRetentionEnv overrides TrashRetention
+	TrashRetentionEnv = "TRASH_RETENTION"
+	// TrashPurgeInterval is how often expired trash is looked for
+	TrashPurgeInterval = time.Hour
+	// TrashPurgeIntervalEnv overrides TrashPurgeInterval
+	TrashPurgeIntervalEnv = "TRASH_PURGE_INTERVAL"
+)
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//GetMe controller returns the profile of the current user
+func (t todoCtrl) GetMeController(ctx *gin.Context) {
+	response, err := t.todoSrv.GetMe(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//UpdateMe controller updates the name, email and time zone of the current user
+func (t todoCtrl) UpdateMeController(ctx *gin.Context) {
+	var profile model.UpdateProfile
+	if err := ctx.ShouldBindJSON(&profile); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.UpdateMe(ctx, profile)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//ChangePassword controller changes the password of the current user
+func (t todoCtrl) ChangePasswordController(ctx *gin.Context) {
+	var password model.ChangePassword
+	if err := ctx.ShouldBindJSON(&password); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.ChangePassword(ctx, password)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Password changed successfully")
+}
+
+//DeleteMe controller deletes the account of the current user
+func (t todoCtrl) DeleteMeController(ctx *gin.Context) {
+	var confirm model.DeleteAccount
+	if err := ctx.ShouldBindJSON(&confirm); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.DeleteMe(ctx, confirm.Password)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Account deleted successfully")
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//SearchUsers controller lists users, optionally filtered by the q query parameter
+func (t todoCtrl) SearchUsersController(ctx *gin.Context) {
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	offset, errOffset := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if errLimit != nil || errOffset != nil || limit < 1 || limit > 500 || offset < 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, "Please provide valid limit and offset")
+		return
+	}
+	response, err := t.todoSrv.SearchUsers(ctx, ctx.Query("q"), limit, offset)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//DisableUser controller disables an account
+func (t todoCtrl) DisableUserController(ctx *gin.Context) {
+	t.setUserDisabled(ctx, true, "User disabled successfully")
+}
+
+//EnableUser controller enables a disabled account
+func (t todoCtrl) EnableUserController(ctx *gin.Context) {
+	t.setUserDisabled(ctx, false, "User enabled successfully")
+}
+
+func (t todoCtrl) setUserDisabled(ctx *gin.Context, disabled bool, message string) {
+	var user model.Id
+	if err := ctx.ShouldBindJSON(&user); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.SetUserDisabled(ctx, user.ID, disabled)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, message)
+}
+
+//ForcePasswordReset controller makes a user choose a new password before the next signin
+func (t todoCtrl) ForcePasswordResetController(ctx *gin.Context) {
+	var user model.Id
+	if err := ctx.ShouldBindJSON(&user); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.ForcePasswordReset(ctx, user.ID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Password reset forced successfully")
+}
+
+//SetUserRole controller changes the role of a user
+func (t todoCtrl) SetUserRoleController(ctx *gin.Context) {
+	var role model.UserRole
+	if err := ctx.ShouldBindJSON(&role); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.SetUserRole(ctx, role)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Role updated successfully")
+}
+
+//GetStats controller shows system stats
+func (t todoCtrl) GetStatsController(ctx *gin.Context) {
+	response, err := t.todoSrv.GetStats(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//GetInvalidDueDates controller lists the todos whose old due date could not be migrated
+func (t todoCtrl) GetInvalidDueDatesController(ctx *gin.Context) {
+	response, err := t.todoSrv.GetInvalidDueDates(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AssignTodo controller hands a todo to another user, a null assigneeId leaves it to nobody
+func (t todoCtrl) AssignTodoController(ctx *gin.Context) {
+	var input model.AssignTodo
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.AssignTodo(ctx, input)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//GetAssignmentHistory controller lists the assignees of the todo given by the id query parameter
+func (t todoCtrl) GetAssignmentHistoryController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	response, err := t.todoSrv.GetAssignmentHistory(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//GetAssignedTodos controller lists the todos assigned to the user a page at a time, see parseTodoQuery
+//for the parameters
+func (t todoCtrl) GetAssignedTodosController(ctx *gin.Context) {
+	query, err := parseTodoQuery(ctx)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	page, err := t.todoSrv.GetAssignedTodos(ctx, query)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	writeTodoPage(ctx, page)
+}
+
+//GetCreatedTodos controller lists the todos the user created a page at a time, see parseTodoQuery
+//for the parameters
+func (t todoCtrl) GetCreatedTodosController(ctx *gin.Context) {
+	query, err := parseTodoQuery(ctx)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	page, err := t.todoSrv.GetCreatedTodos(ctx, query)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	writeTodoPage(ctx, page)
+}
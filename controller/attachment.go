@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"todo/constants"
+	"todo/services"
+	"todo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for the boundaries and fields of an upload next to the file itself
+const multipartOverhead = 1 << 20
+
+//AddAttachment controller attaches the file field of a multipart form to the todo given by the todoId field
+func (t todoCtrl) AddAttachmentController(ctx *gin.Context) {
+	maxSize := int64(utils.EnvInt(constants.AttachmentMaxSizeEnv, constants.AttachmentMaxSize))
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+multipartOverhead)
+	file, errFile := ctx.FormFile("file")
+	if errFile != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(errFile, &maxErr) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, fmt.Sprintf("file must be at most %d bytes", maxSize))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, "please upload the attachment as file")
+		return
+	}
+	todo, errParam := strconv.ParseUint(ctx.PostForm("todoId"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid todoId")
+		return
+	}
+	attachment, err := t.todoSrv.AddAttachment(ctx, int(todo), file)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err == services.ErrQuotaExceeded {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, fmt.Sprint(err))
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, attachment)
+}
+
+//GetAttachments controller lists the attachments of the todo given by the id query parameter
+func (t todoCtrl) GetAttachmentsController(ctx *gin.Context) {
+	todo, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	attachments, err := t.todoSrv.GetAttachments(ctx, int(todo))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, attachments)
+}
+
+//DownloadAttachment controller sends the content of the attachment given by the id query parameter
+func (t todoCtrl) DownloadAttachmentController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	attachment, content, err := t.todoSrv.DownloadAttachment(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	defer content.Close()
+	// the file is always downloaded, never rendered by the browser in the context of the api
+	ctx.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+//DeleteAttachment controller deletes the attachment given by the id query parameter
+func (t todoCtrl) DeleteAttachmentController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.DeleteAttachment(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Attachment Deleted Successfully")
+}
+
+//GetStorageUsage controller tells how much of the attachment quota the current user has used
+func (t todoCtrl) GetStorageUsageController(ctx *gin.Context) {
+	usage, err := t.todoSrv.GetStorageUsage(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, usage)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddComment controller comments on a todo
+func (t todoCtrl) AddCommentController(ctx *gin.Context) {
+	var comment model.Comment
+	if err := ctx.ShouldBindJSON(&comment); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.AddComment(ctx, &comment)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, comment)
+}
+
+//EditComment controller changes the body of a comment
+func (t todoCtrl) EditCommentController(ctx *gin.Context) {
+	var input model.EditComment
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	comment, err := t.todoSrv.EditComment(ctx, input)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, comment)
+}
+
+//DeleteComment controller deletes the comment given by the id query parameter
+func (t todoCtrl) DeleteCommentController(ctx *gin.Context) {
+	comment := ctx.Query("id")
+	number, errParam := strconv.ParseUint(comment, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.DeleteComment(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Comment Deleted Successfully")
+}
+
+//GetComments controller lists the comments on the todo given by the id query parameter, limit and
+//offset page through them and X-Total-Count tells how many there are
+func (t todoCtrl) GetCommentsController(ctx *gin.Context) {
+	todo, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	limit, offset, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	page, err := t.todoSrv.GetComments(ctx, int(todo), limit, offset)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.Header("X-Total-Count", strconv.Itoa(page.Total))
+	ctx.JSON(http.StatusOK, page.Comments)
+}
+
+//GetActivity controller lists the activity feed of the todo given by the id query parameter, paged like
+//the comments
+func (t todoCtrl) GetActivityController(ctx *gin.Context) {
+	todo, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	limit, offset, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	page, err := t.todoSrv.GetActivity(ctx, int(todo), limit, offset)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.Header("X-Total-Count", strconv.Itoa(page.Total))
+	ctx.JSON(http.StatusOK, page.Items)
+}
+
+// parsePage reads the limit and offset query parameters, it answers the request itself when they are invalid
+func parsePage(ctx *gin.Context) (int, int, bool) {
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(constants.DefaultPageSize)))
+	offset, errOffset := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if errLimit != nil || errOffset != nil || limit < 1 || limit > constants.MaxPageSize || offset < 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, "Please provide valid limit and offset")
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -23,6 +24,82 @@ type TodoCtrl interface {
 	GetTodoByCategoryController(ctx *gin.Context)
 	GetCategoryController(ctx *gin.Context)
 	DeleteCategoryController(ctx *gin.Context)
+	RefreshTokenController(ctx *gin.Context)
+	SignOutController(ctx *gin.Context)
+	KeysController(ctx *gin.Context)
+	ForgotPasswordController(ctx *gin.Context)
+	ResetPasswordController(ctx *gin.Context)
+	VerifyEmailController(ctx *gin.Context)
+	ResendVerificationController(ctx *gin.Context)
+	CreatePersonalAccessTokenController(ctx *gin.Context)
+	GetPersonalAccessTokensController(ctx *gin.Context)
+	RevokePersonalAccessTokenController(ctx *gin.Context)
+	EnrollTOTPController(ctx *gin.Context)
+	ConfirmTOTPController(ctx *gin.Context)
+	DisableTOTPController(ctx *gin.Context)
+	SignInTwoFactorController(ctx *gin.Context)
+	SearchUsersController(ctx *gin.Context)
+	DisableUserController(ctx *gin.Context)
+	EnableUserController(ctx *gin.Context)
+	ForcePasswordResetController(ctx *gin.Context)
+	SetUserRoleController(ctx *gin.Context)
+	GetStatsController(ctx *gin.Context)
+	GetMeController(ctx *gin.Context)
+	UpdateMeController(ctx *gin.Context)
+	ChangePasswordController(ctx *gin.Context)
+	DeleteMeController(ctx *gin.Context)
+	ExportDataController(ctx *gin.Context)
+	ImportDataController(ctx *gin.Context)
+	AddSubtaskController(ctx *gin.Context)
+	GetSubtasksController(ctx *gin.Context)
+	AddTagController(ctx *gin.Context)
+	GetTagsController(ctx *gin.Context)
+	EditTagController(ctx *gin.Context)
+	DeleteTagController(ctx *gin.Context)
+	SkipOccurrenceController(ctx *gin.Context)
+	EndSeriesController(ctx *gin.Context)
+	GetInvalidDueDatesController(ctx *gin.Context)
+	AddReminderController(ctx *gin.Context)
+	GetRemindersController(ctx *gin.Context)
+	DeleteReminderController(ctx *gin.Context)
+	SearchTodosController(ctx *gin.Context)
+	AddSmartListController(ctx *gin.Context)
+	GetSmartListsController(ctx *gin.Context)
+	EditSmartListController(ctx *gin.Context)
+	DeleteSmartListController(ctx *gin.Context)
+	GetSmartListTodosController(ctx *gin.Context)
+	GetWorkflowController(ctx *gin.Context)
+	SetWorkflowController(ctx *gin.Context)
+	DeleteWorkflowController(ctx *gin.Context)
+	ChangeStatusController(ctx *gin.Context)
+	GetStatusHistoryController(ctx *gin.Context)
+	AddDependencyController(ctx *gin.Context)
+	GetDependenciesController(ctx *gin.Context)
+	RemoveDependencyController(ctx *gin.Context)
+	NextTodosController(ctx *gin.Context)
+	InviteMemberController(ctx *gin.Context)
+	GetMembersController(ctx *gin.Context)
+	EditMemberController(ctx *gin.Context)
+	RemoveMemberController(ctx *gin.Context)
+	AssignTodoController(ctx *gin.Context)
+	GetAssignmentHistoryController(ctx *gin.Context)
+	GetAssignedTodosController(ctx *gin.Context)
+	GetCreatedTodosController(ctx *gin.Context)
+	AddCommentController(ctx *gin.Context)
+	EditCommentController(ctx *gin.Context)
+	DeleteCommentController(ctx *gin.Context)
+	GetCommentsController(ctx *gin.Context)
+	GetActivityController(ctx *gin.Context)
+	AddAttachmentController(ctx *gin.Context)
+	GetAttachmentsController(ctx *gin.Context)
+	DownloadAttachmentController(ctx *gin.Context)
+	DeleteAttachmentController(ctx *gin.Context)
+	GetStorageUsageController(ctx *gin.Context)
+	GetTrashController(ctx *gin.Context)
+	RestoreTodoController(ctx *gin.Context)
+	PurgeTodoController(ctx *gin.Context)
+	RestoreCategoryController(ctx *gin.Context)
+	PurgeCategoryController(ctx *gin.Context)
 }
 
 type todoCtrl struct {
@@ -35,6 +112,16 @@ func NewTodoController(todosrv services.TodoService) TodoCtrl {
 	}
 }
 
+// abortFieldError answers with the field when err is about an invalid field of the request, it reports whether it did
+func abortFieldError(ctx *gin.Context, err error) bool {
+	var fieldErr *model.FieldError
+	if errors.As(err, &fieldErr) {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, fieldErr)
+		return true
+	}
+	return false
+}
+
 //SignUp controller to create a new user
 func (t todoCtrl) SignUpController(ctx *gin.Context) {
 	var user model.User
@@ -43,6 +130,9 @@ func (t todoCtrl) SignUpController(ctx *gin.Context) {
 		return
 	}
 	errSignup := t.todoSrv.SignUp(ctx, &user)
+	if abortFieldError(ctx, errSignup) {
+		return
+	}
 	if errSignup != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(errSignup))
 		return
@@ -58,6 +148,13 @@ func (t todoCtrl) SignInController(ctx *gin.Context) {
 		return
 	}
 	response, errSignup := t.todoSrv.SignIn(ctx, &user)
+	if abortLocked(ctx, errSignup) {
+		return
+	}
+	if errSignup == services.ErrAccountDisabled || errSignup == services.ErrPasswordResetRequired {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, fmt.Sprint(errSignup))
+		return
+	}
 	if errSignup != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "server error")
 		return
@@ -68,11 +165,17 @@ func (t todoCtrl) SignInController(ctx *gin.Context) {
 //AddTodo controller to add a new todo for a user
 func (t todoCtrl) AddTodoController(ctx *gin.Context) {
 	var todo model.Todo
-	if err := ctx.BindJSON(&todo); err != nil {
+	if err := ctx.ShouldBindJSON(&todo); err != nil {
+		if abortFieldError(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
 		return
 	}
 	err := t.todoSrv.AddTodo(ctx, &todo)
+	if abortFieldError(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
 		return
@@ -80,7 +183,7 @@ func (t todoCtrl) AddTodoController(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, "Todo Added Successfully")
 }
 
-//DeleteTodo controller to delete a todo
+//DeleteTodo controller to delete a todo, it goes to the trash with its subtasks
 func (t todoCtrl) DeleteTodoController(ctx *gin.Context) {
 	todo := ctx.Query("id")
 	errDelete := t.todoSrv.DeleteTodo(ctx, todo)
@@ -91,14 +194,31 @@ func (t todoCtrl) DeleteTodoController(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, "Todo Deleted Successfully")
 }
 
-//GetAllTodos controller to get all todo for a user
+//GetAllTodos controller lists the todos of a user a page at a time, see parseTodoQuery for the
+//parameters. view=tree instead returns all todos with subtasks nested below their parents
 func (t todoCtrl) GetAllTodosController(ctx *gin.Context) {
-	todos, err := t.todoSrv.GetAlltodo(ctx)
+	if ctx.Query("view") == "tree" {
+		todos, err := t.todoSrv.GetTodoTree(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, todos)
+		return
+	}
+	query, err := parseTodoQuery(ctx)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	page, err := t.todoSrv.GetAlltodo(ctx, query)
+	if abortFieldError(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
 		return
 	}
-	ctx.JSON(http.StatusOK, todos)
+	writeTodoPage(ctx, page)
 }
 
 //MarkTodo controller to mark a todo status
@@ -134,11 +254,17 @@ func (t todoCtrl) AddCategoryController(ctx *gin.Context) {
 //EditTodo controller edit a todo
 func (t todoCtrl) EditTodoController(ctx *gin.Context) {
 	var todo model.EditTodo
-	if err := ctx.BindJSON(&todo); err != nil {
+	if err := ctx.ShouldBindJSON(&todo); err != nil {
+		if abortFieldError(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
 		return
 	}
 	err := t.todoSrv.EditTodo(ctx, todo)
+	if abortFieldError(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
 		return
@@ -173,7 +299,7 @@ func (t todoCtrl) GetCategoryController(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-//DeleteCategory controller to delete a category
+//DeleteCategory controller to delete a category, it goes to the trash
 func (t todoCtrl) DeleteCategoryController(ctx *gin.Context) {
 	category := ctx.Query("id")
 	number, errParam := strconv.ParseUint(category, 10, 32)
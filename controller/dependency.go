@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddDependency controller makes a todo wait for another one
+func (t todoCtrl) AddDependencyController(ctx *gin.Context) {
+	var dependency model.Dependency
+	if err := ctx.ShouldBindJSON(&dependency); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.AddDependency(ctx, &dependency)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, dependency)
+}
+
+//GetDependencies controller lists the todos the todo given by the id query parameter waits for,
+//and the ones waiting for it
+func (t todoCtrl) GetDependenciesController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	response, err := t.todoSrv.GetDependencies(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//RemoveDependency controller lets the todo given by the id query parameter stop waiting for the blocker one
+func (t todoCtrl) RemoveDependencyController(ctx *gin.Context) {
+	todo, errTodo := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	blocker, errBlocker := strconv.ParseUint(ctx.Query("blocker"), 10, 32)
+	if errTodo != nil || errBlocker != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id and blocker")
+		return
+	}
+	err := t.todoSrv.RemoveDependency(ctx, int(todo), int(blocker))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Successfully Removed Dependency")
+}
+
+//NextTodos controller lists the todos which can be worked on now, all=true lists every open todo
+//after the todos it waits for
+func (t todoCtrl) NextTodosController(ctx *gin.Context) {
+	all := false
+	if value := ctx.Query("all"); value != "" {
+		var err error
+		if all, err = strconv.ParseBool(value); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, &model.FieldError{Field: "all", Message: "must be true or false"})
+			return
+		}
+	}
+	response, err := t.todoSrv.NextTodos(ctx, all)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
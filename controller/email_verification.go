@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//VerifyEmail controller verifies the email address using the token from the verification mail
+func (t todoCtrl) VerifyEmailController(ctx *gin.Context) {
+	token := ctx.Query("token")
+	err := t.todoSrv.VerifyEmail(ctx, token)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Email verified successfully")
+}
+
+//ResendVerification controller sends a new verification mail to the current user
+func (t todoCtrl) ResendVerificationController(ctx *gin.Context) {
+	err := t.todoSrv.ResendVerification(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Verification mail sent")
+}
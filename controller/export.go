@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"todo/constants"
+	"todo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//ExportData controller downloads all data of the current user, as json or with format=zip as a zip archive
+func (t todoCtrl) ExportDataController(ctx *gin.Context) {
+	export, err := t.todoSrv.ExportData(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	name := "todo-export-" + time.Now().Format("20060102")
+	switch ctx.DefaultQuery("format", "json") {
+	case "json":
+		ctx.Header("Content-Disposition", "attachment; filename="+name+".json")
+		ctx.JSON(http.StatusOK, export)
+	case "zip":
+		archive, err := utils.ExportZip(export)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, "unable to build archive")
+			return
+		}
+		ctx.Header("Content-Disposition", "attachment; filename="+name+".zip")
+		ctx.Data(http.StatusOK, "application/zip", archive)
+	default:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, "format must be json or zip")
+	}
+}
+
+//ImportData controller restores an export, sent as the request body or as the file field of a multipart form
+func (t todoCtrl) ImportDataController(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, constants.MaxImportSize)
+	var data []byte
+	var err error
+	if ctx.ContentType() == "multipart/form-data" {
+		file, errFile := ctx.FormFile("file")
+		if errFile != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, "please upload the export as file")
+			return
+		}
+		reader, errOpen := file.Open()
+		if errOpen != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, "unable to read file")
+			return
+		}
+		defer reader.Close()
+		data, err = ioutil.ReadAll(reader)
+	} else {
+		data, err = ioutil.ReadAll(ctx.Request.Body)
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, "unable to read export")
+		return
+	}
+	export, err := utils.ParseExport(data)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, fmt.Sprint(err))
+		return
+	}
+	response, err := t.todoSrv.ImportData(ctx, export)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
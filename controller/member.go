@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//InviteMember controller shares a category with another user by email
+func (t todoCtrl) InviteMemberController(ctx *gin.Context) {
+	var input model.InviteMember
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.InviteMember(ctx, input)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "If an account has this email, the category is shared with it")
+}
+
+//GetMembers controller lists the members of the category given by the id query parameter
+func (t todoCtrl) GetMembersController(ctx *gin.Context) {
+	category := ctx.Query("id")
+	number, errParam := strconv.ParseUint(category, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	response, err := t.todoSrv.GetMembers(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//EditMember controller changes the role of a member of a category
+func (t todoCtrl) EditMemberController(ctx *gin.Context) {
+	var input model.EditMember
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	member, err := t.todoSrv.EditMember(ctx, input)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, member)
+}
+
+//RemoveMember controller removes the user given by the user query parameter from the category
+//given by the id query parameter
+func (t todoCtrl) RemoveMemberController(ctx *gin.Context) {
+	category, errCategory := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	user, errUser := strconv.ParseUint(ctx.Query("user"), 10, 32)
+	if errCategory != nil || errUser != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id and user")
+		return
+	}
+	err := t.todoSrv.RemoveMember(ctx, int(category), int(user))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Successfully Removed Member")
+}
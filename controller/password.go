@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//ForgotPassword controller sends a password reset mail
+func (t todoCtrl) ForgotPasswordController(ctx *gin.Context) {
+	var forgot model.ForgotPassword
+	if err := ctx.ShouldBindJSON(&forgot); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.ForgotPassword(ctx, forgot.Email)
+	if abortLocked(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "If the account exists, a password reset mail has been sent")
+}
+
+//ResetPassword controller sets a new password using a reset token
+func (t todoCtrl) ResetPasswordController(ctx *gin.Context) {
+	var reset model.ResetPassword
+	if err := ctx.ShouldBindJSON(&reset); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.ResetPassword(ctx, reset)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Password reset successfully")
+}
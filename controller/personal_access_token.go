@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//CreatePersonalAccessToken controller creates a personal access token
+func (t todoCtrl) CreatePersonalAccessTokenController(ctx *gin.Context) {
+	var input model.NewPersonalAccessToken
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.CreatePersonalAccessToken(ctx, input)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//GetPersonalAccessTokens controller lists the personal access tokens of the user
+func (t todoCtrl) GetPersonalAccessTokensController(ctx *gin.Context) {
+	response, err := t.todoSrv.GetPersonalAccessTokens(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//RevokePersonalAccessToken controller revokes a personal access token
+func (t todoCtrl) RevokePersonalAccessTokenController(ctx *gin.Context) {
+	token := ctx.Query("id")
+	number, errParam := strconv.ParseUint(token, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.RevokePersonalAccessToken(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Token revoked successfully")
+}
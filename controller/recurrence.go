@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+//SkipOccurrence controller moves the recurring todo given by the id query parameter to its next occurrence
+func (t todoCtrl) SkipOccurrenceController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	response, err := t.todoSrv.SkipOccurrence(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//EndSeries controller stops the recurring todo given by the id query parameter from recurring
+func (t todoCtrl) EndSeriesController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	err := t.todoSrv.EndSeries(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Series Ended Successfully")
+}
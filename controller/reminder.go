@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddReminder controller attaches a reminder to the todo given by the id query parameter
+func (t todoCtrl) AddReminderController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	var input model.NewReminder
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.AddReminder(ctx, int(number), input)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//GetReminders controller lists the reminders of the todo given by the id query parameter
+func (t todoCtrl) GetRemindersController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	response, err := t.todoSrv.GetReminders(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//DeleteReminder controller removes the reminder given by the id query parameter
+func (t todoCtrl) DeleteReminderController(ctx *gin.Context) {
+	reminder := ctx.Query("id")
+	number, errParam := strconv.ParseUint(reminder, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	err := t.todoSrv.DeleteReminder(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Reminder Deleted Successfully")
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+	"todo/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+//SearchTodos controller searches the todos of the user for q: words must all match, "quoted words"
+//match as a phrase and word* matches words starting with word
+func (t todoCtrl) SearchTodosController(ctx *gin.Context) {
+	limit := 0
+	if value := ctx.Query("limit"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			abortFieldError(ctx, &model.FieldError{Field: "limit", Message: "must be a positive number"})
+			return
+		}
+		limit = number
+	}
+	results, err := t.todoSrv.SearchTodos(ctx, ctx.Query("q"), limit)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if errors.Is(err, services.ErrSearchUnavailable) {
+		ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, fmt.Sprint(err))
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, results)
+}
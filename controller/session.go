@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"todo/auth"
+	"todo/lockout"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//RefreshToken controller exchanges a refresh token for a new jwt and refresh token
+func (t todoCtrl) RefreshTokenController(ctx *gin.Context) {
+	var refresh model.RefreshToken
+	if err := ctx.ShouldBindJSON(&refresh); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.RefreshToken(ctx, refresh.RefreshToken)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//SignOut controller revokes the session of the current user
+func (t todoCtrl) SignOutController(ctx *gin.Context) {
+	err := t.todoSrv.SignOut(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Successfully signed out")
+}
+
+//Keys controller publishes the public jwt verification keys as a JWK set
+func (t todoCtrl) KeysController(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"keys": auth.PublicKeys()})
+}
+
+// abortLocked answers 423 for a locked account and 429 while backing off, with a Retry-After header
+func abortLocked(ctx *gin.Context, err error) bool {
+	var locked *lockout.Error
+	if !errors.As(err, &locked) {
+		return false
+	}
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	status := http.StatusTooManyRequests
+	if locked.Locked {
+		status = http.StatusLocked
+	}
+	ctx.AbortWithStatusJSON(status, fmt.Sprint(err))
+	return true
+}
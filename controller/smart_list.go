@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddSmartList controller saves a named filter expression
+func (t todoCtrl) AddSmartListController(ctx *gin.Context) {
+	var list model.SmartList
+	if err := ctx.ShouldBindJSON(&list); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.AddSmartList(ctx, &list)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+//GetSmartLists controller lists the smart lists of the user
+func (t todoCtrl) GetSmartListsController(ctx *gin.Context) {
+	response, err := t.todoSrv.GetSmartLists(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//EditSmartList controller renames a smart list or changes its query
+func (t todoCtrl) EditSmartListController(ctx *gin.Context) {
+	var list model.EditSmartList
+	if err := ctx.ShouldBindJSON(&list); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.EditSmartList(ctx, list)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Smart List updated Successfully")
+}
+
+//DeleteSmartList controller deletes a smart list
+func (t todoCtrl) DeleteSmartListController(ctx *gin.Context) {
+	list := ctx.Query("id")
+	number, errParam := strconv.ParseUint(list, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.DeleteSmartList(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Successfully Deleted Smart List")
+}
+
+//GetSmartListTodos controller lists the todos matching the smart list in the path, a page at a time
+//with the parameters of the todo listing
+func (t todoCtrl) GetSmartListTodosController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	query, err := parseTodoQuery(ctx)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	page, err := t.todoSrv.GetSmartListTodos(ctx, int(number), query)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	writeTodoPage(ctx, page)
+}
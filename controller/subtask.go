@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddSubtask controller adds a subtask below the todo given by the id query parameter
+func (t todoCtrl) AddSubtaskController(ctx *gin.Context) {
+	parent := ctx.Query("id")
+	number, errParam := strconv.ParseUint(parent, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	var todo model.Todo
+	if err := ctx.ShouldBindJSON(&todo); err != nil {
+		if abortFieldError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.AddSubtask(ctx, int(number), &todo)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Subtask Added Successfully")
+}
+
+//GetSubtasks controller lists the subtasks of the todo given by the id query parameter
+func (t todoCtrl) GetSubtasksController(ctx *gin.Context) {
+	parent := ctx.Query("id")
+	number, errParam := strconv.ParseUint(parent, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "Please provide valid id")
+		return
+	}
+	response, err := t.todoSrv.GetSubtasks(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddTag controller to add a tag
+func (t todoCtrl) AddTagController(ctx *gin.Context) {
+	var tag model.Tag
+	if err := ctx.ShouldBindJSON(&tag); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.AddTag(ctx, &tag)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, tag)
+}
+
+//GetTags controller lists the tags of the user
+func (t todoCtrl) GetTagsController(ctx *gin.Context) {
+	response, err := t.todoSrv.GetTags(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//EditTag controller renames or recolors a tag
+func (t todoCtrl) EditTagController(ctx *gin.Context) {
+	var tag model.EditTag
+	if err := ctx.BindJSON(&tag); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.EditTag(ctx, tag)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Tag updated Successfully")
+}
+
+//DeleteTag controller deletes a tag and detaches it from its todos
+func (t todoCtrl) DeleteTagController(ctx *gin.Context) {
+	tag := ctx.Query("id")
+	number, errParam := strconv.ParseUint(tag, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.DeleteTag(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Successfully Deleted Tag")
+}
+
+// parseIds reads a comma separated list of ids
+func parseIds(list string) ([]int, error) {
+	var ids []int
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		number, err := strconv.ParseUint(item, 10, 32)
+		if err != nil {
+			return nil, errors.New("Please provide valid ids")
+		}
+		ids = append(ids, int(number))
+	}
+	return ids, nil
+}
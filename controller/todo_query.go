@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTodoQuery reads the listing parameters: sort=due|priority|created|title, order=asc|desc, limit,
+// cursor (X-Next-Cursor of the previous page), completed=true|false, category, priority, dueBefore and
+// dueAfter (timestamp or date), overdue=true, tags=1,2 for todos carrying any of the tags, with
+// match=all for the ones carrying all of them, filter for a smart list expression and
+// dependencies=true for the blocked and ready flags
+func parseTodoQuery(ctx *gin.Context) (model.TodoQuery, error) {
+	query := model.TodoQuery{
+		Sort:     ctx.Query("sort"),
+		Cursor:   ctx.Query("cursor"),
+		Priority: ctx.Query("priority"),
+		Filter:   ctx.Query("filter"),
+		MatchAll: ctx.Query("match") == "all",
+	}
+	switch strings.ToLower(ctx.Query("order")) {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, &model.FieldError{Field: "order", Message: "must be asc or desc"}
+	}
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return query, &model.FieldError{Field: "limit", Message: "must be a positive number"}
+		}
+		query.Limit = limit
+	}
+	if value := ctx.Query("completed"); value != "" {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, &model.FieldError{Field: "completed", Message: "must be true or false"}
+		}
+		query.Completed = &completed
+	}
+	if value := ctx.Query("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, &model.FieldError{Field: "overdue", Message: "must be true or false"}
+		}
+		query.Overdue = overdue
+	}
+	if value := ctx.Query("dependencies"); value != "" {
+		dependencies, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, &model.FieldError{Field: "dependencies", Message: "must be true or false"}
+		}
+		query.Dependencies = dependencies
+	}
+	if value := ctx.Query("category"); value != "" {
+		category, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return query, &model.FieldError{Field: "category", Message: "must be a category id"}
+		}
+		id := int(category)
+		query.Category = &id
+	}
+	for field, bound := range map[string]**model.DueDate{"dueBefore": &query.DueBefore, "dueAfter": &query.DueAfter} {
+		if value := ctx.Query(field); value != "" {
+			dueDate, err := model.ParseDueDate(field, value)
+			if err != nil {
+				return query, err
+			}
+			*bound = &dueDate
+		}
+	}
+	if tags := ctx.Query("tags"); tags != "" {
+		tagIds, err := parseIds(tags)
+		if err != nil {
+			return query, &model.FieldError{Field: "tags", Message: "must be a comma separated list of tag ids"}
+		}
+		query.TagIds = tagIds
+	}
+	return query, nil
+}
+
+// writeTodoPage sends a page of the todo listing, the body stays a plain list and the paging
+// details travel in headers
+func writeTodoPage(ctx *gin.Context, page *model.TodoPage) {
+	ctx.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		ctx.Header("X-Next-Cursor", page.NextCursor)
+	}
+	ctx.JSON(http.StatusOK, page.Todos)
+}
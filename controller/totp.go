@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"todo/model"
+	"todo/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+//EnrollTOTP controller starts two factor authentication enrollment
+func (t todoCtrl) EnrollTOTPController(ctx *gin.Context) {
+	response, err := t.todoSrv.EnrollTOTP(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//ConfirmTOTP controller enables two factor authentication and returns the recovery codes
+func (t todoCtrl) ConfirmTOTPController(ctx *gin.Context) {
+	var code model.TOTPCode
+	if err := ctx.ShouldBindJSON(&code); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.ConfirmTOTP(ctx, code.Code)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//DisableTOTP controller switches two factor authentication off
+func (t todoCtrl) DisableTOTPController(ctx *gin.Context) {
+	var code model.TOTPCode
+	if err := ctx.ShouldBindJSON(&code); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.DisableTOTP(ctx, code.Code)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Two factor authentication disabled")
+}
+
+//SignInTwoFactor controller completes a signin with a totp or recovery code
+func (t todoCtrl) SignInTwoFactorController(ctx *gin.Context) {
+	var input model.TwoFactorSignIn
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.SignInTwoFactor(ctx, input)
+	if abortLocked(ctx, err) {
+		return
+	}
+	if err == services.ErrAccountDisabled || err == services.ErrPasswordResetRequired {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, fmt.Sprint(err))
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+//GetTrash controller lists the deleted todos and categories the current user can restore
+func (t todoCtrl) GetTrashController(ctx *gin.Context) {
+	trash, err := t.todoSrv.GetTrash(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, trash)
+}
+
+//RestoreTodo controller takes the todo given by the id query parameter out of the trash
+func (t todoCtrl) RestoreTodoController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	todo, err := t.todoSrv.RestoreTodo(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, todo)
+}
+
+//PurgeTodo controller deletes the todo given by the id query parameter from the trash for good
+func (t todoCtrl) PurgeTodoController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.PurgeTodo(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Todo Purged Successfully")
+}
+
+//RestoreCategory controller takes the category given by the id query parameter out of the trash
+func (t todoCtrl) RestoreCategoryController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.RestoreCategory(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Category Restored Successfully")
+}
+
+//PurgeCategory controller deletes the category given by the id query parameter from the trash for good
+func (t todoCtrl) PurgeCategoryController(ctx *gin.Context) {
+	number, errParam := strconv.ParseUint(ctx.Query("id"), 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	err := t.todoSrv.PurgeCategory(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Category Purged Successfully")
+}
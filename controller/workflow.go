@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workflowCategory reads the category query parameter, without it the workflow of the user is meant
+func workflowCategory(ctx *gin.Context) (int, bool) {
+	category := ctx.Query("category")
+	if category == "" {
+		return 0, true
+	}
+	number, err := strconv.ParseUint(category, 10, 32)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid category id")
+		return 0, false
+	}
+	return int(number), true
+}
+
+//GetWorkflow controller shows the workflow in effect for the category query parameter, or for the user
+func (t todoCtrl) GetWorkflowController(ctx *gin.Context) {
+	category, ok := workflowCategory(ctx)
+	if !ok {
+		return
+	}
+	response, err := t.todoSrv.GetWorkflow(ctx, category)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//SetWorkflow controller replaces the workflow of the user, or of the category in the body
+func (t todoCtrl) SetWorkflowController(ctx *gin.Context) {
+	var workflow model.Workflow
+	if err := ctx.ShouldBindJSON(&workflow); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	err := t.todoSrv.SetWorkflow(ctx, &workflow)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, workflow)
+}
+
+//DeleteWorkflow controller removes the workflow of the category query parameter, or of the user
+func (t todoCtrl) DeleteWorkflowController(ctx *gin.Context) {
+	category, ok := workflowCategory(ctx)
+	if !ok {
+		return
+	}
+	err := t.todoSrv.DeleteWorkflow(ctx, category)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, "Successfully Deleted Workflow")
+}
+
+//ChangeStatus controller moves a todo to another state of its workflow
+func (t todoCtrl) ChangeStatusController(ctx *gin.Context) {
+	var input model.ChangeStatus
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, "invalid json")
+		return
+	}
+	response, err := t.todoSrv.ChangeStatus(ctx, input)
+	if abortFieldError(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+//GetStatusHistory controller lists the transitions of the todo given by the id query parameter
+func (t todoCtrl) GetStatusHistoryController(ctx *gin.Context) {
+	todo := ctx.Query("id")
+	number, errParam := strconv.ParseUint(todo, 10, 32)
+	if errParam != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, "provide valid id")
+		return
+	}
+	response, err := t.todoSrv.GetStatusHistory(ctx, int(number))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprint(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlUpdateUserProfile = `
+	UPDATE user
+		SET name = ?,
+		email = ?,
+		email_verified = ?,
+		time_zone = ?
+		WHERE user_id = ?
+	`
+	sqlRevokeOtherSessions = `
+	UPDATE session
+		SET revoked = 1
+		WHERE user_id = ? AND session_id != ?
+	`
+)
+
+// sqlDeleteUserData removes everything that belongs to a user, children before their parents
+var sqlDeleteUserData = []string{
+	`DELETE FROM todo_tag WHERE tag_id IN (SELECT tag_id FROM tag WHERE user_id = ?)`,
+	`DELETE FROM todo_tag WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM tag WHERE user_id = ?`,
+	`DELETE FROM smart_list WHERE user_id = ?`,
+	`DELETE FROM reminder WHERE user_id = ?`,
+	`DELETE FROM reminder WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM status_change WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM workflow WHERE user_id = ?`,
+	`DELETE FROM todo_dependency WHERE user_id = ?`,
+	`DELETE FROM todo_dependency WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM todo_dependency WHERE blocker_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM todo_assignment WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`UPDATE todo SET assignee_id = NULL WHERE assignee_id = ?`,
+	`DELETE FROM comment_mention WHERE user_id = ?`,
+	`DELETE FROM comment_mention WHERE comment_id IN (SELECT comment_id FROM comment WHERE user_id = ?)`,
+	`DELETE FROM comment_mention WHERE comment_id IN (SELECT comment_id FROM comment WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?))`,
+	`DELETE FROM comment WHERE user_id = ?`,
+	`DELETE FROM comment WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM todo_event WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM attachment WHERE user_id = ?`,
+	`DELETE FROM attachment WHERE todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)`,
+	`DELETE FROM category_member WHERE user_id = ?`,
+	`DELETE FROM category_member WHERE category_id IN (SELECT category_id FROM category WHERE user_id = ?)`,
+	`DELETE FROM todo WHERE user_id = ?`,
+	`DELETE FROM category WHERE user_id = ?`,
+	`DELETE FROM session WHERE user_id = ?`,
+	`DELETE FROM personal_access_token WHERE user_id = ?`,
+	`DELETE FROM password_reset WHERE user_id = ?`,
+	`DELETE FROM email_verification WHERE user_id = ?`,
+	`DELETE FROM recovery_code WHERE user_id = ?`,
+	`DELETE FROM user_totp WHERE user_id = ?`,
+	`DELETE FROM user WHERE user_id = ?`,
+}
+
+func (t todoDatabase) UpdateUserProfile(u *model.User) error {
+	_, err := t.db.Exec(sqlUpdateUserProfile, u.Name, u.Email, u.EmailVerified, u.TimeZone, u.ID)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) RevokeOtherSessions(userId, sessionId int) error {
+	_, err := t.db.Exec(sqlRevokeOtherSessions, userId, sessionId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteUser deletes the user with all their todos, categories and tokens in one transaction
+func (t todoDatabase) DeleteUser(userId int) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, query := range sqlDeleteUserData {
+		if _, err = tx.Exec(query, userId); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"todo/model"
+)
+
+const (
+	sqlSearchUsers = `
+	SELECT ` + userColumns + ` FROM user
+		WHERE name LIKE ? OR email LIKE ?
+		ORDER BY user_id
+		LIMIT ? OFFSET ?
+	`
+	sqlSetUserRole = `
+	UPDATE user
+		SET role = ?
+		WHERE user_id = ?
+	`
+	sqlSetUserRoleByEmail = `
+	UPDATE user
+		SET role = ?
+		WHERE email = ?
+	`
+	sqlSetUserDisabled = `
+	UPDATE user
+		SET disabled = ?
+		WHERE user_id = ?
+	`
+	sqlSetMustResetPassword = `
+	UPDATE user
+		SET must_reset_password = 1
+		WHERE user_id = ?
+	`
+	sqlGetStats = `
+	SELECT
+		(SELECT COUNT(*) FROM user),
+		(SELECT COUNT(*) FROM user WHERE email_verified = 1),
+		(SELECT COUNT(*) FROM user WHERE disabled = 1),
+		(SELECT COUNT(*) FROM user WHERE role = 'admin'),
+		(SELECT COUNT(*) FROM todo WHERE deleted_at IS NULL),
+		(SELECT COUNT(*) FROM todo WHERE completed = 1 AND deleted_at IS NULL),
+		(SELECT COUNT(*) FROM category WHERE deleted_at IS NULL),
+		(SELECT COUNT(*) FROM session WHERE revoked = 0 AND expires_at > ?)
+	`
+)
+
+func (t todoDatabase) SearchUsers(query string, limit, offset int) (*[]model.User, error) {
+	userList := []model.User{}
+	pattern := "%" + query + "%"
+	rows, err := t.db.Query(sqlSearchUsers, pattern, pattern, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		userList = append(userList, *user)
+	}
+	return &userList, nil
+}
+
+func (t todoDatabase) SetUserRole(userId int, role string) (int64, error) {
+	res, err := t.db.Exec(sqlSetUserRole, role, userId)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) SetUserRoleByEmail(email string, role string) (int64, error) {
+	res, err := t.db.Exec(sqlSetUserRoleByEmail, role, email)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) SetUserDisabled(userId int, disabled bool) (int64, error) {
+	res, err := t.db.Exec(sqlSetUserDisabled, disabled, userId)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) SetMustResetPassword(userId int) (int64, error) {
+	res, err := t.db.Exec(sqlSetMustResetPassword, userId)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) GetStats() (*model.Stats, error) {
+	var stats model.Stats
+	err := t.db.QueryRow(sqlGetStats, time.Now().Unix()).Scan(&stats.Users, &stats.VerifiedUsers, &stats.DisabledUsers, &stats.Admins, &stats.Todos, &stats.CompletedTodos, &stats.Categories, &stats.ActiveSessions)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
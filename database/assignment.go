@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	// sqlCreateTodoAssignment keeps the history of the assignees of a todo, NULL stands for nobody
+	sqlCreateTodoAssignment = `
+    CREATE TABLE IF NOT EXISTS todo_assignment(
+        assignment_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        todo_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		from_user INTEGER,
+		to_user INTEGER,
+		changed_at INTEGER NOT NULL,
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlUpdateTodoAssignee = `
+	UPDATE todo
+		SET assignee_id = ?
+		WHERE todo_id = ?
+	`
+	sqlInsertTodoAssignment = `
+	INSERT INTO todo_assignment
+		(todo_id,user_id,from_user,to_user,changed_at)
+		VALUES (?,?,?,?,?);
+	`
+	sqlGetTodoAssignments = `
+	SELECT assignment_id,todo_id,user_id,from_user,to_user,changed_at FROM todo_assignment
+		WHERE todo_id = ?
+		ORDER BY changed_at, assignment_id
+	`
+	sqlDeleteSubtreeAssignments = `
+	DELETE FROM todo_assignment
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+	// a member who leaves a category is no longer the assignee of the todos of others in it,
+	// it takes the category and the member
+	sqlUnassignMember = `
+	UPDATE todo
+		SET assignee_id = NULL
+		WHERE category = ? AND assignee_id = ? AND user_id != assignee_id
+	`
+	// sqlUnassignMembers does the same for all members of the category, it takes the category twice
+	sqlUnassignMembers = `
+	UPDATE todo
+		SET assignee_id = NULL
+		WHERE category = ? AND user_id != assignee_id
+		AND assignee_id IN (SELECT user_id FROM category_member WHERE category_id = ?)
+	`
+)
+
+// AssignTodo saves the new assignee of the todo together with the change
+func (t todoDatabase) AssignTodo(assignment *model.Assignment) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlUpdateTodoAssignee, nullableId(assignment.To), assignment.TodoId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.Exec(sqlInsertTodoAssignment, assignment.TodoId, assignment.UserId, nullableId(assignment.From), nullableId(assignment.To), assignment.ChangedAt)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	assignment.ID = int(id)
+	return tx.Commit()
+}
+
+func (t todoDatabase) GetAssignments(todoId int) (*[]model.Assignment, error) {
+	assignments := []model.Assignment{}
+	rows, err := t.db.Query(sqlGetTodoAssignments, todoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var assignment model.Assignment
+		var from, to sql.NullInt64
+		if err := rows.Scan(&assignment.ID, &assignment.TodoId, &assignment.UserId, &from, &to, &assignment.ChangedAt); err != nil {
+			return nil, err
+		}
+		assignment.From, assignment.To = nullInt(from), nullInt(to)
+		assignments = append(assignments, assignment)
+	}
+	return &assignments, rows.Err()
+}
+
+// nullInt reads a nullable id column, nil for NULL
+func nullInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	id := int(value.Int64)
+	return &id
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateAttachment = `
+    CREATE TABLE IF NOT EXISTS attachment(
+        attachment_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        todo_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		file_name VARCHAR NOT NULL,
+		content_type VARCHAR NOT NULL,
+		size INTEGER NOT NULL,
+		blob_key VARCHAR NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	// sqlInsertAttachment only inserts the attachment while the uploads of the user stay within the quota,
+	// it takes the attachment, then the user id, the size and the quota
+	sqlInsertAttachment = `
+	INSERT INTO attachment
+		(todo_id,user_id,file_name,content_type,size,blob_key,created_at)
+		SELECT ?,?,?,?,?,?,?
+		WHERE (SELECT COALESCE(SUM(size), 0) FROM attachment WHERE user_id = ?) + ? <= ?;
+	`
+	sqlGetAttachmentById = `
+	SELECT attachment_id,todo_id,user_id,file_name,content_type,size,blob_key,created_at FROM attachment
+		WHERE attachment_id = ?
+	`
+	sqlGetAttachments = `
+	SELECT attachment_id,todo_id,user_id,file_name,content_type,size,blob_key,created_at FROM attachment
+		WHERE todo_id = ?
+		ORDER BY created_at, attachment_id
+	`
+	// sqlGetUserAttachments lists the attachments the user uploaded to its own todos, for the export
+	sqlGetUserAttachments = `
+	SELECT attachment.attachment_id,attachment.todo_id,attachment.user_id,attachment.file_name,attachment.content_type,
+		attachment.size,attachment.blob_key,attachment.created_at
+		FROM attachment JOIN todo ON todo.todo_id = attachment.todo_id
+		WHERE attachment.user_id = ? AND todo.user_id = ? AND todo.deleted_at IS NULL
+		ORDER BY attachment.created_at, attachment.attachment_id
+	`
+	sqlDeleteAttachment = `
+	DELETE FROM attachment
+		WHERE attachment_id = ?
+	`
+	sqlGetUsedStorage = `
+	SELECT COALESCE(SUM(size), 0) FROM attachment
+		WHERE user_id = ?
+	`
+	sqlGetSubtreeAttachmentKeys = `
+	SELECT blob_key FROM attachment
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+	sqlDeleteSubtreeAttachments = `
+	DELETE FROM attachment
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+	// sqlGetUserAttachmentKeys are the blobs going with the account: the files the user uploaded
+	// and the files on its todos, it takes the user id twice
+	sqlGetUserAttachmentKeys = `
+	SELECT blob_key FROM attachment
+		WHERE user_id = ? OR todo_id IN (SELECT todo_id FROM todo WHERE user_id = ?)
+	`
+)
+
+func scanAttachment(row scanner) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := row.Scan(&attachment.ID, &attachment.TodoId, &attachment.UserId, &attachment.FileName, &attachment.ContentType, &attachment.Size, &attachment.Key, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// AddAttachment saves the attachment unless it takes the uploads of its user over the quota,
+// it returns false without error in that case
+func (t todoDatabase) AddAttachment(attachment *model.Attachment, quota int64) (bool, error) {
+	res, err := t.db.Exec(sqlInsertAttachment, attachment.TodoId, attachment.UserId, attachment.FileName, attachment.ContentType,
+		attachment.Size, attachment.Key, attachment.CreatedAt, attachment.UserId, attachment.Size, quota)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	attachment.ID = int(id)
+	return true, nil
+}
+
+func (t todoDatabase) GetAttachmentById(attachmentId int) (*model.Attachment, error) {
+	return scanAttachment(t.db.QueryRow(sqlGetAttachmentById, attachmentId))
+}
+
+// GetAttachments returns the attachments of the todo, oldest first
+func (t todoDatabase) GetAttachments(todoId int) (*[]model.Attachment, error) {
+	rows, err := t.db.Query(sqlGetAttachments, todoId)
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+// GetUserAttachments returns the attachments the user uploaded to its own todos
+func (t todoDatabase) GetUserAttachments(userId int) (*[]model.Attachment, error) {
+	rows, err := t.db.Query(sqlGetUserAttachments, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+func scanAttachments(rows *sql.Rows) (*[]model.Attachment, error) {
+	defer rows.Close()
+	attachments := []model.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	return &attachments, rows.Err()
+}
+
+func (t todoDatabase) DeleteAttachment(attachmentId int) (int64, error) {
+	res, err := t.db.Exec(sqlDeleteAttachment, attachmentId)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetUsedStorage returns the total size of the files the user uploaded
+func (t todoDatabase) GetUsedStorage(userId int) (int64, error) {
+	var used int64
+	err := t.db.QueryRow(sqlGetUsedStorage, userId).Scan(&used)
+	return used, err
+}
+
+// GetSubtreeAttachmentKeys returns the blob keys of the attachments of the todo and its subtasks
+func (t todoDatabase) GetSubtreeAttachmentKeys(todoId int) ([]string, error) {
+	return t.getBlobKeys(sqlGetSubtreeAttachmentKeys, todoId)
+}
+
+// GetUserAttachmentKeys returns the blob keys of the attachments removed with the account of the user
+func (t todoDatabase) GetUserAttachmentKeys(userId int) ([]string, error) {
+	return t.getBlobKeys(sqlGetUserAttachmentKeys, userId, userId)
+}
+
+func (t todoDatabase) getBlobKeys(query string, args ...interface{}) ([]string, error) {
+	rows, err := t.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,412 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"todo/constants"
+	"todo/model"
+)
+
+const (
+	sqlCreateComment = `
+    CREATE TABLE IF NOT EXISTS comment(
+        comment_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        todo_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		body VARCHAR NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlCreateCommentMention = `
+    CREATE TABLE IF NOT EXISTS comment_mention(
+        comment_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		PRIMARY KEY (comment_id, user_id),
+		FOREIGN KEY (comment_id) REFERENCES comment (comment_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlCreateTodoEvent = `
+    CREATE TABLE IF NOT EXISTS todo_event(
+        event_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        todo_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		kind VARCHAR NOT NULL,
+		from_value VARCHAR NOT NULL DEFAULT '',
+		to_value VARCHAR NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertComment = `
+	INSERT INTO comment
+		(todo_id,user_id,body,created_at)
+		VALUES (?,?,?,?);
+	`
+	sqlUpdateComment = `
+	UPDATE comment
+		SET body = ?,
+		updated_at = ?
+		WHERE comment_id = ?
+	`
+	sqlDeleteComment = `
+	DELETE FROM comment
+		WHERE comment_id = ?
+	`
+	sqlInsertCommentMention = `
+	INSERT INTO comment_mention
+		(comment_id,user_id)
+		VALUES (?,?);
+	`
+	sqlDeleteCommentMentions = `
+	DELETE FROM comment_mention
+		WHERE comment_id = ?
+	`
+	sqlGetCommentById = `
+	SELECT comment.comment_id,comment.todo_id,comment.user_id,COALESCE(user.name, ''),comment.body,comment.created_at,comment.updated_at
+		FROM comment LEFT JOIN user ON user.user_id = comment.user_id
+		WHERE comment.comment_id = ?
+	`
+	sqlGetComments = `
+	SELECT comment.comment_id,comment.todo_id,comment.user_id,COALESCE(user.name, ''),comment.body,comment.created_at,comment.updated_at
+		FROM comment LEFT JOIN user ON user.user_id = comment.user_id
+		WHERE comment.todo_id = ?
+		ORDER BY comment.created_at, comment.comment_id
+		LIMIT ? OFFSET ?
+	`
+	// sqlGetUserComments lists the comments the user wrote on its own todos, for the export
+	sqlGetUserComments = `
+	SELECT comment.comment_id,comment.todo_id,comment.user_id,COALESCE(user.name, ''),comment.body,comment.created_at,comment.updated_at
+		FROM comment JOIN todo ON todo.todo_id = comment.todo_id
+		LEFT JOIN user ON user.user_id = comment.user_id
+		WHERE comment.user_id = ? AND todo.user_id = ? AND todo.deleted_at IS NULL
+		ORDER BY comment.created_at, comment.comment_id
+	`
+	sqlCountComments = `
+	SELECT COUNT(*) FROM comment
+		WHERE todo_id = ?
+	`
+	sqlGetTodoMentions = `
+	SELECT comment_mention.comment_id,comment_mention.user_id
+		FROM comment_mention JOIN comment ON comment.comment_id = comment_mention.comment_id
+		WHERE comment.todo_id = ?
+		ORDER BY comment_mention.comment_id, comment_mention.user_id
+	`
+	// sqlGetTodoParticipants are the users who can access the todo: its creator, the owner of its
+	// category and the members of it, it takes the todo id three times
+	sqlGetTodoParticipants = `
+	SELECT user_id,name,email FROM user
+		WHERE user_id IN (
+			SELECT user_id FROM todo WHERE todo_id = ?
+			UNION
+			SELECT category.user_id FROM category JOIN todo ON todo.category = category.category_id WHERE todo.todo_id = ?
+			UNION
+			SELECT category_member.user_id FROM category_member JOIN todo ON todo.category = category_member.category_id WHERE todo.todo_id = ?)
+		ORDER BY user_id
+	`
+	sqlInsertTodoEvent = `
+	INSERT INTO todo_event
+		(todo_id,user_id,kind,from_value,to_value,created_at)
+		VALUES (?,?,?,?,?,?);
+	`
+	// sqlTodoActivity puts the creation of the todo, its comments, status changes, assignments
+	// and events in one feed, it takes the todo id five times
+	sqlTodoActivity = `
+	SELECT '` + constants.ActivityCreated + `' AS kind, todo.todo_id AS id, todo.user_id, COALESCE(user.name, '') AS user_name,
+		'' AS body, '' AS from_value, '' AS to_value, todo.created_at AS created_at, 0 AS updated_at
+		FROM todo LEFT JOIN user ON user.user_id = todo.user_id
+		WHERE todo.todo_id = ?
+	UNION ALL
+	SELECT '` + constants.ActivityComment + `', comment.comment_id, comment.user_id, COALESCE(user.name, ''),
+		comment.body, '', '', comment.created_at, comment.updated_at
+		FROM comment LEFT JOIN user ON user.user_id = comment.user_id
+		WHERE comment.todo_id = ?
+	UNION ALL
+	SELECT '` + constants.ActivityStatus + `', status_change.change_id, status_change.user_id, COALESCE(user.name, ''),
+		'', status_change.from_status, status_change.to_status, status_change.changed_at, 0
+		FROM status_change LEFT JOIN user ON user.user_id = status_change.user_id
+		WHERE status_change.todo_id = ?
+	UNION ALL
+	SELECT '` + constants.ActivityAssignee + `', todo_assignment.assignment_id, todo_assignment.user_id, COALESCE(user.name, ''),
+		'', COALESCE(from_user.name, ''), COALESCE(to_user.name, ''), todo_assignment.changed_at, 0
+		FROM todo_assignment LEFT JOIN user ON user.user_id = todo_assignment.user_id
+		LEFT JOIN user AS from_user ON from_user.user_id = todo_assignment.from_user
+		LEFT JOIN user AS to_user ON to_user.user_id = todo_assignment.to_user
+		WHERE todo_assignment.todo_id = ?
+	UNION ALL
+	SELECT todo_event.kind, todo_event.event_id, todo_event.user_id, COALESCE(user.name, ''),
+		'', todo_event.from_value, todo_event.to_value, todo_event.created_at, 0
+		FROM todo_event LEFT JOIN user ON user.user_id = todo_event.user_id
+		WHERE todo_event.todo_id = ?
+	`
+	sqlGetTodoActivity = `
+	SELECT kind,id,user_id,user_name,body,from_value,to_value,created_at,updated_at FROM (` + sqlTodoActivity + `)
+		ORDER BY created_at, kind = '` + constants.ActivityCreated + `' DESC, id
+		LIMIT ? OFFSET ?
+	`
+	sqlCountTodoActivity = `
+	SELECT COUNT(*) FROM (` + sqlTodoActivity + `)
+	`
+	sqlDeleteSubtreeMentions = `
+	DELETE FROM comment_mention
+		WHERE comment_id IN (SELECT comment_id FROM comment WHERE todo_id IN (` + sqlTodoSubtree + `))
+	`
+	sqlDeleteSubtreeComments = `
+	DELETE FROM comment
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+	sqlDeleteSubtreeEvents = `
+	DELETE FROM todo_event
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+)
+
+func scanComment(row scanner) (*model.Comment, error) {
+	var comment model.Comment
+	err := row.Scan(&comment.ID, &comment.TodoId, &comment.UserId, &comment.UserName, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	comment.Mentions = []int{}
+	return &comment, nil
+}
+
+// AddComment saves the comment with the users it mentions
+func (t todoDatabase) AddComment(comment *model.Comment) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(sqlInsertComment, comment.TodoId, comment.UserId, comment.Body, comment.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	comment.ID = int(id)
+	if err := insertMentions(tx, comment); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateComment saves the new body of the comment and replaces its mentions
+func (t todoDatabase) UpdateComment(comment *model.Comment) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlUpdateComment, comment.Body, comment.UpdatedAt, comment.ID); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteCommentMentions, comment.ID); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	if err := insertMentions(tx, comment); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertMentions(tx *sql.Tx, comment *model.Comment) error {
+	for _, userId := range comment.Mentions {
+		if _, err := tx.Exec(sqlInsertCommentMention, comment.ID, userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t todoDatabase) DeleteComment(commentId int) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteCommentMentions, commentId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteComment, commentId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (t todoDatabase) GetCommentById(commentId int) (*model.Comment, error) {
+	comment, err := scanComment(t.db.QueryRow(sqlGetCommentById, commentId))
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := t.getTodoMentions(comment.TodoId)
+	if err != nil {
+		return nil, err
+	}
+	if ids, ok := mentions[comment.ID]; ok {
+		comment.Mentions = ids
+	}
+	return comment, nil
+}
+
+// GetComments returns a page of the comments on the todo, oldest first, with the number of all of them
+func (t todoDatabase) GetComments(todoId, limit, offset int) (*model.CommentPage, error) {
+	page := model.CommentPage{}
+	if err := t.db.QueryRow(sqlCountComments, todoId).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+	mentions, err := t.getTodoMentions(todoId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := t.db.Query(sqlGetComments, todoId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comments := []model.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		if ids, ok := mentions[comment.ID]; ok {
+			comment.Mentions = ids
+		}
+		comments = append(comments, *comment)
+	}
+	page.Comments = &comments
+	return &page, rows.Err()
+}
+
+// GetUserComments returns the comments the user wrote on its own todos, without their mentions
+func (t todoDatabase) GetUserComments(userId int) (*[]model.Comment, error) {
+	rows, err := t.db.Query(sqlGetUserComments, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comments := []model.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	return &comments, rows.Err()
+}
+
+// getTodoMentions returns the ids of the users mentioned by each comment on the todo
+func (t todoDatabase) getTodoMentions(todoId int) (map[int][]int, error) {
+	rows, err := t.db.Query(sqlGetTodoMentions, todoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	mentions := map[int][]int{}
+	for rows.Next() {
+		var commentId, userId int
+		if err := rows.Scan(&commentId, &userId); err != nil {
+			return nil, err
+		}
+		mentions[commentId] = append(mentions[commentId], userId)
+	}
+	return mentions, rows.Err()
+}
+
+// GetTodoParticipants returns the users who can access the todo, with their id, name and email
+func (t todoDatabase) GetTodoParticipants(todoId int) (*[]model.User, error) {
+	rows, err := t.db.Query(sqlGetTodoParticipants, todoId, todoId, todoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := []model.User{}
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return &users, rows.Err()
+}
+
+func (t todoDatabase) AddTodoEvent(event *model.TodoEvent) error {
+	res, err := t.db.Exec(sqlInsertTodoEvent, event.TodoId, event.UserId, event.Kind, event.From, event.To, event.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	event.ID = int(id)
+	return nil
+}
+
+// GetTodoActivity returns a page of the activity feed of the todo, oldest first, with the number of all entries.
+// Comments come with their body and mentions, the summaries are up to the caller.
+func (t todoDatabase) GetTodoActivity(todoId, limit, offset int) (*model.ActivityPage, error) {
+	page := model.ActivityPage{}
+	if err := t.db.QueryRow(sqlCountTodoActivity, todoId, todoId, todoId, todoId, todoId).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+	mentions, err := t.getTodoMentions(todoId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := t.db.Query(sqlGetTodoActivity, todoId, todoId, todoId, todoId, todoId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.Activity{}
+	for rows.Next() {
+		var item model.Activity
+		var body string
+		var updatedAt int64
+		if err := rows.Scan(&item.Kind, &item.ID, &item.UserId, &item.UserName, &body, &item.From, &item.To, &item.CreatedAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if item.Kind == constants.ActivityComment {
+			item.Comment = &model.Comment{
+				ID:        item.ID,
+				TodoId:    todoId,
+				UserId:    item.UserId,
+				UserName:  item.UserName,
+				Body:      body,
+				Mentions:  []int{},
+				CreatedAt: item.CreatedAt,
+				UpdatedAt: updatedAt,
+			}
+			if ids, ok := mentions[item.ID]; ok {
+				item.Comment.Mentions = ids
+			}
+		}
+		items = append(items, item)
+	}
+	page.Items = &items
+	return &page, rows.Err()
+}
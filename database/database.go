@@ -4,12 +4,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"todo/filter"
 	"todo/model"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// userColumns are the user columns in the order scanUser reads them
+const userColumns = "user_id,name,email,password,email_verified,created_at,role,disabled,must_reset_password,time_zone"
+
+// todoColumns are the todo columns in the order scanTodo reads them
+const todoColumns = "todo_id,title,description,due_at,all_day,priority,status,completed,user_id,assignee_id,category,parent_id,auto_complete,recurrence,recurrence_anchor,series_id,occurrence,created_at"
+
 const (
 	sqlCreateUser = `
     CREATE TABLE IF NOT EXISTS user(
@@ -43,16 +51,26 @@ const (
     `
 	sqlInsertUser = `
 	INSERT INTO user 
-		(name,email,password) VALUES (?,?,?)
+		(name,email,password,email_verified,created_at,role,time_zone) VALUES (?,?,?,?,?,?,?)
 	`
 	sqlFindUserByEmail = `
-	SELECT * FROM user
+	SELECT ` + userColumns + ` FROM user
 		WHERE email = ?;
 	`
+	sqlGetUserById = `
+	SELECT ` + userColumns + ` FROM user
+		WHERE user_id = ?;
+	`
+	sqlUpdateUserPassword = `
+	UPDATE user
+		SET password = ?,
+		must_reset_password = 0
+		WHERE user_id = ?
+	`
 	sqlInsertTodo = `
 	INSERT INTO todo
-		(title,description,due_date,priority,completed,user_id,category)
-		VALUES (?,?,?,?,?,?,?);
+		(title,description,due_at,all_day,priority,status,completed,user_id,assignee_id,category,parent_id,auto_complete,recurrence,recurrence_anchor,series_id,occurrence,created_at)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
 		`
 	sqlInsertCategory = `
 	INSERT INTO category
@@ -60,17 +78,19 @@ const (
 		VALUES (?,?);
 		`
 	sqlGetCategory = `
-	SELECT * FROM category 
-		WHERE user_id = ?;
+	SELECT category_id,category_name,user_id FROM category 
+		WHERE user_id = ?
+		AND deleted_at IS NULL;
 		`
 	sqlGetCategoryById = `
-	SELECT * FROM category 
+	SELECT category_id,category_name,user_id FROM category 
 		WHERE user_id = ? 
-		AND category_id = ?;
+		AND category_id = ?
+		AND deleted_at IS NULL;
 		`
 	sqlDeleteTodo = `
 	DELETE from todo 
-		WHERE todo_id = ? 
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
     `
 	sqlDeleteCategory = `
 	DELETE from category 
@@ -80,54 +100,174 @@ const (
 	UPDATE todo 
 		SET title = ?,
 		description = ?,
-		due_date = ?,
+		due_at = ?,
+		all_day = ?,
 		priority = ?,
 		completed = ?,
 		user_id = ?,
-		category = ?
+		category = ?,
+		parent_id = ?,
+		auto_complete = ?,
+		recurrence = ?,
+		recurrence_anchor = ?
 		WHERE todo_id = ?
 	`
-	sqlUpdateTodoCompleted = `
-	UPDATE todo 
-		SET completed = ?
-	 	WHERE todo_id = ?;
-	`
 	sqlGetAllTodo = `
-	SELECT * FROM todo 
+	SELECT ` + todoColumns + ` FROM todo 
 		WHERE user_id = ?
+		AND deleted_at IS NULL
 	 `
 	sqlGetTodoById = `
-	SELECT * FROM todo 
+	SELECT ` + todoColumns + ` FROM todo 
 		WHERE todo_id = ?
+		AND deleted_at IS NULL
 	`
 
 	sqlCategoryById = `
 	SELECT user_id FROM category 
 		WHERE category_id = ?
+		AND deleted_at IS NULL
 	`
 
 	sqlGetAllTodoByCategory = `
-	SELECT * FROM todo 
-		WHERE user_id = ? AND category = ?
+	SELECT ` + todoColumns + ` FROM todo 
+		WHERE category = ?
+		AND deleted_at IS NULL
 	 `
 )
 
 type TodoDatabase interface {
 	CreateUser(u *model.User) error
 	FindUserByEmail(email string) (*model.User, error)
+	GetUserById(id int) (*model.User, error)
 	AddTodo(to *model.Todo) error
 	GetTodoById(id string) (*model.Todo, error)
 	DeleteTodo(id string) (int64, error)
 	GetAllTodo(id int) (*[]model.Todo, error)
 	UpdateTodo(getTodo *model.EditTodo) error
-	UpdateCompleted(completed int, id int) error
 	AddCategory(category *model.Category) error
 	GetCategoryUserById(id int) (*int, error)
-	GetAllTodoByCategory(id int, categoryId int) (*[]model.Todo, error)
+	GetAllTodoByCategory(categoryId int) (*[]model.Todo, error)
 	CheckEmailExists(email string) bool
 	GetCategory(id int) (*[]model.Category, error)
 	DeleteCategory(id int) (int64, error)
 	GetCategoryById(userId, categoryId int) (*model.Category, error)
+	CreateSession(s *model.Session) error
+	GetSessionById(id int) (*model.Session, error)
+	GetSessionByRefreshToken(hash string) (*model.Session, error)
+	RotateSession(id int, oldHash, hash string, expiresAt int64) (int64, error)
+	RevokeSession(id int) error
+	RevokeUserSessions(userId int) error
+	UpdateUserPassword(userId int, password string) error
+	CreatePasswordReset(r *model.PasswordReset) error
+	GetPasswordResetByToken(hash string) (*model.PasswordReset, error)
+	UsePasswordReset(id int, now int64) (int64, error)
+	InvalidateUserPasswordResets(userId int) error
+	CreateEmailVerification(v *model.EmailVerification) error
+	GetEmailVerificationByToken(hash string) (*model.EmailVerification, error)
+	UseEmailVerification(id int, now int64) (int64, error)
+	DeleteEmailVerifications(userId int) error
+	SetEmailVerified(userId int, email string) (int64, error)
+	CreatePersonalAccessToken(pat *model.PersonalAccessToken) error
+	GetPersonalAccessTokens(userId int) (*[]model.PersonalAccessToken, error)
+	GetPersonalAccessTokenByHash(hash string) (*model.PersonalAccessToken, error)
+	RevokePersonalAccessToken(userId, id int) (int64, error)
+	RevokeUserPersonalAccessTokens(userId int) error
+	TouchPersonalAccessToken(id int, now int64) error
+	SaveUserTOTP(totp *model.UserTOTP) error
+	GetUserTOTP(userId int) (*model.UserTOTP, error)
+	ConfirmUserTOTP(userId int) error
+	UseTOTPStep(userId int, step int64) (int64, error)
+	DeleteUserTOTP(userId int) error
+	ReplaceRecoveryCodes(userId int, hashes []string) error
+	UseRecoveryCode(userId int, hash string) (int64, error)
+	SearchUsers(query string, limit, offset int) (*[]model.User, error)
+	SetUserRole(userId int, role string) (int64, error)
+	SetUserRoleByEmail(email string, role string) (int64, error)
+	SetUserDisabled(userId int, disabled bool) (int64, error)
+	SetMustResetPassword(userId int) (int64, error)
+	GetStats() (*model.Stats, error)
+	UpdateUserProfile(u *model.User) error
+	RevokeOtherSessions(userId, sessionId int) error
+	DeleteUser(userId int) error
+	ImportData(userId int, export *model.Export) error
+	GetChildTodos(parentId int) (*[]model.Todo, error)
+	GetTodoAncestors(id int) ([]int, error)
+	GetSubtreeHeight(id int) (int, error)
+	AddTag(tag *model.Tag) error
+	GetTags(userId int) (*[]model.Tag, error)
+	GetTagById(userId, tagId int) (*model.Tag, error)
+	UpdateTag(tag *model.Tag) error
+	DeleteTag(tagId int) error
+	SetTodoTags(userId, todoId int, tagIds []int) error
+	GetTodoTags(userId int) (map[int][]int, error)
+	GetSeriesOccurrence(seriesId, occurrence int) (*model.Todo, error)
+	UpdateTodoOccurrence(id int, dueDate time.Time, occurrence int) error
+	GetInvalidDueDates() (*[]model.InvalidDueDate, error)
+	EndSeries(seriesId int) error
+	AddReminder(reminder *model.Reminder) error
+	GetReminders(todoId, userId int) (*[]model.Reminder, error)
+	GetUserReminders(userId int) (*[]model.Reminder, error)
+	GetReminderById(userId, reminderId int) (*model.Reminder, error)
+	DeleteReminder(reminderId int) error
+	CopyOffsetReminders(fromTodoId, toTodoId int) error
+	ListTodos(userId int, query model.TodoQuery, dueBefore, dueAfter *model.DueBound, expr filter.Expr) (*model.TodoPage, error)
+	SearchTodos(userId int, input string, limit int) (*[]model.SearchResult, error)
+	AddSmartList(list *model.SmartList) error
+	GetSmartLists(userId int) (*[]model.SmartList, error)
+	GetSmartListById(userId, listId int) (*model.SmartList, error)
+	UpdateSmartList(list *model.SmartList) error
+	DeleteSmartList(listId int) error
+	GetWorkflow(userId, categoryId int) (*model.Workflow, error)
+	GetWorkflows(userId int) (*[]model.Workflow, error)
+	SetWorkflow(userId int, workflow *model.Workflow) error
+	DeleteWorkflow(userId, categoryId int) (int64, error)
+	UpdateTodoStatus(change *model.StatusChange, completed bool) error
+	GetStatusChanges(todoId int) (*[]model.StatusChange, error)
+	AddDependency(dependency *model.Dependency) error
+	DeleteDependency(todoId, blockerId int) (int64, error)
+	GetDependencies(userId int) (*[]model.Dependency, error)
+	DependsOn(todoId, blockerId int) (bool, error)
+	GetBlockers(todoId, userId int) (*[]model.Todo, error)
+	GetDependents(todoId, userId int) (*[]model.Todo, error)
+	AddCategoryMember(member *model.CategoryMember) error
+	GetCategoryMembers(categoryId int) (*[]model.CategoryMember, error)
+	GetCategoryMember(categoryId, userId int) (*model.CategoryMember, error)
+	UpdateCategoryMember(categoryId, userId int, role string) error
+	DeleteCategoryMember(categoryId, userId int) (int64, error)
+	DeleteCategoryMembers(categoryId int) error
+	GetSharedCategories(userId int) (*[]model.Category, error)
+	GetVisibleTodos(userId int) (*[]model.Todo, error)
+	AssignTodo(assignment *model.Assignment) error
+	GetAssignments(todoId int) (*[]model.Assignment, error)
+	AddComment(comment *model.Comment) error
+	UpdateComment(comment *model.Comment) error
+	DeleteComment(commentId int) error
+	GetCommentById(commentId int) (*model.Comment, error)
+	GetComments(todoId, limit, offset int) (*model.CommentPage, error)
+	GetUserComments(userId int) (*[]model.Comment, error)
+	GetTodoParticipants(todoId int) (*[]model.User, error)
+	AddTodoEvent(event *model.TodoEvent) error
+	GetTodoActivity(todoId, limit, offset int) (*model.ActivityPage, error)
+	AddAttachment(attachment *model.Attachment, quota int64) (bool, error)
+	GetAttachmentById(attachmentId int) (*model.Attachment, error)
+	GetAttachments(todoId int) (*[]model.Attachment, error)
+	GetUserAttachments(userId int) (*[]model.Attachment, error)
+	DeleteAttachment(attachmentId int) (int64, error)
+	GetUsedStorage(userId int) (int64, error)
+	GetSubtreeAttachmentKeys(todoId int) ([]string, error)
+	GetUserAttachmentKeys(userId int) ([]string, error)
+	TrashTodo(todoId int, deletedAt int64) (int64, error)
+	RestoreTodo(todoId int) (int64, error)
+	GetTrashedTodoById(todoId int) (*model.Todo, error)
+	GetTrashedTodos(userId int) (*[]model.Todo, error)
+	GetExpiredTodos(before int64) ([]int, error)
+	TrashCategory(categoryId int, deletedAt int64) (int64, error)
+	RestoreCategory(categoryId int) (int64, error)
+	GetCategoryTrashedTodos(categoryId int) ([]int, error)
+	GetTrashedCategoryById(categoryId int) (*model.Category, error)
+	GetTrashedCategories(userId int) (*[]model.Category, error)
+	GetExpiredCategories(before int64) (*[]model.Category, error)
 }
 type todoDatabase struct {
 	db *sql.DB
@@ -159,6 +299,31 @@ func migrate(db *sql.DB) error {
 	if err != nil {
 		return err
 	}
+	// accounts created before email verification existed count as verified
+	err = addColumn(db, "user", "email_verified", "INTEGER DEFAULT 1")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "user", "created_at", "INTEGER DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "user", "role", "VARCHAR NOT NULL DEFAULT 'user'")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "user", "disabled", "INTEGER DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "user", "must_reset_password", "INTEGER DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "user", "time_zone", "VARCHAR NOT NULL DEFAULT 'UTC'")
+	if err != nil {
+		return err
+	}
 	_, err = db.Exec(sqlCreateCategory)
 	if err != nil {
 		return err
@@ -167,85 +332,382 @@ func migrate(db *sql.DB) error {
 	if err != nil {
 		return err
 	}
+	err = addColumn(db, "todo", "parent_id", "INTEGER REFERENCES todo (todo_id)")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "auto_complete", "INTEGER DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "recurrence", "VARCHAR NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "recurrence_anchor", "VARCHAR NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "series_id", "INTEGER REFERENCES todo (todo_id)")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "occurrence", "INTEGER NOT NULL DEFAULT 1")
+	if err != nil {
+		return err
+	}
+	// due dates move from the free-form due_date column to unix seconds in due_at
+	err = addColumn(db, "todo", "due_at", "INTEGER")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "all_day", "INTEGER DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	// todos from before created_at was tracked sort by their id among themselves
+	err = addColumn(db, "todo", "created_at", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "todo", "status", "VARCHAR NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlMigrateTodoStatus)
+	if err != nil {
+		return err
+	}
+	// todos from before assignees belong to nobody until they are assigned
+	err = addColumn(db, "todo", "assignee_id", "INTEGER REFERENCES user (user_id)")
+	if err != nil {
+		return err
+	}
+	// trashed todos and categories keep the time they were deleted until they are purged
+	err = addColumn(db, "todo", "deleted_at", "INTEGER")
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "category", "deleted_at", "INTEGER")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlTrashTodosOfTrashedCategories)
+	if err != nil {
+		return err
+	}
+	err = migrateDueDates(db)
+	if err != nil {
+		return err
+	}
+	err = migrateSearch(db)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateTag)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateTodoTag)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateSmartList)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateReminder)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateWorkflow)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateStatusChange)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateTodoDependency)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateCategoryMember)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateTodoAssignment)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateComment)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateCommentMention)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateTodoEvent)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateAttachment)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateSession)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreatePasswordReset)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateEmailVerification)
+	if err != nil {
+		return err
+	}
+	err = addColumn(db, "email_verification", "email", "VARCHAR NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreatePersonalAccessToken)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateUserTOTP)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateRecoveryCode)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(sqlCreateLoginAttempt)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+// addColumn adds a column to an existing table, sqlite has no ADD COLUMN IF NOT EXISTS
+func addColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		err = rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk)
+		if err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row scanner) (*model.User, error) {
+	getuser := model.User{}
+	err := row.Scan(&getuser.ID, &getuser.Name, &getuser.Email, &getuser.Password, &getuser.EmailVerified, &getuser.CreatedAt, &getuser.Role, &getuser.Disabled, &getuser.MustResetPassword, &getuser.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	return &getuser, nil
+}
+
 func (t todoDatabase) CreateUser(u *model.User) error {
-	_, err := t.db.Exec(sqlInsertUser, u.Name, u.Email, u.Password)
+	res, err := t.db.Exec(sqlInsertUser, u.Name, u.Email, u.Password, u.EmailVerified, u.CreatedAt, u.Role, u.TimeZone)
 	if err != nil {
 		fmt.Println(err)
 		return err
 	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	u.ID = int(id)
 	return nil
 }
 
 func (t todoDatabase) FindUserByEmail(email string) (*model.User, error) {
-	getuser := model.User{}
-	err := t.db.QueryRow(sqlFindUserByEmail, email).Scan(&getuser.ID, &getuser.Name, &getuser.Email, &getuser.Password)
+	return scanUser(t.db.QueryRow(sqlFindUserByEmail, email))
+}
+
+func (t todoDatabase) GetUserById(id int) (*model.User, error) {
+	return scanUser(t.db.QueryRow(sqlGetUserById, id))
+}
+
+func (t todoDatabase) UpdateUserPassword(userId int, password string) error {
+	_, err := t.db.Exec(sqlUpdateUserPassword, password, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanTodo(row scanner) (*model.Todo, error) {
+	getTodo := model.Todo{}
+	var assigneeId, parentId, seriesId, dueAt sql.NullInt64
+	err := row.Scan(&getTodo.ID, &getTodo.Title, &getTodo.Description, &dueAt, &getTodo.AllDay, &getTodo.Priority, &getTodo.Status, &getTodo.Completed, &getTodo.UserId, &assigneeId, &getTodo.Category, &parentId, &getTodo.AutoComplete, &getTodo.Recurrence, &getTodo.RecurrenceAnchor, &seriesId, &getTodo.Occurrence, &getTodo.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
-	return &getuser, nil
+	getTodo.AssigneeId = nullInt(assigneeId)
+	getTodo.ParentId = nullInt(parentId)
+	getTodo.SeriesId = nullInt(seriesId)
+	// due dates the migration could not read stay empty
+	if dueAt.Valid {
+		getTodo.DueDate = model.DueDate{Time: time.Unix(dueAt.Int64, 0).UTC(), DateOnly: getTodo.AllDay}
+	}
+	return &getTodo, nil
+}
+
+func scanTodos(rows *sql.Rows) (*[]model.Todo, error) {
+	var TodoList []model.Todo
+	defer rows.Close()
+	for rows.Next() {
+		getTodo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		TodoList = append(TodoList, *getTodo)
+	}
+	return &TodoList, rows.Err()
+}
+
+// nullableId stores a missing or zero id as NULL
+func nullableId(id *int) interface{} {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	return *id
+}
+
+// occurrence numbers start at 1
+func occurrence(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
 }
 
 func (t todoDatabase) AddTodo(to *model.Todo) error {
-	_, err := t.db.Exec(sqlInsertTodo, to.Title, to.Description, to.DueDate, to.Priority, to.Completed, to.UserId, to.Category)
+	if to.CreatedAt == 0 {
+		to.CreatedAt = time.Now().Unix()
+	}
+	res, err := t.db.Exec(sqlInsertTodo, to.Title, to.Description, dueAt(to.DueDate), to.AllDay, to.Priority, to.Status, to.Completed, to.UserId, nullableId(to.AssigneeId), to.Category, nullableId(to.ParentId), to.AutoComplete, to.Recurrence, to.RecurrenceAnchor, nullableId(to.SeriesId), occurrence(to.Occurrence), to.CreatedAt)
 	if err != nil {
 		fmt.Println(err)
 		return err
 	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	to.ID = int(id)
 	return nil
 }
 
 func (t todoDatabase) GetTodoById(id string) (*model.Todo, error) {
-	getTodo := model.Todo{}
-	err := t.db.QueryRow(sqlGetTodoById, id).Scan(&getTodo.ID, &getTodo.Title, &getTodo.Description, &getTodo.DueDate, &getTodo.Priority, &getTodo.Completed, &getTodo.UserId, &getTodo.Category)
+	getTodo, err := scanTodo(t.db.QueryRow(sqlGetTodoById, id))
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
 	}
-	fmt.Println(&getTodo)
-	return &getTodo, nil
+	return getTodo, nil
 }
 
+// DeleteTodo removes the todo and its subtasks for good, deleting a todo for the user moves it to the trash
 func (t todoDatabase) DeleteTodo(id string) (int64, error) {
-	res, err := t.db.Exec(sqlDeleteTodo, id)
+	tx, err := t.db.Begin()
 	if err != nil {
+		return 0, err
+	}
+	// the subtasks go with the todo, and so do their tags, reminders, history, dependencies, assignments,
+	// comments, events and attachments, the blobs of the attachments are up to the caller
+	if _, err = tx.Exec(sqlDeleteSubtreeTags, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeReminders, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeStatusChanges, id); err != nil {
 		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeDependencies, id, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeAssignments, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeMentions, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeComments, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeEvents, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlDeleteSubtreeAttachments, id); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	res, err := tx.Exec(sqlDeleteTodo, id)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
 		return 0, err
 	}
 	n, err := res.RowsAffected()
-	if n != 1 {
+	if n == 0 {
+		tx.Rollback()
 		return 0, err
 	}
-	return n, nil
+	return n, tx.Commit()
 }
 
 func (t todoDatabase) GetAllTodo(id int) (*[]model.Todo, error) {
-	var TodoList []model.Todo
-	var getTodo model.Todo
 	rows, err := t.db.Query(sqlGetAllTodo, id)
 	if err != nil {
 
 		return nil, err
 	}
-	for rows.Next() {
-		rows.Scan(&getTodo.ID, &getTodo.Title, &getTodo.Description, &getTodo.DueDate, &getTodo.Priority, &getTodo.Completed, &getTodo.UserId, &getTodo.Category)
-		TodoList = append(TodoList, getTodo)
-	}
-	return &TodoList, nil
+	return scanTodos(rows)
 }
 
 func (t todoDatabase) UpdateTodo(getTodo *model.EditTodo) error {
-	_, err := t.db.Exec(sqlUpdateTodo, &getTodo.Title, &getTodo.Description, &getTodo.DueDate, &getTodo.Priority, &getTodo.Completed, &getTodo.UserId, &getTodo.Category, &getTodo.ID)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (t todoDatabase) UpdateCompleted(completed int, id int) error {
-	_, err := t.db.Exec(sqlUpdateTodoCompleted, &completed, &id)
+	_, err := t.db.Exec(sqlUpdateTodo, &getTodo.Title, &getTodo.Description, dueAt(*getTodo.DueDate), getTodo.AllDay, &getTodo.Priority, &getTodo.Completed, &getTodo.UserId, &getTodo.Category, nullableId(getTodo.ParentId), getTodo.AutoComplete, getTodo.Recurrence, getTodo.RecurrenceAnchor, &getTodo.ID)
 	if err != nil {
 		return err
 	}
@@ -269,18 +731,12 @@ func (t todoDatabase) GetCategoryUserById(id int) (*int, error) {
 	return &categoryUserId, nil
 }
 
-func (t todoDatabase) GetAllTodoByCategory(id int, categoryId int) (*[]model.Todo, error) {
-	var TodoList []model.Todo
-	var getTodo model.Todo
-	rows, err := t.db.Query(sqlGetAllTodoByCategory, id, categoryId)
+func (t todoDatabase) GetAllTodoByCategory(categoryId int) (*[]model.Todo, error) {
+	rows, err := t.db.Query(sqlGetAllTodoByCategory, categoryId)
 	if err != nil {
 		return nil, err
 	}
-	for rows.Next() {
-		rows.Scan(&getTodo.ID, &getTodo.Title, &getTodo.Description, &getTodo.DueDate, &getTodo.Priority, &getTodo.Completed, &getTodo.UserId, &getTodo.Category)
-		TodoList = append(TodoList, getTodo)
-	}
-	return &TodoList, nil
+	return scanTodos(rows)
 }
 
 func (t todoDatabase) CheckEmailExists(email string) bool {
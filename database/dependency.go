@@ -0,0 +1,128 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	// sqlCreateTodoDependency holds the edges of the dependency graph, todo_id waits for blocker_id
+	sqlCreateTodoDependency = `
+    CREATE TABLE IF NOT EXISTS todo_dependency(
+        todo_id INTEGER NOT NULL,
+        blocker_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (todo_id, blocker_id),
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (blocker_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertTodoDependency = `
+	INSERT INTO todo_dependency
+		(todo_id,blocker_id,user_id,created_at)
+		VALUES (?,?,?,?);
+	`
+	sqlDeleteTodoDependency = `
+	DELETE FROM todo_dependency
+		WHERE todo_id = ? AND blocker_id = ?
+	`
+	// sqlGetDependencies lists the dependencies of the todos the user can see, except the ones on trashed blockers
+	sqlGetDependencies = `
+	SELECT todo_dependency.todo_id,todo_dependency.blocker_id,todo_dependency.user_id,todo_dependency.created_at
+		FROM todo_dependency JOIN todo ON todo.todo_id = todo_dependency.todo_id
+		WHERE ` + sqlVisibleTodos + `
+		AND todo_dependency.blocker_id NOT IN (SELECT todo_id FROM todo WHERE deleted_at IS NOT NULL)
+	`
+	// sqlDependsOn walks from the todo through everything it waits for, directly or not
+	sqlDependsOn = `
+	WITH RECURSIVE blockers(id) AS (
+		SELECT blocker_id FROM todo_dependency WHERE todo_id = ?
+		UNION
+		SELECT todo_dependency.blocker_id FROM todo_dependency JOIN blockers ON todo_dependency.todo_id = blockers.id
+	)
+	SELECT COUNT(*) FROM blockers WHERE id = ?
+	`
+	// sqlGetBlockers lists the todos the todo waits for which the user can see, it takes the todo id
+	// and then the user id three times
+	sqlGetBlockers = `
+	SELECT ` + todoColumns + ` FROM todo
+		WHERE todo_id IN (SELECT blocker_id FROM todo_dependency WHERE todo_id = ?) AND ` + sqlVisibleTodos + `
+		ORDER BY todo_id
+	`
+	// sqlGetDependents lists the todos waiting for the todo which the user can see, it takes the todo id
+	// and then the user id three times
+	sqlGetDependents = `
+	SELECT ` + todoColumns + ` FROM todo
+		WHERE todo_id IN (SELECT todo_id FROM todo_dependency WHERE blocker_id = ?) AND ` + sqlVisibleTodos + `
+		ORDER BY todo_id
+	`
+	sqlDeleteSubtreeDependencies = `
+	DELETE FROM todo_dependency
+		WHERE todo_id IN (` + sqlTodoSubtree + `) OR blocker_id IN (` + sqlTodoSubtree + `)
+	`
+)
+
+func (t todoDatabase) AddDependency(dependency *model.Dependency) error {
+	_, err := t.db.Exec(sqlInsertTodoDependency, dependency.TodoId, dependency.BlockerId, dependency.UserId, dependency.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) DeleteDependency(todoId, blockerId int) (int64, error) {
+	res, err := t.db.Exec(sqlDeleteTodoDependency, todoId, blockerId)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) GetDependencies(userId int) (*[]model.Dependency, error) {
+	dependencies := []model.Dependency{}
+	rows, err := t.db.Query(sqlGetDependencies, userId, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dependency model.Dependency
+		if err := rows.Scan(&dependency.TodoId, &dependency.BlockerId, &dependency.UserId, &dependency.CreatedAt); err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return &dependencies, rows.Err()
+}
+
+// DependsOn reports whether the todo waits for the blocker, directly or through other todos
+func (t todoDatabase) DependsOn(todoId, blockerId int) (bool, error) {
+	var n int
+	err := t.db.QueryRow(sqlDependsOn, todoId, blockerId).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetBlockers returns the todos the todo waits for, limited to the ones the user can see
+func (t todoDatabase) GetBlockers(todoId, userId int) (*[]model.Todo, error) {
+	rows, err := t.db.Query(sqlGetBlockers, todoId, userId, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanTodos(rows)
+}
+
+// GetDependents returns the todos waiting for the todo, limited to the ones the user can see
+func (t todoDatabase) GetDependents(todoId, userId int) (*[]model.Todo, error) {
+	rows, err := t.db.Query(sqlGetDependents, todoId, userId, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanTodos(rows)
+}
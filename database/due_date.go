@@ -0,0 +1,118 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"todo/model"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// the due_date column is read as text, the driver would otherwise turn it into a time itself
+	sqlGetUnmigratedDueDates = `
+	SELECT todo_id, CAST(due_date AS TEXT) FROM todo
+		WHERE due_at IS NULL AND due_date IS NOT NULL AND due_date != ''
+	`
+	sqlSetDueAt = `
+	UPDATE todo
+		SET due_at = ?,
+		all_day = ?
+		WHERE todo_id = ?
+	`
+	sqlGetInvalidDueDates = `
+	SELECT todo_id, user_id, CAST(due_date AS TEXT) FROM todo
+		WHERE due_at IS NULL AND due_date IS NOT NULL AND due_date != ''
+	`
+)
+
+// dueAt stores a due date as unix seconds, a missing one as NULL
+func dueAt(dueDate model.DueDate) interface{} {
+	if dueDate.IsZero() {
+		return nil
+	}
+	return dueDate.Unix()
+}
+
+// migrateDueDates copies the free-form due_date strings into due_at. Plain dates become all day todos,
+// timestamps without a zone are taken as UTC. Rows that can not be read are logged on every start
+// and listed for admins by GetInvalidDueDates, until someone edits their due date.
+func migrateDueDates(db *sql.DB) error {
+	type legacy struct {
+		id      int
+		dueDate string
+	}
+	rows, err := db.Query(sqlGetUnmigratedDueDates)
+	if err != nil {
+		return err
+	}
+	var todos []legacy
+	for rows.Next() {
+		var todo legacy
+		if err := rows.Scan(&todo.id, &todo.dueDate); err != nil {
+			rows.Close()
+			return err
+		}
+		todos = append(todos, todo)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	invalid := 0
+	for _, todo := range todos {
+		dueDate, allDay, ok := parseLegacyDueDate(todo.dueDate)
+		if !ok {
+			log.Printf("todo %d: can not read due date %q, it is left empty", todo.id, todo.dueDate)
+			invalid++
+			continue
+		}
+		if _, err := tx.Exec(sqlSetDueAt, dueDate.Unix(), allDay, todo.id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if invalid > 0 {
+		log.Printf("%d todos have a due date which could not be migrated", invalid)
+	}
+	return tx.Commit()
+}
+
+func parseLegacyDueDate(value string) (time.Time, bool, bool) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, true
+	}
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, false, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// GetInvalidDueDates lists the todos whose old due date could not be migrated
+func (t todoDatabase) GetInvalidDueDates() (*[]model.InvalidDueDate, error) {
+	invalid := []model.InvalidDueDate{}
+	rows, err := t.db.Query(sqlGetInvalidDueDates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dueDate model.InvalidDueDate
+		if err := rows.Scan(&dueDate.TodoId, &dueDate.UserId, &dueDate.DueDate); err != nil {
+			return nil, err
+		}
+		invalid = append(invalid, dueDate)
+	}
+	return &invalid, rows.Err()
+}
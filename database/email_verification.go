@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateEmailVerification = `
+    CREATE TABLE IF NOT EXISTS email_verification(
+        verification_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL,
+		email VARCHAR NOT NULL DEFAULT '',
+		token_hash VARCHAR NOT NULL UNIQUE,
+		expires_at INTEGER NOT NULL,
+		used INTEGER DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertEmailVerification = `
+	INSERT INTO email_verification
+		(user_id,email,token_hash,expires_at,used,created_at)
+		VALUES (?,?,?,?,0,?);
+	`
+	sqlGetEmailVerificationByToken = `
+	SELECT verification_id,user_id,email,token_hash,expires_at,used,created_at FROM email_verification
+		WHERE token_hash = ?
+	`
+	sqlUseEmailVerification = `
+	UPDATE email_verification
+		SET used = 1
+		WHERE verification_id = ? AND used = 0 AND expires_at > ?
+	`
+	// sqlSetEmailVerified only verifies the address the token was sent to, not one the user changed to since
+	sqlSetEmailVerified = `
+	UPDATE user
+		SET email_verified = 1
+		WHERE user_id = ? AND email = ?
+	`
+	sqlDeleteEmailVerifications = `
+	DELETE FROM email_verification
+		WHERE user_id = ?
+	`
+)
+
+func (t todoDatabase) CreateEmailVerification(v *model.EmailVerification) error {
+	res, err := t.db.Exec(sqlInsertEmailVerification, v.UserId, v.Email, v.TokenHash, v.ExpiresAt, v.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	v.ID = int(id)
+	return nil
+}
+
+func (t todoDatabase) GetEmailVerificationByToken(hash string) (*model.EmailVerification, error) {
+	var verification model.EmailVerification
+	err := t.db.QueryRow(sqlGetEmailVerificationByToken, hash).Scan(&verification.ID, &verification.UserId, &verification.Email, &verification.TokenHash, &verification.ExpiresAt, &verification.Used, &verification.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// UseEmailVerification marks the verification token as used, it affects no row if the token was already used or has expired
+func (t todoDatabase) UseEmailVerification(id int, now int64) (int64, error) {
+	res, err := t.db.Exec(sqlUseEmailVerification, id, now)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SetEmailVerified marks the email of the user as verified, it affects no row if the user has another email by now
+func (t todoDatabase) SetEmailVerified(userId int, email string) (int64, error) {
+	res, err := t.db.Exec(sqlSetEmailVerified, userId, email)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteEmailVerifications drops the outstanding verification tokens of the user
+func (t todoDatabase) DeleteEmailVerifications(userId int) error {
+	_, err := t.db.Exec(sqlDeleteEmailVerifications, userId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"todo/model"
+)
+
+const (
+	sqlSetTodoParent = `
+	UPDATE todo
+		SET parent_id = ?
+		WHERE todo_id = ?
+	`
+	sqlSetTodoSeries = `
+	UPDATE todo
+		SET series_id = ?
+		WHERE todo_id = ?
+	`
+	sqlImportComment = `
+	INSERT INTO comment
+		(todo_id,user_id,body,created_at,updated_at)
+		VALUES (?,?,?,?,?);
+	`
+	sqlImportReminder = `
+	INSERT INTO reminder
+		(todo_id,user_id,remind_at,offset_minutes,status,sent_at,last_error,created_at)
+		VALUES (?,?,?,?,?,?,?,?);
+	`
+)
+
+// ImportData restores categories, workflows, tags, todos, their reminders, dependencies and comments
+// and smart lists for the user in one transaction,
+// category, tag and todo ids from the export are mapped to the newly created ones
+func (t todoDatabase) ImportData(userId int, export *model.Export) error {
+	categories, workflows, tags, todos := export.Categories, export.Workflows, export.Tags, export.Todos
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	categoryIds := map[int]int{}
+	for _, category := range categories {
+		res, err := tx.Exec(sqlInsertCategory, category.Name, userId)
+		if err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		categoryIds[category.ID] = int(id)
+	}
+	for _, workflow := range workflows {
+		// workflows of a category missing from the export are dropped
+		if workflow.Category != 0 {
+			category, found := categoryIds[workflow.Category]
+			if !found {
+				continue
+			}
+			workflow.Category = category
+		}
+		definition, err := workflowDefinition(&workflow)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(sqlSetWorkflow, userId, workflow.Category, definition); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	tagIds := map[int]int{}
+	for _, tag := range tags {
+		res, err := tx.Exec(sqlInsertTag, tag.Name, tag.Color, userId)
+		if err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		tagIds[tag.ID] = int(id)
+	}
+	todoIds := map[int]int{}
+	for _, todo := range todos {
+		// todos pointing at a category missing from the export lose the category
+		category := categoryIds[todo.Category]
+		createdAt := todo.CreatedAt
+		if createdAt == 0 {
+			createdAt = time.Now().Unix()
+		}
+		// assignees are users of the exporting server, imported todos belong to nobody
+		res, err := tx.Exec(sqlInsertTodo, todo.Title, todo.Description, dueAt(todo.DueDate), todo.AllDay, todo.Priority, todo.Status, todo.Completed, userId, nil, category, nil, todo.AutoComplete, todo.Recurrence, todo.RecurrenceAnchor, nil, occurrence(todo.Occurrence), createdAt)
+		if err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		todoIds[todo.ID] = int(id)
+		// tags missing from the export are dropped
+		for _, tag := range todo.Tags {
+			if tagId, found := tagIds[tag]; found {
+				if _, err := tx.Exec(sqlInsertTodoTag, id, tagId); err != nil {
+					fmt.Println(err)
+					tx.Rollback()
+					return err
+				}
+			}
+		}
+	}
+	// parents and the first todo of a series can come later in the export, so they are linked once every todo exists
+	for _, todo := range todos {
+		if todo.ParentId != nil {
+			if parent, found := todoIds[*todo.ParentId]; found {
+				if _, err := tx.Exec(sqlSetTodoParent, parent, todoIds[todo.ID]); err != nil {
+					fmt.Println(err)
+					tx.Rollback()
+					return err
+				}
+			}
+		}
+		if todo.SeriesId != nil {
+			if series, found := todoIds[*todo.SeriesId]; found {
+				if _, err := tx.Exec(sqlSetTodoSeries, series, todoIds[todo.ID]); err != nil {
+					fmt.Println(err)
+					tx.Rollback()
+					return err
+				}
+			}
+		}
+	}
+	for _, reminder := range export.Reminders {
+		// reminders of todos missing from the export are dropped
+		todo, found := todoIds[reminder.TodoId]
+		if !found {
+			continue
+		}
+		var remindAt interface{}
+		if reminder.RemindAt != nil {
+			remindAt = reminder.RemindAt.Unix()
+		}
+		if _, err := tx.Exec(sqlImportReminder, todo, userId, remindAt, reminder.OffsetMinutes, reminder.Status, reminder.SentAt, reminder.LastError, reminder.CreatedAt); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, dependency := range export.Dependencies {
+		// dependencies on todos missing from the export are dropped
+		todo, found := todoIds[dependency.TodoId]
+		blocker, blockerFound := todoIds[dependency.BlockerId]
+		if !found || !blockerFound {
+			continue
+		}
+		if _, err := tx.Exec(sqlInsertTodoDependency, todo, blocker, userId, dependency.CreatedAt); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, comment := range export.Comments {
+		// comments on todos missing from the export are dropped
+		todo, found := todoIds[comment.TodoId]
+		if !found {
+			continue
+		}
+		if _, err := tx.Exec(sqlImportComment, todo, userId, comment.Body, comment.CreatedAt, comment.UpdatedAt); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, list := range export.SmartLists {
+		if _, err := tx.Exec(sqlInsertSmartList, list.Name, list.Query, userId); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
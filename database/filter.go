@@ -0,0 +1,103 @@
+package database
+
+import (
+	"strings"
+
+	"todo/filter"
+)
+
+// compileFilter turns a resolved filter expression into a condition on the todo table with its parameters.
+// Every condition is coalesced to 0 or 1, so negating a condition on a missing value, like a todo
+// without a due date, matches instead of leaving the todo out.
+func compileFilter(expr filter.Expr, userId int) (string, []interface{}) {
+	switch expr := expr.(type) {
+	case filter.And:
+		return compileFilters(expr.Exprs, " AND ", userId)
+	case filter.Or:
+		return compileFilters(expr.Exprs, " OR ", userId)
+	case filter.Not:
+		where, args := compileFilter(expr.Expr, userId)
+		return "NOT " + where, args
+	case *filter.Condition:
+		where, args := compileCondition(expr, userId)
+		return "COALESCE(" + where + ", 0)", args
+	}
+	return "1", nil
+}
+
+func compileFilters(exprs []filter.Expr, operator string, userId int) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	for _, expr := range exprs {
+		where, exprArgs := compileFilter(expr, userId)
+		parts = append(parts, where)
+		args = append(args, exprArgs...)
+	}
+	return "(" + strings.Join(parts, operator) + ")", args
+}
+
+// likeContains matches the value anywhere in a LIKE pattern, with its wildcards taken literally
+func likeContains(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + value + "%"
+}
+
+func compileCondition(c *filter.Condition, userId int) (string, []interface{}) {
+	switch c.Field {
+	case filter.FieldText:
+		return `(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, []interface{}{likeContains(c.Value), likeContains(c.Value)}
+	case filter.FieldTitle:
+		return `title LIKE ? ESCAPE '\'`, []interface{}{likeContains(c.Value)}
+	case filter.FieldDescription:
+		return `description LIKE ? ESCAPE '\'`, []interface{}{likeContains(c.Value)}
+	case filter.FieldPriority:
+		return "priority = ? COLLATE NOCASE", []interface{}{c.Value}
+	case filter.FieldStatus:
+		return "status = ? COLLATE NOCASE", []interface{}{c.Value}
+	case filter.FieldCategory:
+		return "category IN (SELECT category_id FROM category WHERE category_name = ? COLLATE NOCASE AND category_id IN (" + sqlAccessibleCategories + "))",
+			[]interface{}{c.Value, userId, userId}
+	case filter.FieldTag:
+		return `todo_id IN (
+			SELECT todo_tag.todo_id FROM todo_tag JOIN tag ON tag.tag_id = todo_tag.tag_id
+			WHERE tag.user_id = ? AND tag.name = ? COLLATE NOCASE)`, []interface{}{userId, c.Value}
+	case filter.FieldCompleted:
+		return "completed = ?", []interface{}{c.Flag}
+	case filter.FieldAllDay:
+		return "all_day = ?", []interface{}{c.Flag}
+	case filter.FieldRecurring:
+		return "(recurrence != '') = ?", []interface{}{c.Flag}
+	case filter.FieldOverdue:
+		return "(completed = 0 AND " + sqlTodosDueBefore + ") = ?", []interface{}{c.Start.At.Unix(), c.Start.Date.Unix(), c.Flag}
+	case filter.FieldDue:
+		if c.Day == nil {
+			return "due_at IS NULL", nil
+		}
+		start := []interface{}{c.Start.At.Unix(), c.Start.Date.Unix()}
+		end := []interface{}{c.End.At.Unix(), c.End.Date.Unix()}
+		switch c.Op {
+		case filter.OpBefore:
+			return sqlTodosDueBefore, start
+		case filter.OpBeforeOrOn:
+			return sqlTodosDueBefore, end
+		case filter.OpAfter:
+			return sqlTodosDueAfter, end
+		case filter.OpOnOrAfter:
+			return sqlTodosDueAfter, start
+		}
+		return "(" + sqlTodosDueAfter + " AND " + sqlTodosDueBefore + ")", append(start, end...)
+	case filter.FieldCreated:
+		switch c.Op {
+		case filter.OpBefore:
+			return "created_at < ?", []interface{}{c.Start.At.Unix()}
+		case filter.OpBeforeOrOn:
+			return "created_at < ?", []interface{}{c.End.At.Unix()}
+		case filter.OpAfter:
+			return "created_at >= ?", []interface{}{c.End.At.Unix()}
+		case filter.OpOnOrAfter:
+			return "created_at >= ?", []interface{}{c.Start.At.Unix()}
+		}
+		return "(created_at >= ? AND created_at < ?)", []interface{}{c.Start.At.Unix(), c.End.At.Unix()}
+	}
+	return "0", nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"todo/filter"
+	"todo/model"
+)
+
+// newTestDatabase migrates a fresh in-memory database, one connection keeps every query on the same database
+func newTestDatabase(t *testing.T) todoDatabase {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return todoDatabase{db: db}
+}
+
+func parseFilter(t *testing.T, input string, now time.Time) filter.Expr {
+	expr, err := filter.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", input, err)
+	}
+	filter.Resolve(expr, now, time.UTC)
+	return expr
+}
+
+// TestCompileFilterParameters makes sure values typed by the user only ever reach SQL as parameters
+func TestCompileFilterParameters(t *testing.T) {
+	hostile := `x' OR 1=1; DROP TABLE todo; --`
+	quoted := `"` + hostile + `"`
+	inputs := []string{
+		quoted,
+		"title:" + quoted,
+		"description:" + quoted,
+		"priority:" + quoted,
+		"status:" + quoted,
+		"category:" + quoted,
+		"tag:" + quoted,
+		"-(tag:" + quoted + " OR " + quoted + ")",
+	}
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	for _, input := range inputs {
+		where, args := compileFilter(parseFilter(t, input, now), 1)
+		for _, fragment := range []string{"1=1", "DROP", "--", "x'"} {
+			if strings.Contains(where, fragment) {
+				t.Errorf("%s compiled to SQL holding %q: %s", input, fragment, where)
+			}
+		}
+		if strings.Count(where, "?") != len(args) {
+			t.Errorf("%s compiled to %d placeholders for %d parameters", input, strings.Count(where, "?"), len(args))
+		}
+		found := false
+		for _, arg := range args {
+			if value, ok := arg.(string); ok && strings.Contains(value, hostile) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: the value is not among the parameters %v", input, args)
+		}
+	}
+}
+
+func TestLikeContains(t *testing.T) {
+	if got := likeContains(`50%_off\`); got != `%50\%\_off\\%` {
+		t.Fatalf("likeContains = %q", got)
+	}
+}
+
+// TestFilterTodos runs compiled filters against the todo table
+func TestFilterTodos(t *testing.T) {
+	database := newTestDatabase(t)
+	user := model.User{Name: "a", Email: "a@example.com", Password: "x", Role: "user", TimeZone: "UTC"}
+	if err := database.CreateUser(&user); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	todos := []model.Todo{
+		{Title: "buy milk", Priority: "high", DueDate: model.DueDate{Time: now.Add(2 * time.Hour)}},
+		{Title: "call bob", Priority: "low"},
+		{Title: "file taxes", Description: "100% done", Priority: "High", DueDate: model.DueDate{Time: now.AddDate(0, 0, -1)}, Completed: true},
+		{Title: "water plants", DueDate: model.DueDate{Time: time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC), DateOnly: true}, AllDay: true},
+	}
+	for i := range todos {
+		todos[i].UserId = user.ID
+		todos[i].Status = "todo"
+		todos[i].CreatedAt = now.Unix()
+		if err := database.AddTodo(&todos[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"milk", []string{"buy milk"}},
+		{"priority:HIGH", []string{"buy milk", "file taxes"}},
+		{"due:today", []string{"buy milk", "water plants"}},
+		// a todo without a due date does not match due:today, so it matches the negation
+		{"-due:today", []string{"call bob", "file taxes"}},
+		{"due:none", []string{"call bob"}},
+		{"-due:none", []string{"buy milk", "file taxes", "water plants"}},
+		{"due:<today", []string{"file taxes"}},
+		{"-due:<today", []string{"buy milk", "call bob", "water plants"}},
+		{"overdue", nil},
+		{"-completed", []string{"buy milk", "call bob", "water plants"}},
+		{"allday OR priority:low", []string{"call bob", "water plants"}},
+		// AND binds tighter than OR, parentheses change that
+		{"priority:high -completed OR allday", []string{"buy milk", "water plants"}},
+		{"priority:high (-completed OR allday)", []string{"buy milk"}},
+		{"-(priority:high OR allday)", []string{"call bob"}},
+		// LIKE wildcards in the value are taken literally
+		{`"100%"`, []string{"file taxes"}},
+		{`"1_0"`, nil},
+	}
+	for _, test := range tests {
+		page, err := database.ListTodos(user.ID, model.TodoQuery{Limit: 10}, nil, nil, parseFilter(t, test.input, now))
+		if err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		var got []string
+		for _, todo := range *page.Todos {
+			got = append(got, todo.Title)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("%s = %v, want %v", test.input, got, test.want)
+		}
+		if page.Total != len(test.want) {
+			t.Errorf("%s counted %d todos, want %d", test.input, page.Total, len(test.want))
+		}
+	}
+}
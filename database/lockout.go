@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"todo/lockout"
+)
+
+const (
+	sqlCreateLoginAttempt = `
+    CREATE TABLE IF NOT EXISTS login_attempt(
+        attempt_key VARCHAR NOT NULL PRIMARY KEY,
+		failures INTEGER DEFAULT 0,
+		last_failure INTEGER DEFAULT 0,
+		locked_until INTEGER DEFAULT 0
+    );
+    `
+	sqlGetLoginAttempt = `
+	SELECT failures,last_failure,locked_until FROM login_attempt
+		WHERE attempt_key = ?
+	`
+	// sqlAttemptFailures counts the failure being recorded, failures are forgotten
+	// once the key has been quiet for a lockout period
+	sqlAttemptFailures = `(CASE WHEN :now - login_attempt.last_failure > :lockout THEN 1 ELSE login_attempt.failures + 1 END)`
+	// sqlIncrementLoginAttempt does what lockout.Policy does on a failure in one statement,
+	// reaching the threshold locks the key and starts counting again
+	sqlIncrementLoginAttempt = `
+	INSERT INTO login_attempt
+		(attempt_key,failures,last_failure,locked_until)
+		VALUES (:key, CASE WHEN 1 >= :threshold THEN 0 ELSE 1 END, :now, CASE WHEN 1 >= :threshold THEN :now + :lockout ELSE 0 END)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+		failures = CASE WHEN ` + sqlAttemptFailures + ` >= :threshold THEN 0 ELSE ` + sqlAttemptFailures + ` END,
+		locked_until = CASE WHEN ` + sqlAttemptFailures + ` >= :threshold THEN :now + :lockout ELSE login_attempt.locked_until END,
+		last_failure = :now
+	`
+	sqlDeleteLoginAttempt = `
+	DELETE FROM login_attempt
+		WHERE attempt_key = ?
+	`
+)
+
+type lockoutStore struct {
+	db *sql.DB
+}
+
+//NewLockoutStore creates a lockout store which keeps failed attempts in the sqlite database
+func NewLockoutStore(db *sql.DB) lockout.Store {
+	return lockoutStore{db: db}
+}
+
+func (l lockoutStore) Get(key string) (lockout.State, error) {
+	var failures int
+	var lastFailure, lockedUntil int64
+	err := l.db.QueryRow(sqlGetLoginAttempt, key).Scan(&failures, &lastFailure, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return lockout.State{}, nil
+	}
+	if err != nil {
+		return lockout.State{}, err
+	}
+	return lockout.State{
+		Failures:    failures,
+		LastFailure: fromUnixNano(lastFailure),
+		LockedUntil: fromUnixNano(lockedUntil),
+	}, nil
+}
+
+// Increment records the failure and reads the new state back in one transaction,
+// the write lock it takes keeps other failures from landing in between
+func (l lockoutStore) Increment(key string, now time.Time, policy lockout.Policy) (lockout.State, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return lockout.State{}, err
+	}
+	_, err = tx.Exec(sqlIncrementLoginAttempt,
+		sql.Named("key", key),
+		sql.Named("now", toUnixNano(now)),
+		sql.Named("lockout", int64(policy.LockoutDuration)),
+		sql.Named("threshold", policy.Threshold))
+	if err != nil {
+		tx.Rollback()
+		return lockout.State{}, err
+	}
+	var failures int
+	var lastFailure, lockedUntil int64
+	if err := tx.QueryRow(sqlGetLoginAttempt, key).Scan(&failures, &lastFailure, &lockedUntil); err != nil {
+		tx.Rollback()
+		return lockout.State{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return lockout.State{}, err
+	}
+	return lockout.State{
+		Failures:    failures,
+		LastFailure: fromUnixNano(lastFailure),
+		LockedUntil: fromUnixNano(lockedUntil),
+	}, nil
+}
+
+func (l lockoutStore) Reset(key string) error {
+	_, err := l.db.Exec(sqlDeleteLoginAttempt, key)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// the zero time is stored as 0, it has no unix nano representation
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
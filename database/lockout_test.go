@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"todo/lockout"
+)
+
+// TestLockoutStoreIncrement runs the failures of lockout.Policy through the sql statement
+func TestLockoutStoreIncrement(t *testing.T) {
+	store := NewLockoutStore(newTestDatabase(t).db)
+	policy := lockout.Policy{Threshold: 3, LockoutDuration: time.Hour}
+	start := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at          time.Time
+		failures    int
+		lockedUntil time.Time
+	}{
+		{start, 1, time.Time{}},
+		{start.Add(time.Minute), 2, time.Time{}},
+		// the threshold locks the key and starts counting again
+		{start.Add(2 * time.Minute), 0, start.Add(62 * time.Minute)},
+		{start.Add(3 * time.Minute), 1, start.Add(62 * time.Minute)},
+		// a key quiet for a lockout period starts over
+		{start.Add(2 * time.Hour), 1, start.Add(62 * time.Minute)},
+	}
+	for i, test := range tests {
+		state, err := store.Increment("k", test.at, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.Failures != test.failures || !state.LastFailure.Equal(test.at) || !state.LockedUntil.Equal(test.lockedUntil) {
+			t.Errorf("failure %d: state = %+v, want %d failures locked until %v", i+1, state, test.failures, test.lockedUntil)
+		}
+	}
+	if err := store.Reset("k"); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := store.Get("k"); err != nil || state != (lockout.State{}) {
+		t.Fatalf("state after reset = %+v, %v", state, err)
+	}
+}
+
+// TestLockoutStoreParallelFailures makes sure failures from several connections all count
+func TestLockoutStoreParallelFailures(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "lockout.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqlCreateLoginAttempt); err != nil {
+		t.Fatal(err)
+	}
+	store := NewLockoutStore(db)
+	policy := lockout.Policy{Threshold: 1000, LockoutDuration: time.Hour}
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.Increment("k", now, policy); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	state, err := store.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Failures != 50 {
+		t.Fatalf("recorded %d of 50 parallel failures", state.Failures)
+	}
+}
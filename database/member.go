@@ -0,0 +1,194 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateCategoryMember = `
+    CREATE TABLE IF NOT EXISTS category_member(
+        category_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		role VARCHAR NOT NULL,
+		invited_by INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (category_id, user_id),
+		FOREIGN KEY (category_id) REFERENCES category (category_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	// sqlAccessibleCategories are the categories of the user and the ones shared with it, trashed ones
+	// are left out, it takes the user id twice
+	sqlAccessibleCategories = `
+	SELECT category_id FROM category WHERE user_id = ? AND deleted_at IS NULL
+		UNION
+		SELECT category_member.category_id FROM category_member JOIN category ON category.category_id = category_member.category_id
+		WHERE category_member.user_id = ? AND category.deleted_at IS NULL`
+	// sqlVisibleTodos limits todos to the ones in categories the user can access and its own ones outside the
+	// categories of other users, trashed todos are left out, it takes the user id three times
+	sqlVisibleTodos = `(todo.deleted_at IS NULL AND ((todo.user_id = ? AND NOT EXISTS (` + sqlForeignCategory + `))
+		OR todo.category IN (` + sqlAccessibleCategories + `)))`
+	// sqlForeignCategory finds the category of the todo when it belongs to another user than the todo, trashed or not
+	sqlForeignCategory      = `SELECT 1 FROM category WHERE category.category_id = todo.category AND category.user_id != todo.user_id`
+	sqlInsertCategoryMember = `
+	INSERT INTO category_member
+		(category_id,user_id,role,invited_by,created_at)
+		VALUES (?,?,?,?,?);
+	`
+	sqlGetCategoryMembers = `
+	SELECT category_member.category_id,category_member.user_id,user.name,user.email,category_member.role,
+		category_member.invited_by,category_member.created_at
+		FROM category_member JOIN user ON user.user_id = category_member.user_id
+		WHERE category_member.category_id = ?
+		ORDER BY category_member.created_at, category_member.user_id
+	`
+	sqlGetCategoryMember = `
+	SELECT category_member.category_id,category_member.user_id,user.name,user.email,category_member.role,
+		category_member.invited_by,category_member.created_at
+		FROM category_member JOIN user ON user.user_id = category_member.user_id
+		WHERE category_member.category_id = ? AND category_member.user_id = ?
+	`
+	sqlUpdateCategoryMember = `
+	UPDATE category_member
+		SET role = ?
+		WHERE category_id = ? AND user_id = ?
+	`
+	sqlDeleteCategoryMember = `
+	DELETE FROM category_member
+		WHERE category_id = ? AND user_id = ?
+	`
+	sqlDeleteCategoryMembers = `
+	DELETE FROM category_member
+		WHERE category_id = ?
+	`
+	sqlGetSharedCategories = `
+	SELECT category.category_id,category.category_name,category.user_id,category_member.role
+		FROM category JOIN category_member ON category_member.category_id = category.category_id
+		WHERE category_member.user_id = ? AND category.deleted_at IS NULL
+		ORDER BY category.category_id
+	`
+	sqlGetVisibleTodos = `
+	SELECT ` + todoColumns + ` FROM todo
+		WHERE ` + sqlVisibleTodos + `
+	`
+)
+
+func scanCategoryMember(row scanner) (*model.CategoryMember, error) {
+	var member model.CategoryMember
+	err := row.Scan(&member.CategoryId, &member.UserId, &member.Name, &member.Email, &member.Role, &member.InvitedBy, &member.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (t todoDatabase) AddCategoryMember(member *model.CategoryMember) error {
+	_, err := t.db.Exec(sqlInsertCategoryMember, member.CategoryId, member.UserId, member.Role, member.InvitedBy, member.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) GetCategoryMembers(categoryId int) (*[]model.CategoryMember, error) {
+	members := []model.CategoryMember{}
+	rows, err := t.db.Query(sqlGetCategoryMembers, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		member, err := scanCategoryMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *member)
+	}
+	return &members, rows.Err()
+}
+
+func (t todoDatabase) GetCategoryMember(categoryId, userId int) (*model.CategoryMember, error) {
+	return scanCategoryMember(t.db.QueryRow(sqlGetCategoryMember, categoryId, userId))
+}
+
+func (t todoDatabase) UpdateCategoryMember(categoryId, userId int, role string) error {
+	_, err := t.db.Exec(sqlUpdateCategoryMember, role, categoryId, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteCategoryMember removes the member, the todos of others in the category assigned to it go to nobody
+func (t todoDatabase) DeleteCategoryMember(categoryId, userId int) (int64, error) {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	if _, err = tx.Exec(sqlUnassignMember, categoryId, userId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	res, err := tx.Exec(sqlDeleteCategoryMember, categoryId, userId)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// DeleteCategoryMembers removes all members of the category, like DeleteCategoryMember does for one
+func (t todoDatabase) DeleteCategoryMembers(categoryId int) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlUnassignMembers, categoryId, categoryId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteCategoryMembers, categoryId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetSharedCategories returns the categories other users shared with the user, with the role of the user
+func (t todoDatabase) GetSharedCategories(userId int) (*[]model.Category, error) {
+	categories := []model.Category{}
+	rows, err := t.db.Query(sqlGetSharedCategories, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var category model.Category
+		if err := rows.Scan(&category.ID, &category.Name, &category.UserId, &category.Role); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return &categories, rows.Err()
+}
+
+// GetVisibleTodos returns the todos of the user and the ones in categories shared with it
+func (t todoDatabase) GetVisibleTodos(userId int) (*[]model.Todo, error) {
+	rows, err := t.db.Query(sqlGetVisibleTodos, userId, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanTodos(rows)
+}
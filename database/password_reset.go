@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreatePasswordReset = `
+    CREATE TABLE IF NOT EXISTS password_reset(
+        reset_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL,
+		token_hash VARCHAR NOT NULL UNIQUE,
+		expires_at INTEGER NOT NULL,
+		used INTEGER DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertPasswordReset = `
+	INSERT INTO password_reset
+		(user_id,token_hash,expires_at,used,created_at)
+		VALUES (?,?,?,0,?);
+	`
+	sqlGetPasswordResetByToken = `
+	SELECT reset_id,user_id,token_hash,expires_at,used,created_at FROM password_reset
+		WHERE token_hash = ?
+	`
+	sqlUsePasswordReset = `
+	UPDATE password_reset
+		SET used = 1
+		WHERE reset_id = ? AND used = 0 AND expires_at > ?
+	`
+	sqlInvalidateUserPasswordResets = `
+	UPDATE password_reset
+		SET used = 1
+		WHERE user_id = ?
+	`
+)
+
+func (t todoDatabase) CreatePasswordReset(r *model.PasswordReset) error {
+	res, err := t.db.Exec(sqlInsertPasswordReset, r.UserId, r.TokenHash, r.ExpiresAt, r.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	r.ID = int(id)
+	return nil
+}
+
+func (t todoDatabase) GetPasswordResetByToken(hash string) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+	err := t.db.QueryRow(sqlGetPasswordResetByToken, hash).Scan(&reset.ID, &reset.UserId, &reset.TokenHash, &reset.ExpiresAt, &reset.Used, &reset.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// UsePasswordReset marks the reset token as used, it affects no row if the token was already used or has expired
+func (t todoDatabase) UsePasswordReset(id int, now int64) (int64, error) {
+	res, err := t.db.Exec(sqlUsePasswordReset, id, now)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) InvalidateUserPasswordResets(userId int) error {
+	_, err := t.db.Exec(sqlInvalidateUserPasswordResets, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
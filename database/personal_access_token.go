@@ -0,0 +1,124 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"todo/model"
+)
+
+const (
+	sqlCreatePersonalAccessToken = `
+    CREATE TABLE IF NOT EXISTS personal_access_token(
+        pat_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL,
+		name VARCHAR NOT NULL,
+		token_hash VARCHAR NOT NULL UNIQUE,
+		scopes VARCHAR NOT NULL,
+		expires_at INTEGER DEFAULT 0,
+		last_used_at INTEGER DEFAULT 0,
+		revoked INTEGER DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertPersonalAccessToken = `
+	INSERT INTO personal_access_token
+		(user_id,name,token_hash,scopes,expires_at,created_at)
+		VALUES (?,?,?,?,?,?);
+	`
+	sqlGetPersonalAccessTokens = `
+	SELECT pat_id,user_id,name,token_hash,scopes,expires_at,last_used_at,revoked,created_at FROM personal_access_token
+		WHERE user_id = ?
+	`
+	sqlGetPersonalAccessTokenByHash = `
+	SELECT pat_id,user_id,name,token_hash,scopes,expires_at,last_used_at,revoked,created_at FROM personal_access_token
+		WHERE token_hash = ?
+	`
+	sqlRevokePersonalAccessToken = `
+	UPDATE personal_access_token
+		SET revoked = 1
+		WHERE user_id = ? AND pat_id = ? AND revoked = 0
+	`
+	sqlRevokeUserPersonalAccessTokens = `
+	UPDATE personal_access_token
+		SET revoked = 1
+		WHERE user_id = ?
+	`
+	sqlTouchPersonalAccessToken = `
+	UPDATE personal_access_token
+		SET last_used_at = ?
+		WHERE pat_id = ?
+	`
+)
+
+func scanPersonalAccessToken(row scanner) (*model.PersonalAccessToken, error) {
+	var pat model.PersonalAccessToken
+	var scopes string
+	err := row.Scan(&pat.ID, &pat.UserId, &pat.Name, &pat.TokenHash, &scopes, &pat.ExpiresAt, &pat.LastUsedAt, &pat.Revoked, &pat.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	pat.Scopes = strings.Fields(scopes)
+	return &pat, nil
+}
+
+func (t todoDatabase) CreatePersonalAccessToken(pat *model.PersonalAccessToken) error {
+	res, err := t.db.Exec(sqlInsertPersonalAccessToken, pat.UserId, pat.Name, pat.TokenHash, strings.Join(pat.Scopes, " "), pat.ExpiresAt, pat.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	pat.ID = int(id)
+	return nil
+}
+
+func (t todoDatabase) GetPersonalAccessTokens(userId int) (*[]model.PersonalAccessToken, error) {
+	patList := []model.PersonalAccessToken{}
+	rows, err := t.db.Query(sqlGetPersonalAccessTokens, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		pat, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		patList = append(patList, *pat)
+	}
+	return &patList, nil
+}
+
+func (t todoDatabase) GetPersonalAccessTokenByHash(hash string) (*model.PersonalAccessToken, error) {
+	return scanPersonalAccessToken(t.db.QueryRow(sqlGetPersonalAccessTokenByHash, hash))
+}
+
+func (t todoDatabase) RevokePersonalAccessToken(userId, id int) (int64, error) {
+	res, err := t.db.Exec(sqlRevokePersonalAccessToken, userId, id)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) RevokeUserPersonalAccessTokens(userId int) error {
+	_, err := t.db.Exec(sqlRevokeUserPersonalAccessTokens, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) TouchPersonalAccessToken(id int, now int64) error {
+	_, err := t.db.Exec(sqlTouchPersonalAccessToken, now, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"time"
+
+	"todo/model"
+)
+
+const (
+	sqlGetSeriesOccurrence = `
+	SELECT ` + todoColumns + ` FROM todo
+		WHERE (todo_id = ? OR series_id = ?) AND occurrence = ?
+	`
+	sqlUpdateTodoOccurrence = `
+	UPDATE todo
+		SET due_at = ?,
+		occurrence = ?
+		WHERE todo_id = ?
+	`
+	sqlEndSeries = `
+	UPDATE todo
+		SET recurrence = ''
+		WHERE todo_id = ? OR series_id = ?
+	`
+)
+
+// GetSeriesOccurrence returns the given occurrence of a series, sql.ErrNoRows if it was not created yet.
+// Trashed occurrences count too, so completing a todo again does not bring back one the user deleted.
+func (t todoDatabase) GetSeriesOccurrence(seriesId, occurrence int) (*model.Todo, error) {
+	return scanTodo(t.db.QueryRow(sqlGetSeriesOccurrence, seriesId, seriesId, occurrence))
+}
+
+func (t todoDatabase) UpdateTodoOccurrence(id int, dueDate time.Time, occurrence int) error {
+	_, err := t.db.Exec(sqlUpdateTodoOccurrence, dueDate.Unix(), occurrence, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// EndSeries stops every todo of the series from recurring, the todos themselves stay
+func (t todoDatabase) EndSeries(seriesId int) error {
+	_, err := t.db.Exec(sqlEndSeries, seriesId, seriesId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+	"todo/notifier"
+	"todo/scheduler"
+)
+
+const (
+	sqlCreateReminder = `
+    CREATE TABLE IF NOT EXISTS reminder(
+        reminder_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        todo_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		remind_at INTEGER,
+		offset_minutes INTEGER,
+		status VARCHAR NOT NULL DEFAULT 'pending',
+		sent_at INTEGER DEFAULT 0,
+		last_error VARCHAR NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	// sqlReminderFireAt is when a reminder is due, offset reminders follow the due date of their todo
+	sqlReminderFireAt = `COALESCE(reminder.remind_at, todo.due_at - reminder.offset_minutes * 60)`
+	sqlInsertReminder = `
+	INSERT INTO reminder
+		(todo_id,user_id,remind_at,offset_minutes,status,created_at)
+		VALUES (?,?,?,?,?,?);
+	`
+	sqlGetReminders = `
+	SELECT reminder.reminder_id,reminder.todo_id,reminder.user_id,reminder.remind_at,reminder.offset_minutes,
+		` + sqlReminderFireAt + `,reminder.status,reminder.sent_at,reminder.last_error,reminder.created_at
+		FROM reminder JOIN todo ON todo.todo_id = reminder.todo_id
+		WHERE reminder.todo_id = ? AND reminder.user_id = ? AND todo.deleted_at IS NULL
+		ORDER BY reminder.reminder_id
+	`
+	// sqlGetUserReminders lists the reminders the user set on its own todos, for the export
+	sqlGetUserReminders = `
+	SELECT reminder.reminder_id,reminder.todo_id,reminder.user_id,reminder.remind_at,reminder.offset_minutes,
+		` + sqlReminderFireAt + `,reminder.status,reminder.sent_at,reminder.last_error,reminder.created_at
+		FROM reminder JOIN todo ON todo.todo_id = reminder.todo_id
+		WHERE reminder.user_id = ? AND todo.user_id = ? AND todo.deleted_at IS NULL
+		ORDER BY reminder.reminder_id
+	`
+	sqlGetReminderById = `
+	SELECT reminder.reminder_id,reminder.todo_id,reminder.user_id,reminder.remind_at,reminder.offset_minutes,
+		` + sqlReminderFireAt + `,reminder.status,reminder.sent_at,reminder.last_error,reminder.created_at
+		FROM reminder JOIN todo ON todo.todo_id = reminder.todo_id
+		WHERE reminder.user_id = ? AND reminder.reminder_id = ? AND todo.deleted_at IS NULL
+	`
+	sqlDeleteReminder = `
+	DELETE FROM reminder
+		WHERE reminder_id = ?
+	`
+	sqlDeleteSubtreeReminders = `
+	DELETE FROM reminder
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+	// offset reminders carry over to the next occurrence of a recurring todo, fixed times do not
+	sqlCopyOffsetReminders = `
+	INSERT INTO reminder
+		(todo_id,user_id,offset_minutes,status,created_at)
+		SELECT ?,user_id,offset_minutes,?,? FROM reminder
+		WHERE todo_id = ? AND offset_minutes IS NOT NULL
+	`
+	sqlGetDueReminders = `
+	SELECT reminder.reminder_id,todo.todo_id,todo.title,todo.due_at,todo.all_day,user.user_id,user.name,user.email,user.time_zone,
+		` + sqlReminderFireAt + ` AS fire_at
+		FROM reminder
+		JOIN todo ON todo.todo_id = reminder.todo_id
+		JOIN user ON user.user_id = reminder.user_id
+		WHERE reminder.status = ? AND todo.completed = 0 AND todo.deleted_at IS NULL AND fire_at <= ?
+		ORDER BY fire_at
+		LIMIT ?
+	`
+	sqlClaimReminder = `
+	UPDATE reminder
+		SET status = ?
+		WHERE reminder_id = ? AND status = ?
+	`
+	sqlFinishReminder = `
+	UPDATE reminder
+		SET status = ?,
+		sent_at = ?,
+		last_error = ?
+		WHERE reminder_id = ?
+	`
+	sqlAbandonClaimedReminders = `
+	UPDATE reminder
+		SET status = ?,
+		sent_at = ?,
+		last_error = 'interrupted by a restart'
+		WHERE status = ?
+	`
+)
+
+func scanReminder(row scanner) (*model.Reminder, error) {
+	var reminder model.Reminder
+	var remindAt, offset, fireAt sql.NullInt64
+	err := row.Scan(&reminder.ID, &reminder.TodoId, &reminder.UserId, &remindAt, &offset, &fireAt, &reminder.Status, &reminder.SentAt, &reminder.LastError, &reminder.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if remindAt.Valid {
+		at := time.Unix(remindAt.Int64, 0).UTC()
+		reminder.RemindAt = &at
+	}
+	if offset.Valid {
+		minutes := int(offset.Int64)
+		reminder.OffsetMinutes = &minutes
+	}
+	if fireAt.Valid {
+		at := time.Unix(fireAt.Int64, 0).UTC()
+		reminder.FireAt = &at
+	}
+	return &reminder, nil
+}
+
+func (t todoDatabase) AddReminder(reminder *model.Reminder) error {
+	var remindAt interface{}
+	if reminder.RemindAt != nil {
+		remindAt = reminder.RemindAt.Unix()
+	}
+	res, err := t.db.Exec(sqlInsertReminder, reminder.TodoId, reminder.UserId, remindAt, reminder.OffsetMinutes, reminder.Status, reminder.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	reminder.ID = int(id)
+	return nil
+}
+
+// GetReminders returns the reminders the user set on the todo
+func (t todoDatabase) GetReminders(todoId, userId int) (*[]model.Reminder, error) {
+	rows, err := t.db.Query(sqlGetReminders, todoId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanReminders(rows)
+}
+
+// GetUserReminders returns the reminders the user set on its own todos
+func (t todoDatabase) GetUserReminders(userId int) (*[]model.Reminder, error) {
+	rows, err := t.db.Query(sqlGetUserReminders, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanReminders(rows)
+}
+
+func scanReminders(rows *sql.Rows) (*[]model.Reminder, error) {
+	reminders := []model.Reminder{}
+	defer rows.Close()
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+	return &reminders, rows.Err()
+}
+
+func (t todoDatabase) GetReminderById(userId, reminderId int) (*model.Reminder, error) {
+	return scanReminder(t.db.QueryRow(sqlGetReminderById, userId, reminderId))
+}
+
+func (t todoDatabase) DeleteReminder(reminderId int) error {
+	_, err := t.db.Exec(sqlDeleteReminder, reminderId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) CopyOffsetReminders(fromTodoId, toTodoId int) error {
+	_, err := t.db.Exec(sqlCopyOffsetReminders, toTodoId, constants.ReminderPending, time.Now().Unix(), fromTodoId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type reminderStore struct {
+	db *sql.DB
+}
+
+//NewReminderStore creates the scheduler store on the reminder table
+func NewReminderStore(db *sql.DB) scheduler.Store {
+	return reminderStore{db: db}
+}
+
+func (s reminderStore) ClaimDueReminders(now time.Time, limit int) ([]notifier.Notification, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(sqlGetDueReminders, constants.ReminderPending, now.Unix(), limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var notifications []notifier.Notification
+	for rows.Next() {
+		n := notifier.Notification{Kind: constants.NotificationReminder}
+		var dueAt sql.NullInt64
+		var allDay bool
+		var timeZone string
+		var fireAt int64
+		if err := rows.Scan(&n.ReminderId, &n.TodoId, &n.Title, &dueAt, &allDay, &n.UserId, &n.UserName, &n.UserEmail, &timeZone, &fireAt); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		n.FireAt = time.Unix(fireAt, 0).UTC()
+		if dueAt.Valid {
+			dueDate := model.DueDate{Time: time.Unix(dueAt.Int64, 0).UTC(), DateOnly: allDay}
+			if location, err := time.LoadLocation(timeZone); err == nil && !allDay {
+				dueDate.Time = dueDate.In(location)
+			}
+			n.DueDate = dueDate.String()
+		}
+		notifications = append(notifications, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, n := range notifications {
+		if _, err := tx.Exec(sqlClaimReminder, constants.ReminderSending, n.ReminderId, constants.ReminderPending); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return notifications, tx.Commit()
+}
+
+func (s reminderStore) FinishReminder(id int, now time.Time, deliveryError string) error {
+	status := constants.ReminderSent
+	if deliveryError != "" {
+		status = constants.ReminderFailed
+	}
+	_, err := s.db.Exec(sqlFinishReminder, status, now.Unix(), deliveryError, id)
+	return err
+}
+
+func (s reminderStore) AbandonClaimedReminders(now time.Time) (int, error) {
+	res, err := s.db.Exec(sqlAbandonClaimedReminders, constants.ReminderFailed, now.Unix(), constants.ReminderSending)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
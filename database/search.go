@@ -0,0 +1,180 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"todo/constants"
+	"todo/model"
+)
+
+const (
+	// todo_fts indexes the title and description of the todo table, it holds no copy of the text.
+	// The triggers keep it in step with every write to todo.
+	sqlCreateTodoFts = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS todo_fts USING fts5(
+		title,
+		description,
+		content='todo',
+		content_rowid='todo_id',
+		tokenize='unicode61 remove_diacritics 2'
+	);
+	`
+	sqlCreateTodoFtsInsert = `
+	CREATE TRIGGER IF NOT EXISTS todo_fts_insert AFTER INSERT ON todo BEGIN
+		INSERT INTO todo_fts(rowid, title, description) VALUES (new.todo_id, new.title, new.description);
+	END;
+	`
+	sqlCreateTodoFtsDelete = `
+	CREATE TRIGGER IF NOT EXISTS todo_fts_delete AFTER DELETE ON todo BEGIN
+		INSERT INTO todo_fts(todo_fts, rowid, title, description) VALUES ('delete', old.todo_id, old.title, old.description);
+	END;
+	`
+	sqlCreateTodoFtsUpdate = `
+	CREATE TRIGGER IF NOT EXISTS todo_fts_update AFTER UPDATE OF title, description ON todo BEGIN
+		INSERT INTO todo_fts(todo_fts, rowid, title, description) VALUES ('delete', old.todo_id, old.title, old.description);
+		INSERT INTO todo_fts(rowid, title, description) VALUES (new.todo_id, new.title, new.description);
+	END;
+	`
+	sqlFts5Enabled          = `SELECT sqlite_compileoption_used('ENABLE_FTS5')`
+	sqlCountTodoFtsTriggers = `
+	SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'trigger' AND name IN ('todo_fts_insert', 'todo_fts_delete', 'todo_fts_update')
+	`
+	sqlRebuildTodoFts = `INSERT INTO todo_fts(todo_fts) VALUES ('rebuild')`
+	// the title counts ten times as much as the description in the ranking, bm25 is negated so that
+	// better matches score higher
+	sqlSearchTodos = `
+	SELECT ` + todoColumns + `,matches.title_highlight,matches.snippet,matches.score FROM todo
+		JOIN (
+			SELECT rowid AS match_id,
+				highlight(todo_fts, 0, '` + constants.SearchHighlightStart + `', '` + constants.SearchHighlightEnd + `') AS title_highlight,
+				snippet(todo_fts, 1, '` + constants.SearchHighlightStart + `', '` + constants.SearchHighlightEnd + `', '…', 12) AS snippet,
+				-bm25(todo_fts, 10.0, 1.0) AS score
+			FROM todo_fts
+			WHERE todo_fts MATCH ?
+		) AS matches ON todo.todo_id = matches.match_id
+		WHERE ` + sqlVisibleTodos + `
+		ORDER BY matches.score DESC, todo.todo_id
+		LIMIT ?
+	`
+)
+
+var sqlDropTodoFtsTriggers = []string{
+	`DROP TRIGGER IF EXISTS todo_fts_insert`,
+	`DROP TRIGGER IF EXISTS todo_fts_delete`,
+	`DROP TRIGGER IF EXISTS todo_fts_update`,
+}
+
+// ErrSearchUnavailable is returned when sqlite was built without FTS5
+var ErrSearchUnavailable = errors.New("search is not available")
+
+// migrateSearch sets up the full-text index. Without FTS5 (build with -tags sqlite_fts5) search is
+// switched off and the triggers are dropped, so writes to todo keep working. The index is rebuilt
+// whenever the triggers were missing, as todos may have changed while nothing kept it in step.
+func migrateSearch(db *sql.DB) error {
+	var enabled bool
+	if err := db.QueryRow(sqlFts5Enabled).Scan(&enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		log.Println("full-text search is disabled, build with -tags sqlite_fts5 to enable it")
+		for _, query := range sqlDropTodoFtsTriggers {
+			if _, err := db.Exec(query); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var triggers int
+	if err := db.QueryRow(sqlCountTodoFtsTriggers).Scan(&triggers); err != nil {
+		return err
+	}
+	for _, query := range []string{sqlCreateTodoFts, sqlCreateTodoFtsInsert, sqlCreateTodoFtsDelete, sqlCreateTodoFtsUpdate} {
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
+	if triggers < 3 {
+		if _, err := db.Exec(sqlRebuildTodoFts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMissingFts5 tells whether sqlite lacks FTS5, the index is not created then
+func isMissingFts5(err error) bool {
+	return strings.Contains(err.Error(), "no such module: fts5") || strings.Contains(err.Error(), "no such table: todo_fts")
+}
+
+// ftsQuery turns the search input into an FTS5 query. All words have to match, "quoted words" match
+// as a phrase and a trailing * matches words starting with what comes before it. Everything else,
+// FTS5 operators included, is searched for literally. It returns "" when nothing is left to search.
+func ftsQuery(input string) string {
+	var terms []string
+	for {
+		input = strings.TrimLeftFunc(input, unicode.IsSpace)
+		if input == "" {
+			break
+		}
+		var term string
+		if input[0] == '"' {
+			end := strings.IndexByte(input[1:], '"')
+			if end < 0 {
+				term, input = input[1:], ""
+			} else {
+				term, input = input[1:end+1], input[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(input, unicode.IsSpace)
+			if end < 0 {
+				end = len(input)
+			}
+			term, input = input[:end], input[end:]
+		}
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimSpace(strings.TrimRight(term, "*"))
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) < 0 {
+			continue
+		}
+		term = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
+
+// SearchTodos returns the best matches for the search input among the todos of the user
+func (t todoDatabase) SearchTodos(userId int, input string, limit int) (*[]model.SearchResult, error) {
+	results := []model.SearchResult{}
+	query := ftsQuery(input)
+	if query == "" {
+		return &results, nil
+	}
+	rows, err := t.db.Query(sqlSearchTodos, query, userId, userId, userId, limit)
+	if err != nil && isMissingFts5(err) {
+		return nil, ErrSearchUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var result model.SearchResult
+		var snippet sql.NullString
+		todo, err := scanTodo(extraScanner{row: rows, extra: []interface{}{&result.TitleHighlight, &snippet, &result.Score}})
+		if err != nil {
+			return nil, err
+		}
+		result.Todo = *todo
+		result.Snippet = snippet.String
+		results = append(results, result)
+	}
+	return &results, rows.Err()
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateSession = `
+    CREATE TABLE IF NOT EXISTS session(
+        session_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL,
+		refresh_token_hash VARCHAR NOT NULL UNIQUE,
+		expires_at INTEGER NOT NULL,
+		revoked INTEGER DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertSession = `
+	INSERT INTO session
+		(user_id,refresh_token_hash,expires_at,revoked,created_at)
+		VALUES (?,?,?,0,?);
+	`
+	sqlGetSessionById = `
+	SELECT session_id,user_id,refresh_token_hash,expires_at,revoked,created_at FROM session
+		WHERE session_id = ?
+	`
+	sqlGetSessionByRefreshToken = `
+	SELECT session_id,user_id,refresh_token_hash,expires_at,revoked,created_at FROM session
+		WHERE refresh_token_hash = ?
+	`
+	// sqlRotateSession only swaps the refresh token it was given, of two rotations with the same token one wins
+	sqlRotateSession = `
+	UPDATE session
+		SET refresh_token_hash = ?,
+		expires_at = ?
+		WHERE session_id = ? AND refresh_token_hash = ? AND revoked = 0
+	`
+	sqlRevokeSession = `
+	UPDATE session
+		SET revoked = 1
+		WHERE session_id = ?
+	`
+	sqlRevokeUserSessions = `
+	UPDATE session
+		SET revoked = 1
+		WHERE user_id = ?
+	`
+)
+
+func (t todoDatabase) CreateSession(s *model.Session) error {
+	res, err := t.db.Exec(sqlInsertSession, s.UserId, s.RefreshTokenHash, s.ExpiresAt, s.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = int(id)
+	return nil
+}
+
+func (t todoDatabase) GetSessionById(id int) (*model.Session, error) {
+	var session model.Session
+	err := t.db.QueryRow(sqlGetSessionById, id).Scan(&session.ID, &session.UserId, &session.RefreshTokenHash, &session.ExpiresAt, &session.Revoked, &session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (t todoDatabase) GetSessionByRefreshToken(hash string) (*model.Session, error) {
+	var session model.Session
+	err := t.db.QueryRow(sqlGetSessionByRefreshToken, hash).Scan(&session.ID, &session.UserId, &session.RefreshTokenHash, &session.ExpiresAt, &session.Revoked, &session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateSession replaces the refresh token of the session when it still is oldHash
+func (t todoDatabase) RotateSession(id int, oldHash, hash string, expiresAt int64) (int64, error) {
+	res, err := t.db.Exec(sqlRotateSession, hash, expiresAt, id, oldHash)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) RevokeSession(id int) error {
+	_, err := t.db.Exec(sqlRevokeSession, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) RevokeUserSessions(userId int) error {
+	_, err := t.db.Exec(sqlRevokeUserSessions, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
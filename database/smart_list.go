@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateSmartList = `
+    CREATE TABLE IF NOT EXISTS smart_list(
+        list_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        name VARCHAR NOT NULL,
+		query VARCHAR NOT NULL,
+		user_id INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlInsertSmartList = `
+	INSERT INTO smart_list
+		(name,query,user_id)
+		VALUES (?,?,?);
+	`
+	sqlGetSmartLists = `
+	SELECT list_id,name,query,user_id FROM smart_list
+		WHERE user_id = ?
+		ORDER BY name COLLATE NOCASE
+	`
+	sqlGetSmartListById = `
+	SELECT list_id,name,query,user_id FROM smart_list
+		WHERE user_id = ? AND list_id = ?
+	`
+	sqlUpdateSmartList = `
+	UPDATE smart_list
+		SET name = ?,
+		query = ?
+		WHERE list_id = ?
+	`
+	sqlDeleteSmartList = `
+	DELETE FROM smart_list
+		WHERE list_id = ?
+	`
+)
+
+func scanSmartList(row scanner) (*model.SmartList, error) {
+	var list model.SmartList
+	err := row.Scan(&list.ID, &list.Name, &list.Query, &list.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (t todoDatabase) AddSmartList(list *model.SmartList) error {
+	res, err := t.db.Exec(sqlInsertSmartList, list.Name, list.Query, list.UserId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	list.ID = int(id)
+	return nil
+}
+
+func (t todoDatabase) GetSmartLists(userId int) (*[]model.SmartList, error) {
+	lists := []model.SmartList{}
+	rows, err := t.db.Query(sqlGetSmartLists, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		list, err := scanSmartList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *list)
+	}
+	return &lists, rows.Err()
+}
+
+func (t todoDatabase) GetSmartListById(userId, listId int) (*model.SmartList, error) {
+	return scanSmartList(t.db.QueryRow(sqlGetSmartListById, userId, listId))
+}
+
+func (t todoDatabase) UpdateSmartList(list *model.SmartList) error {
+	_, err := t.db.Exec(sqlUpdateSmartList, list.Name, list.Query, list.ID)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) DeleteSmartList(listId int) error {
+	_, err := t.db.Exec(sqlDeleteSmartList, listId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"todo/model"
+)
+
+// sqlTodoSubtree selects the id of a todo and of every todo below it
+const sqlTodoSubtree = `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT ?
+		UNION ALL
+		SELECT todo.todo_id FROM todo JOIN subtree ON todo.parent_id = subtree.id
+	)
+	SELECT id FROM subtree`
+
+const (
+	sqlGetChildTodos = `
+	SELECT ` + todoColumns + ` FROM todo
+		WHERE parent_id = ? AND deleted_at IS NULL
+	`
+	sqlGetTodoAncestors = `
+	WITH RECURSIVE ancestors(id, depth) AS (
+		SELECT parent_id, 1 FROM todo WHERE todo_id = ?
+		UNION ALL
+		SELECT todo.parent_id, ancestors.depth + 1 FROM todo JOIN ancestors ON todo.todo_id = ancestors.id
+		WHERE ancestors.depth < 1000
+	)
+	SELECT id FROM ancestors WHERE id IS NOT NULL ORDER BY depth
+	`
+	sqlGetSubtreeHeight = `
+	WITH RECURSIVE subtree(id, depth) AS (
+		SELECT ?, 1
+		UNION ALL
+		SELECT todo.todo_id, subtree.depth + 1 FROM todo JOIN subtree ON todo.parent_id = subtree.id
+		WHERE subtree.depth < 1000
+	)
+	SELECT MAX(depth) FROM subtree
+	`
+)
+
+func (t todoDatabase) GetChildTodos(parentId int) (*[]model.Todo, error) {
+	rows, err := t.db.Query(sqlGetChildTodos, parentId)
+	if err != nil {
+		return nil, err
+	}
+	return scanTodos(rows)
+}
+
+// GetTodoAncestors returns the ids above a todo, its parent first
+func (t todoDatabase) GetTodoAncestors(id int) ([]int, error) {
+	var ancestors []int
+	rows, err := t.db.Query(sqlGetTodoAncestors, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ancestor int
+		if err := rows.Scan(&ancestor); err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, ancestor)
+	}
+	return ancestors, rows.Err()
+}
+
+// GetSubtreeHeight returns the number of levels from a todo down to its deepest subtask, a todo without subtasks has height 1
+func (t todoDatabase) GetSubtreeHeight(id int) (int, error) {
+	var height int
+	err := t.db.QueryRow(sqlGetSubtreeHeight, id).Scan(&height)
+	if err != nil {
+		return 0, err
+	}
+	return height, nil
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateTag = `
+    CREATE TABLE IF NOT EXISTS tag(
+        tag_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        name VARCHAR NOT NULL,
+		color VARCHAR NOT NULL,
+		user_id INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlCreateTodoTag = `
+    CREATE TABLE IF NOT EXISTS todo_tag(
+        todo_id INTEGER NOT NULL,
+        tag_id INTEGER NOT NULL,
+		PRIMARY KEY (todo_id, tag_id),
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (tag_id) REFERENCES tag (tag_id)
+    );
+    `
+	sqlInsertTag = `
+	INSERT INTO tag
+		(name,color,user_id)
+		VALUES (?,?,?);
+	`
+	sqlGetTags = `
+	SELECT tag_id,name,color,user_id FROM tag
+		WHERE user_id = ?
+	`
+	sqlGetTagById = `
+	SELECT tag_id,name,color,user_id FROM tag
+		WHERE user_id = ? AND tag_id = ?
+	`
+	sqlUpdateTag = `
+	UPDATE tag
+		SET name = ?,
+		color = ?
+		WHERE tag_id = ?
+	`
+	sqlDeleteTagLinks = `
+	DELETE FROM todo_tag
+		WHERE tag_id = ?
+	`
+	sqlDeleteTag = `
+	DELETE FROM tag
+		WHERE tag_id = ?
+	`
+	// sqlDeleteTodoTags removes the tags one user put on a todo, the tags of the other members stay
+	sqlDeleteTodoTags = `
+	DELETE FROM todo_tag
+		WHERE todo_id = ? AND tag_id IN (SELECT tag_id FROM tag WHERE user_id = ?)
+	`
+	sqlDeleteSubtreeTags = `
+	DELETE FROM todo_tag
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+	sqlInsertTodoTag = `
+	INSERT OR IGNORE INTO todo_tag
+		(todo_id,tag_id)
+		VALUES (?,?);
+	`
+	sqlGetTodoTags = `
+	SELECT todo_tag.todo_id, todo_tag.tag_id FROM todo_tag
+		JOIN tag ON tag.tag_id = todo_tag.tag_id
+		WHERE tag.user_id = ?
+		ORDER BY todo_tag.todo_id, todo_tag.tag_id
+	`
+)
+
+func scanTag(row scanner) (*model.Tag, error) {
+	var tag model.Tag
+	err := row.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (t todoDatabase) AddTag(tag *model.Tag) error {
+	res, err := t.db.Exec(sqlInsertTag, tag.Name, tag.Color, tag.UserId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	tag.ID = int(id)
+	return nil
+}
+
+func (t todoDatabase) GetTags(userId int) (*[]model.Tag, error) {
+	tags := []model.Tag{}
+	rows, err := t.db.Query(sqlGetTags, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+	return &tags, rows.Err()
+}
+
+func (t todoDatabase) GetTagById(userId, tagId int) (*model.Tag, error) {
+	return scanTag(t.db.QueryRow(sqlGetTagById, userId, tagId))
+}
+
+func (t todoDatabase) UpdateTag(tag *model.Tag) error {
+	_, err := t.db.Exec(sqlUpdateTag, tag.Name, tag.Color, tag.ID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteTag removes the tag from every todo carrying it and then the tag itself, the todos stay
+func (t todoDatabase) DeleteTag(tagId int) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteTagLinks, tagId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteTag, tagId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetTodoTags replaces the tags the user put on a todo
+func (t todoDatabase) SetTodoTags(userId, todoId int, tagIds []int) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteTodoTags, todoId, userId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	for _, tagId := range tagIds {
+		if _, err = tx.Exec(sqlInsertTodoTag, todoId, tagId); err != nil {
+			fmt.Println(err)
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetTodoTags returns the tag ids of every tagged todo of the user, keyed by todo id
+func (t todoDatabase) GetTodoTags(userId int) (map[int][]int, error) {
+	rows, err := t.db.Query(sqlGetTodoTags, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	todoTags := map[int][]int{}
+	for rows.Next() {
+		var todoId, tagId int
+		if err := rows.Scan(&todoId, &tagId); err != nil {
+			return nil, err
+		}
+		todoTags[todoId] = append(todoTags[todoId], tagId)
+	}
+	return todoTags, rows.Err()
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"todo/constants"
+	"todo/filter"
+	"todo/model"
+)
+
+const (
+	// sqlListTodos is completed with the sort key, the filter and the sort direction twice
+	sqlListTodos = `
+	SELECT ` + todoColumns + `,%[1]s FROM todo
+		WHERE %[2]s
+		ORDER BY %[1]s %[3]s, todo_id %[3]s
+		LIMIT ?
+	`
+	sqlCountTodos = `
+	SELECT COUNT(*) FROM todo
+		WHERE %s
+	`
+	// sqlTodosWithTags is completed with the tag placeholders, a todo matches when it carries
+	// at least the given number of the tags
+	sqlTodosWithTags = `todo_id IN (
+		SELECT todo_id FROM todo_tag
+		WHERE tag_id IN (%s)
+		GROUP BY todo_id
+		HAVING COUNT(DISTINCT tag_id) >= ?
+	)`
+	// sqlPriorityRank orders the known priorities from high to low, anything else comes after them
+	sqlPriorityRank = `CASE lower(priority) WHEN 'high' THEN 0 WHEN 'medium' THEN 1 WHEN 'low' THEN 2 ELSE 3 END`
+	// todos due before or after a bound, all day todos are compared by their date
+	sqlTodosDueBefore = `((all_day = 0 AND due_at < ?) OR (all_day = 1 AND due_at < ?))`
+	sqlTodosDueAfter  = `((all_day = 0 AND due_at >= ?) OR (all_day = 1 AND due_at >= ?))`
+)
+
+// ErrInvalidCursor is returned for a cursor which was not handed out for the same listing order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// todoCursor is where the next page of the listing starts: the sort key and id of the last todo handed out
+type todoCursor struct {
+	Sort string          `json:"s"`
+	Desc bool            `json:"d"`
+	Key  json.RawMessage `json:"k"`
+	Id   int             `json:"i"`
+}
+
+// todoSortKey is the expression the listing is ordered by, todos without a due date come last either way
+func todoSortKey(sort string, desc bool) string {
+	switch sort {
+	case constants.TodoSortPriority:
+		return sqlPriorityRank
+	case constants.TodoSortCreated:
+		return "created_at"
+	case constants.TodoSortTitle:
+		return "lower(title)"
+	}
+	if desc {
+		return fmt.Sprintf("COALESCE(due_at, %d)", math.MinInt64+1)
+	}
+	return fmt.Sprintf("COALESCE(due_at, %d)", math.MaxInt64)
+}
+
+// newSortKey returns a destination for the sort key column, the title scans into a string
+func newSortKey(sort string) interface{} {
+	if sort == constants.TodoSortTitle {
+		return new(string)
+	}
+	return new(int64)
+}
+
+// extraScanner reads columns selected after the todo columns, like the sort key
+type extraScanner struct {
+	row   scanner
+	extra []interface{}
+}
+
+func (e extraScanner) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+func encodeTodoCursor(sort string, desc bool, key interface{}, id int) (string, error) {
+	value, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	cursor, err := json.Marshal(todoCursor{Sort: sort, Desc: desc, Key: value, Id: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+func decodeTodoCursor(encoded, sort string, desc bool) (interface{}, int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var cursor todoCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort || cursor.Desc != desc {
+		return nil, 0, ErrInvalidCursor
+	}
+	key := newSortKey(sort)
+	if err := json.Unmarshal(cursor.Key, key); err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	switch key := key.(type) {
+	case *string:
+		return *key, cursor.Id, nil
+	case *int64:
+		return *key, cursor.Id, nil
+	}
+	return nil, 0, ErrInvalidCursor
+}
+
+// ListTodos returns a page of the todos visible to the user matching the query, with the number of matching todos.
+// The due bounds and the dates of the filter expression are resolved by the caller, nil does not filter.
+func (t todoDatabase) ListTodos(userId int, query model.TodoQuery, dueBefore, dueAfter *model.DueBound, expr filter.Expr) (*model.TodoPage, error) {
+	where := []string{sqlVisibleTodos}
+	args := []interface{}{userId, userId, userId}
+	if query.Completed != nil {
+		where = append(where, "completed = ?")
+		args = append(args, *query.Completed)
+	}
+	if query.Category != nil {
+		where = append(where, "category = ?")
+		args = append(args, *query.Category)
+	}
+	if query.AssigneeId != nil {
+		where = append(where, "assignee_id = ?")
+		args = append(args, *query.AssigneeId)
+	}
+	if query.CreatorId != nil {
+		where = append(where, "user_id = ?")
+		args = append(args, *query.CreatorId)
+	}
+	if query.Priority != "" {
+		where = append(where, "priority = ?")
+		args = append(args, query.Priority)
+	}
+	if dueBefore != nil {
+		where = append(where, sqlTodosDueBefore)
+		args = append(args, dueBefore.At.Unix(), dueBefore.Date.Unix())
+	}
+	if dueAfter != nil {
+		where = append(where, sqlTodosDueAfter)
+		args = append(args, dueAfter.At.Unix(), dueAfter.Date.Unix())
+	}
+	if len(query.TagIds) > 0 {
+		where = append(where, fmt.Sprintf(sqlTodosWithTags, strings.TrimSuffix(strings.Repeat("?,", len(query.TagIds)), ",")))
+		for _, tagId := range query.TagIds {
+			args = append(args, tagId)
+		}
+		matches := 1
+		if query.MatchAll {
+			matches = len(query.TagIds)
+		}
+		args = append(args, matches)
+	}
+	if expr != nil {
+		filterWhere, filterArgs := compileFilter(expr, userId)
+		where = append(where, filterWhere)
+		args = append(args, filterArgs...)
+	}
+	page := model.TodoPage{}
+	err := t.db.QueryRow(fmt.Sprintf(sqlCountTodos, strings.Join(where, " AND ")), args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKey := todoSortKey(query.Sort, query.Desc)
+	order := "ASC"
+	after := ">"
+	if query.Desc {
+		order = "DESC"
+		after = "<"
+	}
+	if query.Cursor != "" {
+		key, id, err := decodeTodoCursor(query.Cursor, query.Sort, query.Desc)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, fmt.Sprintf("(%s, todo_id) %s (?, ?)", sortKey, after))
+		args = append(args, key, id)
+	}
+	// one todo more than the page tells whether there is a next page
+	args = append(args, query.Limit+1)
+	rows, err := t.db.Query(fmt.Sprintf(sqlListTodos, sortKey, strings.Join(where, " AND "), order), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	todos := []model.Todo{}
+	var lastKey interface{}
+	for rows.Next() {
+		key := newSortKey(query.Sort)
+		todo, err := scanTodo(extraScanner{row: rows, extra: []interface{}{key}})
+		if err != nil {
+			return nil, err
+		}
+		if len(todos) == query.Limit {
+			page.NextCursor, err = encodeTodoCursor(query.Sort, query.Desc, lastKey, todos[len(todos)-1].ID)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		todos = append(todos, *todo)
+		lastKey = key
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	page.Todos = &todos
+	return &page, nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	sqlCreateUserTOTP = `
+    CREATE TABLE IF NOT EXISTS user_totp(
+        user_id INTEGER NOT NULL PRIMARY KEY,
+		secret VARCHAR NOT NULL,
+		confirmed INTEGER DEFAULT 0,
+		last_used_step INTEGER DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlCreateRecoveryCode = `
+    CREATE TABLE IF NOT EXISTS recovery_code(
+        code_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL,
+		code_hash VARCHAR NOT NULL,
+		used INTEGER DEFAULT 0,
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlSaveUserTOTP = `
+	INSERT OR REPLACE INTO user_totp
+		(user_id,secret,confirmed,last_used_step,created_at)
+		VALUES (?,?,0,0,?);
+	`
+	sqlGetUserTOTP = `
+	SELECT user_id,secret,confirmed,last_used_step,created_at FROM user_totp
+		WHERE user_id = ?
+	`
+	sqlConfirmUserTOTP = `
+	UPDATE user_totp
+		SET confirmed = 1
+		WHERE user_id = ?
+	`
+	sqlUseTOTPStep = `
+	UPDATE user_totp
+		SET last_used_step = ?
+		WHERE user_id = ? AND last_used_step < ?
+	`
+	sqlDeleteUserTOTP = `
+	DELETE FROM user_totp
+		WHERE user_id = ?
+	`
+	sqlDeleteRecoveryCodes = `
+	DELETE FROM recovery_code
+		WHERE user_id = ?
+	`
+	sqlInsertRecoveryCode = `
+	INSERT INTO recovery_code
+		(user_id,code_hash)
+		VALUES (?,?);
+	`
+	sqlUseRecoveryCode = `
+	UPDATE recovery_code
+		SET used = 1
+		WHERE user_id = ? AND code_hash = ? AND used = 0
+	`
+)
+
+func (t todoDatabase) SaveUserTOTP(totp *model.UserTOTP) error {
+	_, err := t.db.Exec(sqlSaveUserTOTP, totp.UserId, totp.Secret, totp.CreatedAt)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) GetUserTOTP(userId int) (*model.UserTOTP, error) {
+	var totp model.UserTOTP
+	err := t.db.QueryRow(sqlGetUserTOTP, userId).Scan(&totp.UserId, &totp.Secret, &totp.Confirmed, &totp.LastUsedStep, &totp.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+func (t todoDatabase) ConfirmUserTOTP(userId int) error {
+	_, err := t.db.Exec(sqlConfirmUserTOTP, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code, it affects no row if that step or a later one was used already
+func (t todoDatabase) UseTOTPStep(userId int, step int64) (int64, error) {
+	res, err := t.db.Exec(sqlUseTOTPStep, step, userId, step)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteUserTOTP switches two factor authentication off, together with the recovery codes
+func (t todoDatabase) DeleteUserTOTP(userId int) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteUserTOTP, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteRecoveryCodes, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes drops every recovery code of the user and stores the new ones
+func (t todoDatabase) ReplaceRecoveryCodes(userId int, hashes []string) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlDeleteRecoveryCodes, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, hash := range hashes {
+		if _, err = tx.Exec(sqlInsertRecoveryCode, userId, hash); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (t todoDatabase) UseRecoveryCode(userId int, hash string) (int64, error) {
+	res, err := t.db.Exec(sqlUseRecoveryCode, userId, hash)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
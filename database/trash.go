@@ -0,0 +1,288 @@
+package database
+
+import (
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	// sqlTrashTodo moves the todo and its subtasks to the trash, subtasks trashed before keep their own time
+	sqlTrashTodo = `
+	UPDATE todo
+		SET deleted_at = ?
+		WHERE todo_id IN (` + sqlTodoSubtree + `) AND deleted_at IS NULL
+	`
+	// sqlRestoreTodo brings back the todo with the subtasks trashed together with it, it takes the todo id twice
+	sqlRestoreTodo = `
+	UPDATE todo
+		SET deleted_at = NULL
+		WHERE todo_id IN (` + sqlTodoSubtree + `) AND deleted_at = (SELECT deleted_at FROM todo WHERE todo_id = ?)
+	`
+	sqlGetTrashedTodoById = `
+	SELECT ` + todoColumns + `,deleted_at FROM todo
+		WHERE todo_id = ? AND deleted_at IS NOT NULL
+	`
+	// sqlGetTrashedTodos lists the trashed todos of the user and of the categories it can access, subtasks
+	// trashed together with their parent are left out, it takes the user id three times
+	sqlGetTrashedTodos = `
+	SELECT ` + todoColumns + `,deleted_at FROM todo
+		WHERE deleted_at IS NOT NULL
+		AND (todo.user_id = ? OR todo.category IN (` + sqlAccessibleCategories + `))
+		AND NOT EXISTS (SELECT 1 FROM todo AS parent WHERE parent.todo_id = todo.parent_id AND parent.deleted_at = todo.deleted_at)
+		AND NOT EXISTS (` + sqlTrashedWithCategory + `)
+		ORDER BY deleted_at DESC, todo_id
+	`
+	sqlGetExpiredTodos = `
+	SELECT todo_id FROM todo
+		WHERE deleted_at < ?
+		ORDER BY deleted_at, todo_id
+	`
+	// sqlTrashedWithCategory finds the category of the todo when the todo went to the trash together with it
+	sqlTrashedWithCategory = `SELECT 1 FROM category WHERE category.category_id = todo.category AND category.deleted_at = todo.deleted_at`
+	// sqlCategoryTodos are the todos of the category with all their subtasks
+	sqlCategoryTodos = `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT todo_id FROM todo WHERE category = ?
+		UNION
+		SELECT todo.todo_id FROM todo JOIN subtree ON todo.parent_id = subtree.id
+	)
+	SELECT id FROM subtree`
+	// sqlTrashCategoryTodos moves the todos of the category to the trash with it, todos trashed before keep their own time
+	sqlTrashCategoryTodos = `
+	UPDATE todo
+		SET deleted_at = ?
+		WHERE todo_id IN (` + sqlCategoryTodos + `) AND deleted_at IS NULL
+	`
+	// sqlRestoreCategoryTodos brings back the todos trashed together with the category, it takes the category id twice
+	sqlRestoreCategoryTodos = `
+	UPDATE todo
+		SET deleted_at = NULL
+		WHERE todo_id IN (` + sqlCategoryTodos + `) AND deleted_at = (SELECT deleted_at FROM category WHERE category_id = ?)
+	`
+	// sqlGetCategoryTrashedTodos lists the todos trashed together with the category, subtasks go with their parent
+	sqlGetCategoryTrashedTodos = `
+	SELECT todo_id FROM todo
+		WHERE category = ? AND deleted_at = (SELECT deleted_at FROM category WHERE category_id = ?)
+		AND NOT EXISTS (SELECT 1 FROM todo AS parent WHERE parent.todo_id = todo.parent_id AND parent.deleted_at = todo.deleted_at)
+		ORDER BY todo_id
+	`
+	// sqlTrashTodosOfTrashedCategories moves the todos left behind in categories trashed before their
+	// todos went to the trash with them, run once by the migration
+	sqlTrashTodosOfTrashedCategories = `
+	WITH RECURSIVE subtree(id, deleted_at) AS (
+		SELECT todo.todo_id, category.deleted_at FROM todo JOIN category ON category.category_id = todo.category
+			WHERE category.deleted_at IS NOT NULL
+		UNION
+		SELECT todo.todo_id, subtree.deleted_at FROM todo JOIN subtree ON todo.parent_id = subtree.id
+	)
+	UPDATE todo
+		SET deleted_at = (SELECT MIN(subtree.deleted_at) FROM subtree WHERE subtree.id = todo.todo_id)
+		WHERE deleted_at IS NULL AND todo_id IN (SELECT id FROM subtree)
+	`
+	sqlTrashCategory = `
+	UPDATE category
+		SET deleted_at = ?
+		WHERE category_id = ? AND deleted_at IS NULL
+	`
+	sqlRestoreCategory = `
+	UPDATE category
+		SET deleted_at = NULL
+		WHERE category_id = ? AND deleted_at IS NOT NULL
+	`
+	sqlGetTrashedCategoryById = `
+	SELECT category_id,category_name,user_id,deleted_at FROM category
+		WHERE category_id = ? AND deleted_at IS NOT NULL
+	`
+	sqlGetTrashedCategories = `
+	SELECT category_id,category_name,user_id,deleted_at FROM category
+		WHERE user_id = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, category_id
+	`
+	sqlGetExpiredCategories = `
+	SELECT category_id,category_name,user_id,deleted_at FROM category
+		WHERE deleted_at < ?
+		ORDER BY deleted_at, category_id
+	`
+)
+
+func scanTrashedTodo(row scanner) (*model.Todo, error) {
+	var deletedAt int64
+	todo, err := scanTodo(extraScanner{row: row, extra: []interface{}{&deletedAt}})
+	if err != nil {
+		return nil, err
+	}
+	todo.DeletedAt = &deletedAt
+	return todo, nil
+}
+
+func scanTrashedCategory(row scanner) (*model.Category, error) {
+	var category model.Category
+	var deletedAt int64
+	err := row.Scan(&category.ID, &category.Name, &category.UserId, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	category.DeletedAt = &deletedAt
+	return &category, nil
+}
+
+// TrashTodo moves the todo and its subtasks to the trash, it returns the number of todos trashed
+func (t todoDatabase) TrashTodo(todoId int, deletedAt int64) (int64, error) {
+	res, err := t.db.Exec(sqlTrashTodo, deletedAt, todoId)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RestoreTodo takes the todo out of the trash with the subtasks trashed together with it
+func (t todoDatabase) RestoreTodo(todoId int) (int64, error) {
+	res, err := t.db.Exec(sqlRestoreTodo, todoId, todoId)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t todoDatabase) GetTrashedTodoById(todoId int) (*model.Todo, error) {
+	return scanTrashedTodo(t.db.QueryRow(sqlGetTrashedTodoById, todoId))
+}
+
+// GetTrashedTodos returns the trashed todos the user can see, most recently trashed first
+func (t todoDatabase) GetTrashedTodos(userId int) (*[]model.Todo, error) {
+	rows, err := t.db.Query(sqlGetTrashedTodos, userId, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	todos := []model.Todo{}
+	for rows.Next() {
+		todo, err := scanTrashedTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, *todo)
+	}
+	return &todos, rows.Err()
+}
+
+// GetExpiredTodos returns the ids of the todos trashed before the given time, oldest first
+func (t todoDatabase) GetExpiredTodos(before int64) ([]int, error) {
+	rows, err := t.db.Query(sqlGetExpiredTodos, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// TrashCategory moves the category to the trash together with its todos and their subtasks
+func (t todoDatabase) TrashCategory(categoryId int, deletedAt int64) (int64, error) {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(sqlTrashCategory, deletedAt, categoryId)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	effect, err := res.RowsAffected()
+	if err != nil || effect == 0 {
+		tx.Rollback()
+		return 0, err
+	}
+	if _, err := tx.Exec(sqlTrashCategoryTodos, deletedAt, categoryId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	return effect, tx.Commit()
+}
+
+// RestoreCategory takes the category out of the trash with the todos trashed together with it
+func (t todoDatabase) RestoreCategory(categoryId int) (int64, error) {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	// the todos are matched by the time the category was trashed, so they come back first
+	if _, err := tx.Exec(sqlRestoreCategoryTodos, categoryId, categoryId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	res, err := tx.Exec(sqlRestoreCategory, categoryId)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return 0, err
+	}
+	effect, err := res.RowsAffected()
+	if err != nil || effect == 0 {
+		tx.Rollback()
+		return 0, err
+	}
+	return effect, tx.Commit()
+}
+
+// GetCategoryTrashedTodos returns the ids of the todos trashed together with the category, without their subtasks
+func (t todoDatabase) GetCategoryTrashedTodos(categoryId int) ([]int, error) {
+	rows, err := t.db.Query(sqlGetCategoryTrashedTodos, categoryId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (t todoDatabase) GetTrashedCategoryById(categoryId int) (*model.Category, error) {
+	return scanTrashedCategory(t.db.QueryRow(sqlGetTrashedCategoryById, categoryId))
+}
+
+// GetTrashedCategories returns the trashed categories of the user, most recently trashed first
+func (t todoDatabase) GetTrashedCategories(userId int) (*[]model.Category, error) {
+	return t.getTrashedCategories(sqlGetTrashedCategories, userId)
+}
+
+// GetExpiredCategories returns the categories trashed before the given time, oldest first
+func (t todoDatabase) GetExpiredCategories(before int64) (*[]model.Category, error) {
+	return t.getTrashedCategories(sqlGetExpiredCategories, before)
+}
+
+func (t todoDatabase) getTrashedCategories(query string, arg interface{}) (*[]model.Category, error) {
+	rows, err := t.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	categories := []model.Category{}
+	for rows.Next() {
+		category, err := scanTrashedCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+	return &categories, rows.Err()
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"todo/model"
+)
+
+func todoTitles(todos []model.Todo) string {
+	titles := []string{}
+	for _, todo := range todos {
+		titles = append(titles, todo.Title)
+	}
+	sort.Strings(titles)
+	return strings.Join(titles, ",")
+}
+
+// TestTrashCategoryTodos makes sure the todos of a trashed category leave every listing and come back with it
+func TestTrashCategoryTodos(t *testing.T) {
+	database := newTestDatabase(t)
+	user := model.User{Name: "a", Email: "a@example.com", Password: "x", Role: "user", TimeZone: "UTC"}
+	if err := database.CreateUser(&user); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.AddCategory(&model.Category{Name: "work", UserId: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	categories, err := database.GetCategory(user.ID)
+	if err != nil || len(*categories) != 1 {
+		t.Fatalf("categories = %v, %v", categories, err)
+	}
+	category := (*categories)[0].ID
+	add := func(title string, category int, parent *int) int {
+		todo := model.Todo{Title: title, UserId: user.ID, Category: category, ParentId: parent, Status: "todo", CreatedAt: 1}
+		if err := database.AddTodo(&todo); err != nil {
+			t.Fatal(err)
+		}
+		return todo.ID
+	}
+	report := add("report", category, nil)
+	add("draft", 0, &report)
+	earlier := add("old", category, nil)
+	add("home", 0, nil)
+	if _, err := database.TrashTodo(earlier, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if effect, err := database.TrashCategory(category, 200); err != nil || effect != 1 {
+		t.Fatalf("TrashCategory = %d, %v", effect, err)
+	}
+	page, err := database.ListTodos(user.ID, model.TodoQuery{Limit: 10}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := todoTitles(*page.Todos); got != "home" {
+		t.Errorf("todos listed with the category in the trash = %s, want home", got)
+	}
+	// the todos trashed with the category are in the trash as the category, not one by one
+	trashed, err := database.GetTrashedTodos(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := todoTitles(*trashed); got != "old" {
+		t.Errorf("trashed todos = %s, want old", got)
+	}
+	ids, err := database.GetCategoryTrashedTodos(category)
+	if err != nil || len(ids) != 1 || ids[0] != report {
+		t.Errorf("todos trashed with the category = %v, %v, want [%d]", ids, err, report)
+	}
+
+	if effect, err := database.RestoreCategory(category); err != nil || effect != 1 {
+		t.Fatalf("RestoreCategory = %d, %v", effect, err)
+	}
+	page, err = database.ListTodos(user.ID, model.TodoQuery{Limit: 10}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the todo trashed on its own before stays in the trash
+	if got := todoTitles(*page.Todos); got != "draft,home,report" {
+		t.Errorf("todos listed after the restore = %s, want draft,home,report", got)
+	}
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"todo/model"
+)
+
+const (
+	// sqlCreateWorkflow holds the workflow of a user in category 0 and the ones of its categories,
+	// the states and transitions are stored as json
+	sqlCreateWorkflow = `
+    CREATE TABLE IF NOT EXISTS workflow(
+        workflow_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        user_id INTEGER NOT NULL,
+		category_id INTEGER NOT NULL DEFAULT 0,
+		definition VARCHAR NOT NULL,
+		UNIQUE (user_id, category_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	sqlCreateStatusChange = `
+    CREATE TABLE IF NOT EXISTS status_change(
+        change_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+        todo_id INTEGER NOT NULL,
+        user_id INTEGER NOT NULL,
+		from_status VARCHAR NOT NULL,
+		to_status VARCHAR NOT NULL,
+		changed_at INTEGER NOT NULL,
+		FOREIGN KEY (todo_id) REFERENCES todo (todo_id),
+		FOREIGN KEY (user_id) REFERENCES user (user_id)
+    );
+    `
+	// todos from before the status column take it from their completed flag
+	sqlMigrateTodoStatus = `
+	UPDATE todo
+		SET status = CASE WHEN completed = 1 THEN 'done' ELSE 'todo' END
+		WHERE status = ''
+	`
+	sqlGetWorkflow = `
+	SELECT category_id,definition FROM workflow
+		WHERE user_id = ? AND category_id = ?
+	`
+	sqlGetWorkflows = `
+	SELECT category_id,definition FROM workflow
+		WHERE user_id = ? AND category_id NOT IN (SELECT category_id FROM category WHERE deleted_at IS NOT NULL)
+		ORDER BY category_id
+	`
+	sqlSetWorkflow = `
+	INSERT INTO workflow
+		(user_id,category_id,definition)
+		VALUES (?,?,?)
+		ON CONFLICT (user_id, category_id) DO UPDATE SET definition = excluded.definition
+	`
+	sqlDeleteWorkflow = `
+	DELETE FROM workflow
+		WHERE user_id = ? AND category_id = ?
+	`
+	sqlUpdateTodoStatus = `
+	UPDATE todo
+		SET status = ?,
+		completed = ?
+		WHERE todo_id = ?
+	`
+	sqlInsertStatusChange = `
+	INSERT INTO status_change
+		(todo_id,user_id,from_status,to_status,changed_at)
+		VALUES (?,?,?,?,?);
+	`
+	sqlGetStatusChanges = `
+	SELECT change_id,todo_id,user_id,from_status,to_status,changed_at FROM status_change
+		WHERE todo_id = ?
+		ORDER BY changed_at, change_id
+	`
+	sqlDeleteSubtreeStatusChanges = `
+	DELETE FROM status_change
+		WHERE todo_id IN (` + sqlTodoSubtree + `)
+	`
+)
+
+func scanWorkflow(row scanner) (*model.Workflow, error) {
+	var workflow model.Workflow
+	var category int
+	var definition string
+	if err := row.Scan(&category, &definition); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(definition), &workflow); err != nil {
+		return nil, err
+	}
+	workflow.Category = category
+	return &workflow, nil
+}
+
+// workflowDefinition is the json stored for a workflow, the category has a column of its own
+func workflowDefinition(workflow *model.Workflow) (string, error) {
+	definition, err := json.Marshal(struct {
+		States      []model.WorkflowState      `json:"states"`
+		Transitions []model.WorkflowTransition `json:"transitions"`
+	}{workflow.States, workflow.Transitions})
+	return string(definition), err
+}
+
+func (t todoDatabase) GetWorkflow(userId, categoryId int) (*model.Workflow, error) {
+	return scanWorkflow(t.db.QueryRow(sqlGetWorkflow, userId, categoryId))
+}
+
+func (t todoDatabase) GetWorkflows(userId int) (*[]model.Workflow, error) {
+	workflows := []model.Workflow{}
+	rows, err := t.db.Query(sqlGetWorkflows, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		workflow, err := scanWorkflow(rows)
+		if err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, *workflow)
+	}
+	return &workflows, rows.Err()
+}
+
+func (t todoDatabase) SetWorkflow(userId int, workflow *model.Workflow) error {
+	definition, err := workflowDefinition(workflow)
+	if err != nil {
+		return err
+	}
+	_, err = t.db.Exec(sqlSetWorkflow, userId, workflow.Category, definition)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (t todoDatabase) DeleteWorkflow(userId, categoryId int) (int64, error) {
+	res, err := t.db.Exec(sqlDeleteWorkflow, userId, categoryId)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// UpdateTodoStatus moves a todo to the status of the change and records the change
+func (t todoDatabase) UpdateTodoStatus(change *model.StatusChange, completed bool) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(sqlUpdateTodoStatus, change.To, completed, change.TodoId); err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.Exec(sqlInsertStatusChange, change.TodoId, change.UserId, change.From, change.To, change.ChangedAt)
+	if err != nil {
+		fmt.Println(err)
+		tx.Rollback()
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	change.ID = int(id)
+	return tx.Commit()
+}
+
+func (t todoDatabase) GetStatusChanges(todoId int) (*[]model.StatusChange, error) {
+	changes := []model.StatusChange{}
+	rows, err := t.db.Query(sqlGetStatusChanges, todoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var change model.StatusChange
+		if err := rows.Scan(&change.ID, &change.TodoId, &change.UserId, &change.From, &change.To, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return &changes, rows.Err()
+}
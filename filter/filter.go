@@ -0,0 +1,406 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"todo/constants"
+	"todo/model"
+)
+
+// Fields a condition can name, FieldText is a bare word or "quoted words" searched in title and description
+const (
+	FieldText        = "text"
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldPriority    = "priority"
+	FieldStatus      = "status"
+	FieldCategory    = "category"
+	FieldTag         = "tag"
+	FieldDue         = "due"
+	FieldCreated     = "created"
+	FieldCompleted   = "completed"
+	FieldOverdue     = "overdue"
+	FieldAllDay      = "allday"
+	FieldRecurring   = "recurring"
+)
+
+// Operators of a condition, OpIs is the plain field:value form
+const (
+	OpIs           = ":"
+	OpBefore       = "<"
+	OpBeforeOrOn   = "<="
+	OpAfter        = ">"
+	OpOnOrAfter    = ">="
+	noneValue      = "none"
+	fieldNamesHint = "title, description, priority, status, category, tag, due, created, completed, overdue, allday or recurring"
+)
+
+// relativeDay is a number of days or weeks from today like 7d, -1w or +2d
+var relativeDay = regexp.MustCompile(`^([+-]?\d{1,4})([dw])$`)
+
+// Expr is a parsed filter expression: And, Or, Not or a *Condition
+type Expr interface {
+	expr()
+}
+
+// And matches todos matching all of Exprs, terms next to each other are joined by And
+type And struct {
+	Exprs []Expr
+}
+
+// Or matches todos matching any of Exprs
+type Or struct {
+	Exprs []Expr
+}
+
+// Not matches todos not matching Expr, written as a leading -
+type Not struct {
+	Expr Expr
+}
+
+// Condition is a single term like priority:high, due:<7d, completed or a bare word
+type Condition struct {
+	// Pos is the position of the term in the input, counting characters from 1
+	Pos   int
+	Field string
+	Op    string
+	Value string
+	// Day is the date of due and created conditions, nil for due:none
+	Day *Day
+	// Flag is the value of completed, overdue, allday and recurring
+	Flag bool
+	// Start and End are the bounds of Day, for overdue Start is now. Resolve sets them.
+	Start model.DueBound
+	End   model.DueBound
+}
+
+func (And) expr()        {}
+func (Or) expr()         {}
+func (Not) expr()        {}
+func (*Condition) expr() {}
+
+// Day is a calendar date, or a number of days from today when Date is zero
+type Day struct {
+	Date   time.Time
+	Offset int
+}
+
+// Error is a parse error at a position in the input, counting characters from 1
+type Error struct {
+	Pos     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+// Parse reads a filter expression like priority:high due:<7d -completed category:"Work".
+// Terms next to each other must all match, OR between terms lets either match, a leading - negates
+// a term and parentheses group terms. An empty expression returns nil, which matches every todo.
+func Parse(input string) (Expr, error) {
+	p := &parser{input: []rune(input)}
+	p.skipSpace()
+	if p.done() {
+		return nil, nil
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorAt(p.pos, "unexpected )")
+	}
+	return expr, nil
+}
+
+// Resolve fixes the dates of the expression for the moment and time zone it is evaluated in
+func Resolve(expr Expr, now time.Time, location *time.Location) {
+	switch expr := expr.(type) {
+	case And:
+		for _, e := range expr.Exprs {
+			Resolve(e, now, location)
+		}
+	case Or:
+		for _, e := range expr.Exprs {
+			Resolve(e, now, location)
+		}
+	case Not:
+		Resolve(expr.Expr, now, location)
+	case *Condition:
+		if expr.Field == FieldOverdue {
+			expr.Start = bound(now.In(location), now)
+		}
+		if expr.Day != nil {
+			day := expr.Day.Date
+			if day.IsZero() {
+				today := now.In(location)
+				day = time.Date(today.Year(), today.Month(), today.Day()+expr.Day.Offset, 0, 0, 0, 0, location)
+			} else {
+				day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location)
+			}
+			next := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, location)
+			expr.Start = bound(day, day)
+			expr.End = bound(next, next)
+		}
+	}
+}
+
+// bound limits todos due at a moment by at, and all day todos by the date of day
+func bound(day, at time.Time) model.DueBound {
+	return model.DueBound{At: at, Date: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+type parser struct {
+	input []rune
+	// pos is the index of the next rune
+	pos   int
+	depth int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// errorAt reports an error at the rune index i
+func (p *parser) errorAt(i int, format string, args ...interface{}) error {
+	return &Error{Pos: i + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+// atKeyword tells whether the next word is the upper case keyword
+func (p *parser) atKeyword(keyword string) bool {
+	end := p.pos + len(keyword)
+	if end > len(p.input) || string(p.input[p.pos:end]) != keyword {
+		return false
+	}
+	return end == len(p.input) || isDelimiter(p.input[end])
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	var exprs []Expr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.atKeyword("OR") {
+			break
+		}
+		p.pos += len("OR")
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return Or{Exprs: exprs}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	var exprs []Expr
+	for {
+		p.skipSpace()
+		if p.atKeyword("AND") {
+			p.pos += len("AND")
+			p.skipSpace()
+		}
+		if p.done() || p.peek() == ')' || p.atKeyword("OR") {
+			break
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 0 {
+		switch {
+		case p.done():
+			return nil, p.errorAt(p.pos, "expected a filter at the end")
+		case p.peek() == ')' && p.depth == 0:
+			return nil, p.errorAt(p.pos, "unexpected )")
+		case p.atKeyword("OR"):
+			return nil, p.errorAt(p.pos, "expected a filter before OR")
+		}
+		return nil, p.errorAt(p.pos, "expected a filter before %s", string(p.peek()))
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return And{Exprs: exprs}, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	start := p.pos
+	switch p.peek() {
+	case '-':
+		p.pos++
+		if p.done() || unicode.IsSpace(p.peek()) || p.peek() == ')' {
+			return nil, p.errorAt(start, "expected a filter after -")
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	case '(':
+		p.pos++
+		p.depth++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() {
+			return nil, p.errorAt(start, "missing closing parenthesis")
+		}
+		p.pos++
+		p.depth--
+		return expr, nil
+	}
+	return p.parseCondition()
+}
+
+// word reads up to the next delimiter, stopping at a colon too when colon is set
+func (p *parser) word(colon bool) string {
+	start := p.pos
+	for !p.done() && !isDelimiter(p.peek()) && !(colon && p.peek() == ':') {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// quoted reads a "quoted" value, p.pos is at the opening quote
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++
+	for !p.done() && p.peek() != '"' {
+		p.pos++
+	}
+	if p.done() {
+		return "", p.errorAt(start, "missing closing quote")
+	}
+	p.pos++
+	return string(p.input[start+1 : p.pos-1]), nil
+}
+
+func (p *parser) parseCondition() (Expr, error) {
+	start := p.pos
+	if p.peek() == '"' {
+		text, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return p.condition(start, FieldText, OpIs, text, start)
+	}
+	name := p.word(true)
+	if p.done() || p.peek() != ':' {
+		if field := strings.ToLower(name); isFlag(field) {
+			return &Condition{Pos: start + 1, Field: field, Op: OpIs, Flag: true}, nil
+		}
+		return p.condition(start, FieldText, OpIs, name, start)
+	}
+	if name == "" {
+		return nil, p.errorAt(start, "expected a field name before :")
+	}
+	p.pos++
+	op := OpIs
+	for _, candidate := range []string{OpBeforeOrOn, OpOnOrAfter, OpBefore, OpAfter} {
+		if end := p.pos + len(candidate); end <= len(p.input) && string(p.input[p.pos:end]) == candidate {
+			op = candidate
+			p.pos = end
+			break
+		}
+	}
+	valueStart := p.pos
+	var value string
+	if !p.done() && p.peek() == '"' {
+		var err error
+		if value, err = p.quoted(); err != nil {
+			return nil, err
+		}
+	} else {
+		value = p.word(false)
+	}
+	if strings.TrimSpace(value) == "" {
+		return nil, p.errorAt(valueStart, "expected a value for %s", name)
+	}
+	return p.condition(start, strings.ToLower(name), op, value, valueStart)
+}
+
+func isFlag(field string) bool {
+	return field == FieldCompleted || field == FieldOverdue || field == FieldAllDay || field == FieldRecurring
+}
+
+// condition checks the operator and value fit the field
+func (p *parser) condition(start int, field, op, value string, valueStart int) (Expr, error) {
+	c := &Condition{Pos: start + 1, Field: field, Op: op, Value: value}
+	switch {
+	case field == FieldText || field == FieldTitle || field == FieldDescription || field == FieldPriority ||
+		field == FieldStatus || field == FieldCategory || field == FieldTag:
+		if op != OpIs {
+			return nil, p.errorAt(start, "%s does not support %s, only %s:value", field, op, field)
+		}
+	case isFlag(field):
+		flag, err := strconv.ParseBool(value)
+		if err != nil || op != OpIs {
+			return nil, p.errorAt(valueStart, "%s takes true or false", field)
+		}
+		c.Flag = flag
+	case field == FieldDue || field == FieldCreated:
+		if strings.ToLower(value) == noneValue {
+			if field != FieldDue || op != OpIs {
+				return nil, p.errorAt(valueStart, "only due:none can ask for a missing date")
+			}
+			return c, nil
+		}
+		day, ok := parseDay(value)
+		if !ok {
+			return nil, p.errorAt(valueStart, "invalid date %q, use today, tomorrow, yesterday, a date like 2006-01-02 or days from today like 7d or -2w", value)
+		}
+		c.Day = day
+	default:
+		return nil, p.errorAt(start, "unknown field %q, use %s", field, fieldNamesHint)
+	}
+	return c, nil
+}
+
+func parseDay(value string) (*Day, bool) {
+	switch strings.ToLower(value) {
+	case "today":
+		return &Day{}, true
+	case "tomorrow":
+		return &Day{Offset: 1}, true
+	case "yesterday":
+		return &Day{Offset: -1}, true
+	}
+	if date, err := time.Parse(constants.DateLayout, value); err == nil {
+		return &Day{Date: date}, true
+	}
+	match := relativeDay.FindStringSubmatch(strings.ToLower(value))
+	if match == nil {
+		return nil, false
+	}
+	n, _ := strconv.Atoi(match[1])
+	if match[2] == "w" {
+		n *= 7
+	}
+	return &Day{Offset: n}, true
+}
@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func text(pos int, value string) *Condition {
+	return &Condition{Pos: pos, Field: FieldText, Op: OpIs, Value: value}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Expr
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"milk", text(1, "milk")},
+		{`"buy milk"`, text(1, "buy milk")},
+		{"priority:high", &Condition{Pos: 1, Field: FieldPriority, Op: OpIs, Value: "high"}},
+		{`Category:"Side projects"`, &Condition{Pos: 1, Field: FieldCategory, Op: OpIs, Value: "Side projects"}},
+		{"completed", &Condition{Pos: 1, Field: FieldCompleted, Op: OpIs, Flag: true}},
+		{"overdue:false", &Condition{Pos: 1, Field: FieldOverdue, Op: OpIs, Value: "false"}},
+		{"due:none", &Condition{Pos: 1, Field: FieldDue, Op: OpIs, Value: "none"}},
+		{"due:<=7d", &Condition{Pos: 1, Field: FieldDue, Op: OpBeforeOrOn, Value: "7d", Day: &Day{Offset: 7}}},
+		{"due:>-2w", &Condition{Pos: 1, Field: FieldDue, Op: OpAfter, Value: "-2w", Day: &Day{Offset: -14}}},
+		{"created:>=2026-03-01", &Condition{Pos: 1, Field: FieldCreated, Op: OpOnOrAfter, Value: "2026-03-01",
+			Day: &Day{Date: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)}}},
+		// terms next to each other are joined by AND, which binds tighter than OR
+		{"a b", And{Exprs: []Expr{text(1, "a"), text(3, "b")}}},
+		{"a AND b", And{Exprs: []Expr{text(1, "a"), text(7, "b")}}},
+		{"a b OR c", Or{Exprs: []Expr{And{Exprs: []Expr{text(1, "a"), text(3, "b")}}, text(8, "c")}}},
+		{"a OR b c", Or{Exprs: []Expr{text(1, "a"), And{Exprs: []Expr{text(6, "b"), text(8, "c")}}}}},
+		{"a (b OR c)", And{Exprs: []Expr{text(1, "a"), Or{Exprs: []Expr{text(4, "b"), text(9, "c")}}}}},
+		// - applies to the term right after it only
+		{"-a OR b", Or{Exprs: []Expr{Not{Expr: text(2, "a")}, text(7, "b")}}},
+		{"-(a OR b)", Not{Expr: Or{Exprs: []Expr{text(3, "a"), text(8, "b")}}}},
+		{"--a", Not{Expr: Not{Expr: text(3, "a")}}},
+		// keywords are upper case and whole words
+		{"a or b", And{Exprs: []Expr{text(1, "a"), text(3, "or"), text(6, "b")}}},
+		{"ORACLE", text(1, "ORACLE")},
+	}
+	for _, test := range tests {
+		got, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestParseFlagValue(t *testing.T) {
+	expr, err := Parse("recurring:false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := expr.(*Condition); c.Flag {
+		t.Fatalf("recurring:false parsed as %+v", c)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		pos     int
+		message string
+	}{
+		{"priority:high )", 15, "unexpected )"},
+		{"a ) b", 3, "unexpected )"},
+		{"(a b", 1, "missing closing parenthesis"},
+		{"a (b (c)", 3, "missing closing parenthesis"},
+		{"()", 2, "expected a filter before )"},
+		{"a OR", 5, "expected a filter at the end"},
+		{"OR a", 1, "expected a filter before OR"},
+		{"a OR OR b", 6, "expected a filter before OR"},
+		{"a - b", 3, "expected a filter after -"},
+		{`title:"abc`, 7, "missing closing quote"},
+		{`"abc`, 1, "missing closing quote"},
+		{":x", 1, "expected a field name before :"},
+		{"due:", 5, "expected a value for due"},
+		{"due:<soon", 6, `invalid date "soon"`},
+		{"completed:maybe", 11, "completed takes true or false"},
+		{"created:none", 9, "only due:none can ask for a missing date"},
+		{"due:<none", 6, "only due:none can ask for a missing date"},
+		{"colour:red", 1, `unknown field "colour"`},
+		{"a title:>x", 3, "title does not support > only title:value"},
+		// positions count characters, not bytes
+		{"café )", 6, "unexpected )"},
+	}
+	for _, test := range tests {
+		expr, err := Parse(test.input)
+		if err == nil {
+			t.Errorf("Parse(%q) = %#v, want an error", test.input, expr)
+			continue
+		}
+		parseErr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Parse(%q) error is a %T, want *Error", test.input, err)
+			continue
+		}
+		message := strings.ReplaceAll(parseErr.Message, ",", "")
+		if parseErr.Pos != test.pos || !strings.HasPrefix(message, test.message) {
+			t.Errorf("Parse(%q) error = %q at %d, want %q at %d", test.input, parseErr.Message, parseErr.Pos, test.message, test.pos)
+		}
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	_, err := Parse("a )")
+	if err == nil || err.Error() != "unexpected ) at position 3" {
+		t.Fatalf("error = %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	// 02:00 UTC is still the evening before in the time zone of the user
+	now := time.Date(2026, time.March, 10, 2, 0, 0, 0, time.UTC)
+	expr, err := Parse("due:tomorrow overdue created:2026-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Resolve(expr, now, location)
+	terms := expr.(And).Exprs
+
+	due := terms[0].(*Condition)
+	wantStart := time.Date(2026, time.March, 10, 0, 0, 0, 0, location)
+	wantEnd := time.Date(2026, time.March, 11, 0, 0, 0, 0, location)
+	if !due.Start.At.Equal(wantStart) || !due.End.At.Equal(wantEnd) {
+		t.Errorf("due:tomorrow spans %v to %v, want %v to %v", due.Start.At, due.End.At, wantStart, wantEnd)
+	}
+	// all day todos are stored as UTC midnight of their date
+	if want := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC); !due.Start.Date.Equal(want) {
+		t.Errorf("due:tomorrow starts on %v, want %v", due.Start.Date, want)
+	}
+
+	overdue := terms[1].(*Condition)
+	if !overdue.Start.At.Equal(now) || !overdue.Start.Date.Equal(time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("overdue bound = %+v", overdue.Start)
+	}
+
+	created := terms[2].(*Condition)
+	if want := time.Date(2026, time.January, 31, 0, 0, 0, 0, location); !created.Start.At.Equal(want) {
+		t.Errorf("created:2026-01-31 starts at %v, want %v", created.Start.At, want)
+	}
+	if want := time.Date(2026, time.February, 1, 0, 0, 0, 0, location); !created.End.At.Equal(want) {
+		t.Errorf("created:2026-01-31 ends at %v, want %v", created.End.At, want)
+	}
+}
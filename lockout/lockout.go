@@ -0,0 +1,147 @@
+package lockout
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// State is what is remembered about failed attempts for one key, such as an account or a client ip
+type State struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Store keeps the failed attempt state, it is implemented in memory and on the sqlite database.
+// Increment records a failure at now as the policy says, atomically so parallel failures all count.
+type Store interface {
+	Get(key string) (State, error)
+	Increment(key string, now time.Time, policy Policy) (State, error)
+	Reset(key string) error
+}
+
+// Policy configures how quickly attempts are slowed down and when a key gets locked
+type Policy struct {
+	// FreeAttempts are the failures allowed before backoff starts
+	FreeAttempts int
+	// BaseDelay is the wait after the first failure past the free attempts, it doubles with every further failure
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Threshold is the number of failures which locks the key for LockoutDuration
+	Threshold       int
+	LockoutDuration time.Duration
+}
+
+// Error is returned while a key is backing off or locked
+type Error struct {
+	RetryAfter time.Duration
+	// Locked is set when the threshold was hit, otherwise the caller is only being slowed down
+	Locked bool
+}
+
+func (e *Error) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Guard applies a policy on top of a store
+type Guard struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+//NewGuard creates a guard for the store
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{store: store, policy: policy, now: time.Now}
+}
+
+//Check returns an *Error if the key may not attempt right now
+func (g *Guard) Check(key string) error {
+	state, err := g.store.Get(key)
+	if err != nil {
+		return err
+	}
+	now := g.now()
+	if now.Before(state.LockedUntil) {
+		return &Error{RetryAfter: state.LockedUntil.Sub(now), Locked: true}
+	}
+	if next := state.LastFailure.Add(g.delay(state.Failures)); now.Before(next) {
+		return &Error{RetryAfter: next.Sub(now)}
+	}
+	return nil
+}
+
+//Fail records a failed attempt for the key
+func (g *Guard) Fail(key string) error {
+	_, err := g.store.Increment(key, g.now(), g.policy)
+	return err
+}
+
+//Succeed forgets the failed attempts of the key
+func (g *Guard) Succeed(key string) error {
+	return g.store.Reset(key)
+}
+
+// fail is the state after a failure at now, stores which can not run it atomically have to do the same
+func (p Policy) fail(state State, now time.Time) State {
+	// failures are forgotten once the key has been quiet for a lockout period
+	if now.Sub(state.LastFailure) > p.LockoutDuration {
+		state.Failures = 0
+	}
+	state.Failures++
+	state.LastFailure = now
+	if state.Failures >= p.Threshold {
+		state.LockedUntil = now.Add(p.LockoutDuration)
+		state.Failures = 0
+	}
+	return state
+}
+
+// delay is the exponential backoff after the given number of failures
+func (g *Guard) delay(failures int) time.Duration {
+	over := failures - g.policy.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	delay := float64(g.policy.BaseDelay) * math.Pow(2, float64(over-1))
+	if delay > float64(g.policy.MaxDelay) {
+		return g.policy.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+type memoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+//NewMemoryStore creates a store which keeps the state in process, it is lost on restart
+func NewMemoryStore() Store {
+	return &memoryStore{states: map[string]State{}}
+}
+
+func (m *memoryStore) Get(key string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[key], nil
+}
+
+func (m *memoryStore) Increment(key string, now time.Time, policy Policy) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := policy.fail(m.states[key], now)
+	m.states[key] = state
+	return state, nil
+}
+
+func (m *memoryStore) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, key)
+	return nil
+}
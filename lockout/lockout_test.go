@@ -0,0 +1,181 @@
+package lockout
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{
+	FreeAttempts:    2,
+	BaseDelay:       time.Second,
+	MaxDelay:        8 * time.Second,
+	Threshold:       6,
+	LockoutDuration: time.Hour,
+}
+
+// clock is a fake time source the tests move forward by hand
+type clock struct {
+	at time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.at
+}
+
+func newTestGuard(store Store) (*Guard, *clock) {
+	c := &clock{at: time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)}
+	guard := NewGuard(store, testPolicy)
+	guard.now = c.now
+	return guard, c
+}
+
+// retryAfter is the wait Check asks for, 0 when the key may attempt
+func retryAfter(t *testing.T, guard *Guard, key string) (time.Duration, bool) {
+	err := guard.Check(key)
+	if err == nil {
+		return 0, false
+	}
+	lockErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Check = %v, want a *Error", err)
+	}
+	return lockErr.RetryAfter, lockErr.Locked
+}
+
+func TestGuardBackoff(t *testing.T) {
+	guard, c := newTestGuard(NewMemoryStore())
+	tests := []struct {
+		failures int
+		wait     time.Duration
+	}{
+		// the free attempts do not slow down
+		{1, 0},
+		{2, 0},
+		// then the delay doubles with every failure up to the maximum
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+	}
+	failures := 0
+	for _, test := range tests {
+		for ; failures < test.failures; failures++ {
+			if err := guard.Fail("k"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		wait, locked := retryAfter(t, guard, "k")
+		if wait != test.wait || locked {
+			t.Errorf("after %d failures Check asks to wait %s (locked %v), want %s", test.failures, wait, locked, test.wait)
+		}
+	}
+	c.at = c.at.Add(4 * time.Second)
+	if err := guard.Check("k"); err != nil {
+		t.Errorf("Check once the backoff passed = %v", err)
+	}
+	if err := guard.Check("other"); err != nil {
+		t.Errorf("Check of another key = %v", err)
+	}
+}
+
+func TestGuardMaxDelay(t *testing.T) {
+	guard := NewGuard(NewMemoryStore(), Policy{FreeAttempts: 0, BaseDelay: time.Second, MaxDelay: 3 * time.Second, Threshold: 100, LockoutDuration: time.Hour})
+	for i := 0; i < 10; i++ {
+		if err := guard.Fail("k"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := guard.delay(10); got != 3*time.Second {
+		t.Fatalf("delay after 10 failures = %s, want the 3s maximum", got)
+	}
+}
+
+func TestGuardLockout(t *testing.T) {
+	guard, c := newTestGuard(NewMemoryStore())
+	for i := 0; i < testPolicy.Threshold; i++ {
+		if err := guard.Fail("k"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wait, locked := retryAfter(t, guard, "k")
+	if !locked || wait != time.Hour {
+		t.Fatalf("Check at the threshold = %s locked %v, want locked for 1h", wait, locked)
+	}
+	c.at = c.at.Add(59 * time.Minute)
+	if wait, locked := retryAfter(t, guard, "k"); !locked || wait != time.Minute {
+		t.Fatalf("Check before the lock expires = %s locked %v", wait, locked)
+	}
+	c.at = c.at.Add(time.Minute)
+	if err := guard.Check("k"); err != nil {
+		t.Fatalf("Check once the lock expired = %v", err)
+	}
+	// counting starts again after a lock, the next failure is a free one
+	if err := guard.Fail("k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check("k"); err != nil {
+		t.Fatalf("Check after the first failure past the lock = %v", err)
+	}
+}
+
+func TestGuardQuietPeriodForgets(t *testing.T) {
+	guard, c := newTestGuard(NewMemoryStore())
+	for i := 0; i < testPolicy.Threshold-1; i++ {
+		if err := guard.Fail("k"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.at = c.at.Add(testPolicy.LockoutDuration + time.Second)
+	if err := guard.Fail("k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, locked := retryAfter(t, guard, "k"); locked {
+		t.Fatal("failures older than a lockout period still counted")
+	}
+}
+
+func TestGuardSucceedResets(t *testing.T) {
+	guard, _ := newTestGuard(NewMemoryStore())
+	for i := 0; i < testPolicy.Threshold-1; i++ {
+		if err := guard.Fail("k"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := guard.Succeed("k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check("k"); err != nil {
+		t.Fatalf("Check after a success = %v", err)
+	}
+	// the count starts over, so one more failure is far from the threshold
+	if err := guard.Fail("k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check("k"); err != nil {
+		t.Fatalf("Check after a success and one failure = %v", err)
+	}
+}
+
+// TestGuardParallelFailures makes sure failures arriving at the same time all count
+func TestGuardParallelFailures(t *testing.T) {
+	store := NewMemoryStore()
+	guard := NewGuard(store, Policy{Threshold: 1000, LockoutDuration: time.Hour})
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := guard.Fail("k"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	state, err := store.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Failures != 200 {
+		t.Fatalf("recorded %d of 200 parallel failures", state.Failures)
+	}
+}
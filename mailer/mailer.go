@@ -0,0 +1,132 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"todo/constants"
+)
+
+// Mailer sends plain text mails to users
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+//NewMailer creates the mailer selected by the MAILER environment variable, it defaults to logging mails
+func NewMailer() (Mailer, error) {
+	from := os.Getenv(constants.MailFromEnv)
+	if from == "" {
+		from = "todo@localhost"
+	}
+	switch kind := os.Getenv(constants.MailerEnv); kind {
+	case "", "log":
+		return NewLogMailer(), nil
+	case "file":
+		path := os.Getenv(constants.MailerFileEnv)
+		if path == "" {
+			return nil, fmt.Errorf("%s is required for the file mailer", constants.MailerFileEnv)
+		}
+		return NewFileMailer(path), nil
+	case "smtp":
+		addr := os.Getenv(constants.SMTPAddrEnv)
+		if addr == "" {
+			return nil, fmt.Errorf("%s is required for the smtp mailer", constants.SMTPAddrEnv)
+		}
+		return NewSMTPMailer(addr, os.Getenv(constants.SMTPUserEnv), os.Getenv(constants.SMTPPasswordEnv), from), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", kind)
+	}
+}
+
+type logMailer struct{}
+
+//NewLogMailer creates a mailer which only writes the mails to the server log
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+func (m logMailer) Send(to, subject, body string) error {
+	log.Printf("mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+type fileMailer struct {
+	path string
+	mu   *sync.Mutex
+}
+
+//NewFileMailer creates a mailer which appends every mail to a file, so the mails can be read in tests
+func NewFileMailer(path string) Mailer {
+	return fileMailer{path: path, mu: &sync.Mutex{}}
+}
+
+func (m fileMailer) Send(to, subject, body string) error {
+	if err := checkAddress(to); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC1123Z), to, headerValue(subject), body)
+	return err
+}
+
+type smtpMailer struct {
+	addr     string
+	user     string
+	password string
+	from     string
+}
+
+//NewSMTPMailer creates a mailer which delivers mails through an smtp server
+func NewSMTPMailer(addr, user, password, from string) Mailer {
+	return smtpMailer{addr: addr, user: user, password: password, from: from}
+}
+
+func (m smtpMailer) Send(to, subject, body string) error {
+	msg, err := m.message(to, subject, body)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.user != "" {
+		auth = smtp.PlainAuth("", m.user, m.password, strings.Split(m.addr, ":")[0])
+	}
+	return smtp.SendMail(m.addr, auth, m.from, []string{to}, msg)
+}
+
+// message builds the mail, the subject can hold todo titles so it is kept on one line and encoded
+func (m smtpMailer) message(to, subject, body string) ([]byte, error) {
+	if err := checkAddress(m.from); err != nil {
+		return nil, err
+	}
+	if err := checkAddress(to); err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		m.from, to, mime.QEncoding.Encode("utf-8", headerValue(subject)), body)
+	return []byte(msg), nil
+}
+
+// headerValue puts a value on one header line, line breaks in it could start new headers or the body
+func headerValue(value string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+}
+
+// checkAddress refuses addresses which would break out of their header
+func checkAddress(address string) error {
+	if strings.ContainsAny(address, "\r\n") {
+		return fmt.Errorf("invalid mail address %q", address)
+	}
+	return nil
+}
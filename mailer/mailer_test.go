@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSMTPMessageHeaders(t *testing.T) {
+	m := smtpMailer{from: "todo@example.com"}
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"Reminder: buy milk", "Subject: Reminder: buy milk\r\n"},
+		// a title can not add headers or start the body
+		{"Reminder: x\r\nBcc: victim@example.com", "Subject: Reminder: x Bcc: victim@example.com\r\n"},
+		{"Reminder: x\n\nfake body", "Subject: Reminder: x  fake body\r\n"},
+		{"Assigned to you: café", "Subject: =?utf-8?q?Assigned_to_you:_caf=C3=A9?=\r\n"},
+	}
+	for _, test := range tests {
+		msg, err := m.message("a@example.com", test.subject, "body")
+		if err != nil {
+			t.Fatal(err)
+		}
+		headers := strings.SplitN(string(msg), "\r\n\r\n", 2)[0] + "\r\n"
+		if !strings.Contains(headers, test.want) {
+			t.Errorf("subject %q gave the headers %q, want %q", test.subject, headers, test.want)
+		}
+		// From, To, Subject and Content-Type
+		if strings.Count(headers, "\r\n") != 4 {
+			t.Errorf("subject %q changed the headers: %q", test.subject, headers)
+		}
+	}
+}
+
+func TestSMTPMessageAddresses(t *testing.T) {
+	m := smtpMailer{from: "todo@example.com"}
+	for _, to := range []string{"a@example.com\r\nBcc: x@example.com", "a@example.com\nX: y"} {
+		if _, err := m.message(to, "subject", "body"); err == nil {
+			t.Errorf("message to %q was built", to)
+		}
+	}
+	m = smtpMailer{from: "todo@example.com\nBcc: x@example.com"}
+	if _, err := m.message("a@example.com", "subject", "body"); err == nil {
+		t.Error("message from an address with a line break was built")
+	}
+}
@@ -15,12 +15,15 @@ import (
 )
 
 func main() {
-	ginRouter := router.SetupRouter()
+	ginRouter, reminders, trash := router.SetupRouter()
 	srv := &http.Server{
 		Addr:    ":8080",
 		Handler: ginRouter,
 	}
-	graceful := make(chan os.Signal)
+	reminders.Start()
+	trash.Start()
+	stopped := make(chan struct{})
+	graceful := make(chan os.Signal, 1)
 	signal.Notify(graceful, syscall.SIGINT)
 	signal.Notify(graceful, syscall.SIGTERM)
 	go func() {
@@ -28,14 +31,23 @@ func main() {
 		log.Println("Shutting down server...")
 		ctx, cancelFunc := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancelFunc()
+		defer close(stopped)
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Fatalf("Could not do graceful shutdown: %v\n", err)
 		}
+		// let the reminders being delivered finish before exiting
+		if err := reminders.Stop(ctx); err != nil {
+			log.Printf("Could not stop the reminder scheduler: %v\n", err)
+		}
+		if err := trash.Stop(ctx); err != nil {
+			log.Printf("Could not stop the trash purger: %v\n", err)
+		}
 	}()
 
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatalf("Could not do graceful shutdown: %v\n", err)
 	}
 
+	<-stopped
 	log.Println("Server gracefully stopped...")
 }
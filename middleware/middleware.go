@@ -1,15 +1,27 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"strings"
+	"time"
 	"todo/auth"
+	"todo/constants"
+	"todo/database"
+	"todo/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 //Middleware function to authorize the user
-func TokenAuthMiddleware() gin.HandlerFunc {
+func TokenAuthMiddleware(todoDb database.TodoDatabase) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		//personal access tokens are opaque, they are looked up instead of verified
+		token := auth.ExtractToken(c.Request)
+		if strings.HasPrefix(token, constants.PersonalAccessTokenPrefix) {
+			personalAccessTokenAuth(c, todoDb, token)
+			return
+		}
 		//check for token validity
 		err := auth.TokenValid(c.Request)
 		if err != nil {
@@ -24,9 +36,119 @@ func TokenAuthMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		// set the user-id in gin context
+		//extract session id from token and reject it if the session is no longer live
+		sessionId, err := auth.ExtractTokenSessionID(c.Request)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, "You need to be authorized to access this route")
+			c.Abort()
+			return
+		}
+		session, err := todoDb.GetSessionById(sessionId)
+		if err != nil || session.UserId != id || session.Revoked || session.ExpiresAt < time.Now().Unix() {
+			c.JSON(http.StatusUnauthorized, "Session has been revoked, please sign in again")
+			c.Abort()
+			return
+		}
+		role, err := auth.ExtractTokenRole(c.Request)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, "Internal server error")
+			c.Abort()
+			return
+		}
+		// set the user-id, session-id and user-role in gin context
 		c.Set("user-id", id)
+		c.Set("session-id", sessionId)
+		c.Set("user-role", role)
+		c.Next()
+	}
+
+}
+
+func personalAccessTokenAuth(c *gin.Context, todoDb database.TodoDatabase, token string) {
+	pat, err := todoDb.GetPersonalAccessTokenByHash(auth.HashToken(token))
+	if err != nil || pat.Revoked || (pat.ExpiresAt != 0 && pat.ExpiresAt < time.Now().Unix()) {
+		c.JSON(http.StatusUnauthorized, "You need to be authorized to access this route")
+		c.Abort()
+		return
+	}
+	if err := todoDb.TouchPersonalAccessToken(pat.ID, time.Now().Unix()); err != nil {
+		log.Println(err)
+	}
+	// set the user-id and the granted scopes in gin context
+	c.Set("user-id", pat.UserId)
+	c.Set("token-scopes", pat.Scopes)
+	c.Next()
+}
+
+//RequireScope lets personal access tokens through only when they were granted the scope,
+//signed in users have every scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, isToken := c.Get("token-scopes")
+		if !isToken {
+			c.Next()
+			return
+		}
+		for _, granted := range scopes.([]string) {
+			if granted == scope {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, "Token is missing the "+scope+" scope")
+		c.Abort()
+	}
+}
+
+//RequireRole lets only users with one of the roles through, it runs after TokenAuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("user-role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, "You are not allowed to access this route")
+		c.Abort()
+	}
+}
+
+//SessionOnly keeps personal access tokens out of routes that need a signed in user
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isToken := c.Get("token-scopes"); isToken {
+			c.JSON(http.StatusForbidden, "Personal access tokens can not access this route")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
+}
 
+//VerifiedEmailMiddleware blocks unverified users from writing once their grace period is over,
+//it only does so when REQUIRE_EMAIL_VERIFICATION is switched on
+func VerifiedEmailMiddleware(todoDb database.TodoDatabase) gin.HandlerFunc {
+	required := utils.EnvBool(constants.RequireEmailVerificationEnv, false)
+	grace := utils.EnvDuration(constants.EmailVerificationGraceEnv, constants.EmailVerificationGrace)
+	return func(c *gin.Context) {
+		if !required {
+			c.Next()
+			return
+		}
+		id, _ := c.Get("user-id")
+		user, err := todoDb.GetUserById(id.(int))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, "Internal server error")
+			c.Abort()
+			return
+		}
+		if !user.EmailVerified && time.Unix(user.CreatedAt, 0).Add(grace).Before(time.Now()) {
+			c.JSON(http.StatusForbidden, "Please verify your email address to continue")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
 }
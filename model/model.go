@@ -1,14 +1,55 @@
 package model
 
 import (
+	"encoding/json"
+	"time"
+
+	"todo/constants"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	ID            int    `json:"id"`
+	Name          string `json:"name" binding:"required"`
+	Email         string `json:"email" binding:"required"`
+	Password      string `json:"password" binding:"required"`
+	EmailVerified bool   `json:"emailVerified"`
+	CreatedAt     int64  `json:"createdAt"`
+	Role          string `json:"role"`
+	Disabled      bool   `json:"disabled"`
+	// MustResetPassword is set when an admin forces a password reset, signin is refused until then
+	MustResetPassword bool `json:"mustResetPassword"`
+	// TimeZone is the IANA time zone due dates are shown in
+	TimeZone string `json:"timeZone"`
+}
+
+// PublicUser is a user as it is sent to clients, without the password hash
+type PublicUser struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"emailVerified"`
+	CreatedAt         int64  `json:"createdAt"`
+	Role              string `json:"role"`
+	Disabled          bool   `json:"disabled"`
+	MustResetPassword bool   `json:"mustResetPassword"`
+	TimeZone          string `json:"timeZone"`
+}
+
+// Public returns the user without the password hash
+func (u User) Public() PublicUser {
+	return PublicUser{
+		ID:                u.ID,
+		Name:              u.Name,
+		Email:             u.Email,
+		EmailVerified:     u.EmailVerified,
+		CreatedAt:         u.CreatedAt,
+		Role:              u.Role,
+		Disabled:          u.Disabled,
+		MustResetPassword: u.MustResetPassword,
+		TimeZone:          u.TimeZone,
+	}
 }
 
 type UserLogin struct {
@@ -16,43 +57,602 @@ type UserLogin struct {
 	Password string `json:"password" binding:"required"`
 }
 type Todo struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"  binding:"required"`
-	Description string `json:"description"`
-	DueDate     string `json:"dueDate"  binding:"required"`
-	Priority    string `json:"priority"`
-	Completed   bool   `json:"completed"`
-	UserId      int    `json:"userId"`
-	Category    int    `json:"category"`
+	ID          int     `json:"id"`
+	Title       string  `json:"title"  binding:"required"`
+	Description string  `json:"description"`
+	DueDate     DueDate `json:"dueDate"  binding:"required"`
+	// AllDay todos are due on a date rather than at a moment, their due date is a plain date
+	AllDay   bool   `json:"allDay"`
+	Priority string `json:"priority"`
+	// Status is the state of the todo in the workflow of its category,
+	// Completed follows from it and is true for the final states
+	Status    string `json:"status"`
+	Completed bool   `json:"completed"`
+	// UserId is the user who created the todo, AssigneeId the one who is to do it, nil while nobody is
+	UserId     int  `json:"userId"`
+	AssigneeId *int `json:"assigneeId"`
+	Category   int  `json:"category"`
+	// ParentId makes the todo a subtask, nil for a top level todo
+	ParentId *int `json:"parentId"`
+	// AutoComplete completes the todo once all its subtasks are done
+	AutoComplete bool    `json:"autoComplete"`
+	Children     *[]Todo `json:"children,omitempty"`
+	// Tags are the ids of the tags on the todo
+	Tags []int `json:"tags"`
+	// Recurrence is an RRULE like FREQ=WEEKLY;BYDAY=MO, completing the todo creates the next occurrence
+	Recurrence string `json:"recurrence"`
+	// RecurrenceAnchor is due (default) to count the next occurrence from the due date, or completion
+	RecurrenceAnchor string `json:"recurrenceAnchor"`
+	// SeriesId is the first todo of the series for every later occurrence
+	SeriesId   *int  `json:"seriesId"`
+	Occurrence int   `json:"occurrence"`
+	CreatedAt  int64 `json:"createdAt"`
+	// Blocked is set when a todo it depends on is still open, Ready for open todos which are not blocked.
+	// Both are only filled in when asked for.
+	Blocked *bool `json:"blocked,omitempty"`
+	Ready   *bool `json:"ready,omitempty"`
+	// DeletedAt is when the todo was moved to the trash, only set in the trash listing
+	DeletedAt *int64 `json:"deletedAt,omitempty"`
 }
 type MarkTodo struct {
 	ID        int  `json:"id" binding:"required"`
 	Completed bool `json:"completed" `
+	// Force completes the todo even though todos it depends on are still open
+	Force bool `json:"force"`
 }
 
 type Category struct {
 	ID     int    `json:"id"`
 	Name   string `json:"name" binding:"required"`
 	UserId int    `json:"userId"`
+	// Role is owner for the categories of the user and the member role for the ones shared with it
+	Role string `json:"role,omitempty"`
+	// DeletedAt is when the category was moved to the trash, only set in the trash listing
+	DeletedAt *int64 `json:"deletedAt,omitempty"`
+}
+
+type Tag struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name" binding:"required"`
+	Color  string `json:"color"`
+	UserId int    `json:"userId"`
+}
+
+type EditTag struct {
+	ID    int    `json:"id" binding:"required"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Reminder fires at RemindAt, or OffsetMinutes before the due date of its todo
+type Reminder struct {
+	ID            int        `json:"id"`
+	TodoId        int        `json:"todoId"`
+	UserId        int        `json:"userId"`
+	RemindAt      *time.Time `json:"remindAt,omitempty"`
+	OffsetMinutes *int       `json:"offsetMinutes,omitempty"`
+	FireAt        *time.Time `json:"fireAt"`
+	Status        string     `json:"status"`
+	SentAt        int64      `json:"sentAt"`
+	LastError     string     `json:"lastError"`
+	CreatedAt     int64      `json:"createdAt"`
+}
+
+type NewReminder struct {
+	RemindAt      *time.Time `json:"remindAt"`
+	OffsetMinutes *int       `json:"offsetMinutes"`
 }
 
 type Id struct {
 	ID int `json:"id"`
 }
 
-type SignInResponse struct {
-	Token string
-	User  User
+type UpdateProfile struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	TimeZone string `json:"timeZone"`
+	// Password is the current password, it is required to change the email
+	Password string `json:"password"`
 }
-type EditTodo struct {
+
+type ChangePassword struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+type DeleteAccount struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type Export struct {
+	Version    int           `json:"version"`
+	ExportedAt int64         `json:"exportedAt"`
+	Profile    ExportProfile `json:"profile"`
+	Categories []Category    `json:"categories"`
+	Workflows  []Workflow    `json:"workflows"`
+	Tags       []Tag         `json:"tags"`
+	Todos      []Todo        `json:"todos"`
+	// Reminders are the ones the user set on its todos
+	Reminders  []Reminder  `json:"reminders"`
+	SmartLists []SmartList `json:"smartLists"`
+	// Dependencies are the ones between the exported todos
+	Dependencies []Dependency `json:"dependencies"`
+	// Comments are the ones the user wrote on the exported todos, without mentions
+	Comments []Comment `json:"comments"`
+	// Attachments only describe the files the user uploaded to the exported todos,
+	// the files are not part of the export and are not imported
+	Attachments []Attachment `json:"attachments"`
+}
+
+type ExportProfile struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type ImportResult struct {
+	Categories   int `json:"categories"`
+	Tags         int `json:"tags"`
+	Todos        int `json:"todos"`
+	Reminders    int `json:"reminders"`
+	SmartLists   int `json:"smartLists"`
+	Dependencies int `json:"dependencies"`
+	Comments     int `json:"comments"`
+}
+
+// TodoQuery sorts, filters and pages the todo listing, zero fields do not filter
+type TodoQuery struct {
+	// Sort is due (default), priority, created or title
+	Sort string
+	Desc bool
+	// Limit is the page size, Cursor continues after the previous page
+	Limit     int
+	Cursor    string
+	Completed *bool
+	Category  *int
+	Priority  string
+	// DueBefore lists todos due before it, DueAfter the ones due on or after it
+	DueBefore *DueDate
+	DueAfter  *DueDate
+	// Overdue lists the incomplete todos whose due date passed
+	Overdue bool
+	// Filter is an expression in the smart list query language
+	Filter   string
+	TagIds   []int
+	MatchAll bool
+	// Dependencies fills in the blocked and ready flags of the todos
+	Dependencies bool
+	// AssigneeId lists the todos assigned to the user, CreatorId the ones created by the user
+	AssigneeId *int
+	CreatorId  *int
+}
+
+// DueBound is a due date limit resolved for both kinds of todos: At for todos due at a moment,
+// Date (midnight UTC) for all day todos, which are compared by their date
+type DueBound struct {
+	At   time.Time
+	Date time.Time
+}
+
+// TodoPage is one page of the todo listing, NextCursor is empty on the last page
+type TodoPage struct {
+	Todos      *[]Todo
+	NextCursor string
+	Total      int
+}
+
+// SearchResult is a todo matching a search, the highlight and snippet mark the matching words
+type SearchResult struct {
+	Todo           Todo    `json:"todo"`
+	TitleHighlight string  `json:"titleHighlight"`
+	Snippet        string  `json:"snippet"`
+	Score          float64 `json:"score"`
+}
+
+// SmartList is a named filter expression, see the filter package for the query language
+type SmartList struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name" binding:"required"`
+	Query  string `json:"query" binding:"required"`
+	UserId int    `json:"userId"`
+}
+
+type EditSmartList struct {
+	ID    int    `json:"id" binding:"required"`
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Workflow is the set of states of the todos of a user or a category and the transitions allowed
+// between them. New todos start in the first state, todos in a final state count as completed.
+type Workflow struct {
+	// Category is 0 for the workflow of the user, which applies to categories without one
+	Category    int                  `json:"category"`
+	States      []WorkflowState      `json:"states" binding:"required"`
+	Transitions []WorkflowTransition `json:"transitions"`
+	// Default is set on the built-in workflow used when neither the category nor the user have one
+	Default bool `json:"default"`
+}
+
+type WorkflowState struct {
+	Name  string `json:"name"`
+	Final bool   `json:"final"`
+}
+
+type WorkflowTransition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ChangeStatus struct {
+	ID     int    `json:"id" binding:"required"`
+	Status string `json:"status" binding:"required"`
+	// Force moves the todo to a final state even though todos it depends on are still open
+	Force bool `json:"force"`
+}
+
+// StatusChange is a transition a todo went through, a todo starts in the state it was created in
+type StatusChange struct {
+	ID        int    `json:"id"`
+	TodoId    int    `json:"todoId"`
+	UserId    int    `json:"userId"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	ChangedAt int64  `json:"changedAt"`
+}
+
+// AssignTodo hands a todo to another user, a nil AssigneeId leaves it to nobody
+type AssignTodo struct {
+	ID         int  `json:"id" binding:"required"`
+	AssigneeId *int `json:"assigneeId"`
+}
+
+// Assignment is a change of the assignee of a todo, From and To are nil for nobody
+type Assignment struct {
+	ID        int   `json:"id"`
+	TodoId    int   `json:"todoId"`
+	UserId    int   `json:"userId"`
+	From      *int  `json:"from"`
+	To        *int  `json:"to"`
+	ChangedAt int64 `json:"changedAt"`
+}
+
+// Comment is a note on a todo, Body is markdown and Mentions are the ids of the users it @mentions
+type Comment struct {
+	ID        int    `json:"id"`
+	TodoId    int    `json:"todoId" binding:"required"`
+	UserId    int    `json:"userId"`
+	UserName  string `json:"userName"`
+	Body      string `json:"body" binding:"required"`
+	Mentions  []int  `json:"mentions"`
+	CreatedAt int64  `json:"createdAt"`
+	// UpdatedAt is 0 until the comment is edited
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+type EditComment struct {
+	ID   int    `json:"id" binding:"required"`
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentPage is one page of the comments on a todo, Total counts all of them
+type CommentPage struct {
+	Comments *[]Comment
+	Total    int
+}
+
+// TodoEvent is a change of a todo no other history keeps, like a move to another category.
+// From and To are the names of what changed at the time.
+type TodoEvent struct {
+	ID        int
+	TodoId    int
+	UserId    int
+	Kind      string
+	From      string
+	To        string
+	CreatedAt int64
+}
+
+// Activity is an entry of the activity feed of a todo: its creation, a comment, a status change,
+// an assignment or a todo event. From and To are states, user or category names.
+type Activity struct {
+	Kind      string   `json:"kind"`
+	ID        int      `json:"id"`
+	UserId    int      `json:"userId"`
+	UserName  string   `json:"userName"`
+	Summary   string   `json:"summary"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Comment   *Comment `json:"comment,omitempty"`
+	CreatedAt int64    `json:"createdAt"`
+}
+
+// ActivityPage is one page of the activity feed of a todo, Total counts all entries
+type ActivityPage struct {
+	Items *[]Activity
+	Total int
+}
+
+// Attachment is a file attached to a todo, its content lives in the blob store under Key
+type Attachment struct {
 	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	DueDate     string `json:"dueDate"`
-	Priority    string `json:"priority"`
-	Completed   *bool  `json:"completed"`
+	TodoId      int    `json:"todoId"`
 	UserId      int    `json:"userId"`
-	Category    int    `json:"category"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	Key         string `json:"-"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// StorageUsage is how much of the attachment quota a user has used, in bytes
+type StorageUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
+
+// Trash lists what the user deleted and can still restore until it is purged at PurgeAt.
+// A trashed todo stands for its subtasks trashed with it.
+type Trash struct {
+	Todos      []TrashedTodo     `json:"todos"`
+	Categories []TrashedCategory `json:"categories"`
+}
+
+type TrashedTodo struct {
+	Todo
+	PurgeAt int64 `json:"purgeAt"`
+}
+
+type TrashedCategory struct {
+	Category
+	PurgeAt int64 `json:"purgeAt"`
+}
+
+// Dependency says the todo can not start until the blocker is done
+type Dependency struct {
+	TodoId    int   `json:"todoId" binding:"required"`
+	BlockerId int   `json:"blockerId" binding:"required"`
+	UserId    int   `json:"userId"`
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// TodoDependencies are the todos a todo waits for and the ones waiting for it
+type TodoDependencies struct {
+	Blockers   []Todo `json:"blockers"`
+	Dependents []Todo `json:"dependents"`
+}
+
+// CategoryMember is a user a category is shared with, Role is viewer, editor or admin
+type CategoryMember struct {
+	CategoryId int    `json:"categoryId"`
+	UserId     int    `json:"userId"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	InvitedBy  int    `json:"invitedBy"`
+	CreatedAt  int64  `json:"createdAt"`
+}
+
+type InviteMember struct {
+	CategoryId int    `json:"categoryId" binding:"required"`
+	Email      string `json:"email" binding:"required"`
+	Role       string `json:"role" binding:"required"`
+}
+
+type EditMember struct {
+	CategoryId int    `json:"categoryId" binding:"required"`
+	UserId     int    `json:"userId" binding:"required"`
+	Role       string `json:"role" binding:"required"`
+}
+
+type InvalidDueDate struct {
+	TodoId  int    `json:"todoId"`
+	UserId  int    `json:"userId"`
+	DueDate string `json:"dueDate"`
+}
+
+type UserRole struct {
+	ID   int    `json:"id" binding:"required"`
+	Role string `json:"role" binding:"required"`
+}
+
+type Stats struct {
+	Users          int `json:"users"`
+	VerifiedUsers  int `json:"verifiedUsers"`
+	DisabledUsers  int `json:"disabledUsers"`
+	Admins         int `json:"admins"`
+	Todos          int `json:"todos"`
+	CompletedTodos int `json:"completedTodos"`
+	Categories     int `json:"categories"`
+	ActiveSessions int `json:"activeSessions"`
+}
+
+type SignInResponse struct {
+	Token             string
+	RefreshToken      string
+	User              PublicUser
+	TwoFactorRequired bool
+	ChallengeToken    string
+}
+
+type TwoFactorSignIn struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type UserTOTP struct {
+	UserId       int    `json:"userId"`
+	Secret       string `json:"-"`
+	Confirmed    bool   `json:"confirmed"`
+	LastUsedStep int64  `json:"-"`
+	CreatedAt    int64  `json:"createdAt"`
+}
+
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type TOTPCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string
+}
+
+type Session struct {
+	ID               int    `json:"id"`
+	UserId           int    `json:"userId"`
+	RefreshTokenHash string `json:"-"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	Revoked          bool   `json:"revoked"`
+	CreatedAt        int64  `json:"createdAt"`
+}
+
+type PasswordReset struct {
+	ID        int    `json:"id"`
+	UserId    int    `json:"userId"`
+	TokenHash string `json:"-"`
+	ExpiresAt int64  `json:"expiresAt"`
+	Used      bool   `json:"used"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// EmailVerification proves the user owns Email, the address the token was sent to
+type EmailVerification struct {
+	ID        int    `json:"id"`
+	UserId    int    `json:"userId"`
+	Email     string `json:"email"`
+	TokenHash string `json:"-"`
+	ExpiresAt int64  `json:"expiresAt"`
+	Used      bool   `json:"used"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type PersonalAccessToken struct {
+	ID         int      `json:"id"`
+	UserId     int      `json:"userId"`
+	Name       string   `json:"name"`
+	TokenHash  string   `json:"-"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  int64    `json:"expiresAt"`
+	LastUsedAt int64    `json:"lastUsedAt"`
+	Revoked    bool     `json:"revoked"`
+	CreatedAt  int64    `json:"createdAt"`
+}
+
+type NewPersonalAccessToken struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expiresInDays"`
+}
+
+type PersonalAccessTokenResponse struct {
+	Token               string
+	PersonalAccessToken PersonalAccessToken
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string
+	RefreshToken string
+}
+type EditTodo struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	DueDate     *DueDate `json:"dueDate"`
+	AllDay      *bool    `json:"allDay"`
+	Priority    string   `json:"priority"`
+	Completed   *bool    `json:"completed"`
+	UserId      int      `json:"userId"`
+	Category    int      `json:"category"`
+	// ParentId moves the todo below another one, 0 makes it a top level todo again
+	ParentId     *int  `json:"parentId"`
+	AutoComplete *bool `json:"autoComplete"`
+	// Tags replaces the tags of the todo when given, an empty list removes them all
+	Tags *[]int `json:"tags"`
+	// Recurrence replaces the rule when given, an empty rule stops the todo from recurring
+	Recurrence       *string `json:"recurrence"`
+	RecurrenceAnchor *string `json:"recurrenceAnchor"`
+}
+
+// DueDate is when a todo is due, read from an RFC 3339 timestamp or a full date like 2006-01-02
+type DueDate struct {
+	time.Time
+	// DateOnly is set when the due date is a plain date, it is written back the same way
+	DateOnly bool
+}
+
+func (d *DueDate) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &FieldError{Field: "dueDate", Message: "must be a string"}
+	}
+	dueDate, err := ParseDueDate("dueDate", value)
+	if err != nil {
+		return err
+	}
+	*d = dueDate
+	return nil
+}
+
+// ParseDueDate reads an RFC 3339 timestamp or a full date, an empty value is the zero due date.
+// field names the input in the returned FieldError.
+func ParseDueDate(field, value string) (DueDate, error) {
+	if value == "" {
+		return DueDate{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return DueDate{Time: t}, nil
+	}
+	if t, err := time.Parse(constants.DateLayout, value); err == nil {
+		return DueDate{Time: t, DateOnly: true}, nil
+	}
+	return DueDate{}, &FieldError{Field: field, Message: "must be an RFC 3339 timestamp like 2006-01-02T15:04:05+07:00 or a date like 2006-01-02"}
+}
+
+func (d DueDate) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// String formats the due date the way it is read, empty when there is none
+func (d DueDate) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	if d.DateOnly {
+		return d.Format(constants.DateLayout)
+	}
+	return d.Format(time.RFC3339)
+}
+
+// FieldError is an invalid value in a request, reported with the json name of the field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"error"`
+	// Position points into the value for errors in query expressions, counting characters from 1
+	Position int `json:"position,omitempty"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
 }
 
 // Hash the password before saving into database
@@ -74,4 +674,12 @@ func (u *User) HashBeforeSave() error {
 }
 func (u *User) Prepare() {
 	u.ID = 0
+	u.EmailVerified = false
+	u.CreatedAt = time.Now().Unix()
+	u.Role = constants.RoleUser
+	u.Disabled = false
+	u.MustResetPassword = false
+	if u.TimeZone == "" {
+		u.TimeZone = constants.DefaultTimeZone
+	}
 }
@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/mailer"
+)
+
+// Notification is a reminder which became due, or a todo assigned to the user when Kind is assignment
+type Notification struct {
+	Kind       string    `json:"kind"`
+	ReminderId int       `json:"reminderId,omitempty"`
+	TodoId     int       `json:"todoId"`
+	Title      string    `json:"title"`
+	DueDate    string    `json:"dueDate"`
+	UserId     int       `json:"userId"`
+	UserName   string    `json:"userName"`
+	UserEmail  string    `json:"userEmail"`
+	FireAt     time.Time `json:"fireAt"`
+	// AssignedBy is the name of the user who assigned the todo
+	AssignedBy string `json:"assignedBy,omitempty"`
+}
+
+// Notifier delivers due reminders and assignments, every notification goes to each configured notifier
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+//NewNotifiers creates the notifiers listed in REMINDER_NOTIFIERS, it defaults to logging reminders
+func NewNotifiers(mail mailer.Mailer) ([]Notifier, error) {
+	names := os.Getenv(constants.ReminderNotifiersEnv)
+	if names == "" {
+		names = "log"
+	}
+	return newNotifiers(names, constants.ReminderWebhookURLEnv, constants.ReminderWebhookSecretEnv, mail)
+}
+
+//NewAssignmentNotifiers creates the notifiers listed in ASSIGNMENT_NOTIFIERS, without it nobody is
+//notified about assignments
+func NewAssignmentNotifiers(mail mailer.Mailer) ([]Notifier, error) {
+	names := os.Getenv(constants.AssignmentNotifiersEnv)
+	if names == "" {
+		return nil, nil
+	}
+	return newNotifiers(names, constants.AssignmentWebhookURLEnv, constants.AssignmentWebhookSecretEnv, mail)
+}
+
+// newNotifiers creates the notifiers in the comma separated names, the webhook one is configured
+// by the environment variables urlEnv and secretEnv
+func newNotifiers(names, urlEnv, secretEnv string, mail mailer.Mailer) ([]Notifier, error) {
+	var notifiers []Notifier
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier())
+		case "email":
+			notifiers = append(notifiers, NewMailNotifier(mail))
+		case "webhook":
+			url := os.Getenv(urlEnv)
+			if url == "" {
+				return nil, fmt.Errorf("%s is required for the webhook notifier", urlEnv)
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(url, os.Getenv(secretEnv)))
+		default:
+			return nil, fmt.Errorf("unknown notifier %q", name)
+		}
+	}
+	return notifiers, nil
+}
+
+type logNotifier struct{}
+
+//NewLogNotifier creates a notifier which only writes reminders to the server log
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (n logNotifier) Name() string {
+	return "log"
+}
+
+func (n logNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Kind == constants.NotificationAssignment {
+		log.Printf("todo %d assigned to user %d by %s: %s", notification.TodoId, notification.UserId, notification.AssignedBy, notification.Title)
+		return nil
+	}
+	log.Printf("reminder %d for user %d: %s is due %s", notification.ReminderId, notification.UserId, notification.Title, notification.DueDate)
+	return nil
+}
+
+type mailNotifier struct {
+	mailer mailer.Mailer
+}
+
+//NewMailNotifier creates a notifier which mails the reminder to the owner of the todo, and the
+//assignment to the new assignee
+func NewMailNotifier(mail mailer.Mailer) Notifier {
+	return mailNotifier{mailer: mail}
+}
+
+func (n mailNotifier) Name() string {
+	return "email"
+}
+
+func (n mailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Kind == constants.NotificationAssignment {
+		body := fmt.Sprintf("Hi %s,\n\n%s assigned the todo \"%s\" to you", notification.UserName, notification.AssignedBy, notification.Title)
+		if notification.DueDate != "" {
+			body += ", it is due " + notification.DueDate
+		}
+		return n.mailer.Send(notification.UserEmail, "Assigned to you: "+notification.Title, body+".")
+	}
+	body := fmt.Sprintf("Hi %s,\n\nthis is a reminder for your todo \"%s\"", notification.UserName, notification.Title)
+	if notification.DueDate != "" {
+		body += ", it is due " + notification.DueDate
+	}
+	return n.mailer.Send(notification.UserEmail, "Reminder: "+notification.Title, body+".")
+}
+
+type webhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+//NewWebhookNotifier creates a notifier which posts the notification as json to the url, with a secret the body
+//is signed with HMAC-SHA256 in the X-Todo-Signature header
+func NewWebhookNotifier(url, secret string) Notifier {
+	return webhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n webhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set("X-Todo-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", res.Status)
+	}
+	return nil
+}
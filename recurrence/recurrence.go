@@ -0,0 +1,294 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of a rule
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// maxPeriods bounds the search for the next occurrence, so a rule that can never match does not loop forever
+const maxPeriods = 1000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Weekday is a BYDAY entry, Ordinal picks the nth weekday of the month (negative counts from the end), 0 means every one
+type Weekday struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// Rule is the supported subset of an iCalendar RRULE: FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []Weekday
+	ByMonthDay []int
+	// Count is the number of occurrences in the series, 0 for no limit
+	Count int
+	// Until is the last date an occurrence may fall on, zero for no limit
+	Until time.Time
+}
+
+// Parse reads a rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", an optional "RRULE:" prefix is ignored
+func Parse(rule string) (*Rule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(rule)), "RRULE:")
+	r := Rule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 || pair[1] == "" {
+			return nil, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		name, value := pair[0], pair[1]
+		switch name {
+		case "FREQ":
+			switch value {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = value
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, errors.New("recurrence interval must be a positive number")
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, errors.New("recurrence count must be a positive number")
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, err := parseWeekday(day)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid recurrence month day %q", day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence part %q", name)
+		}
+	}
+	if r.Freq == "" {
+		return nil, errors.New("recurrence needs a FREQ")
+	}
+	if r.Count != 0 && !r.Until.IsZero() {
+		return nil, errors.New("recurrence can not have both COUNT and UNTIL")
+	}
+	if r.Freq == Yearly && (len(r.ByDay) != 0 || len(r.ByMonthDay) != 0) {
+		return nil, errors.New("BYDAY and BYMONTHDAY are not supported for yearly recurrence")
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Ordinal != 0 && r.Freq != Monthly {
+			return nil, errors.New("numbered BYDAY entries are only supported for monthly recurrence")
+		}
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) != 0 {
+		return nil, errors.New("BYMONTHDAY is not supported for weekly recurrence")
+	}
+	return &r, nil
+}
+
+func parseWeekday(day string) (Weekday, error) {
+	day = strings.TrimSpace(day)
+	if len(day) < 2 {
+		return Weekday{}, fmt.Errorf("invalid recurrence weekday %q", day)
+	}
+	weekday, found := weekdays[day[len(day)-2:]]
+	if !found {
+		return Weekday{}, fmt.Errorf("invalid recurrence weekday %q", day)
+	}
+	ordinal := 0
+	if prefix := day[:len(day)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return Weekday{}, fmt.Errorf("invalid recurrence weekday %q", day)
+		}
+		ordinal = n
+	}
+	return Weekday{Ordinal: ordinal, Day: weekday}, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return Date(until), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid recurrence until %q", value)
+}
+
+// Date drops the time of day, occurrences are whole days
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Next returns the first occurrence after the given date, occurrence is the number of the occurrence
+// the date belongs to, counting from 1. It returns false once the series is over.
+func (r *Rule) Next(after time.Time, occurrence int) (time.Time, bool) {
+	if r.Count != 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+	after = Date(after)
+	start := r.periodStart(after)
+	for period := 0; period < maxPeriods; period++ {
+		for _, candidate := range r.candidates(start, after) {
+			if !candidate.After(after) {
+				continue
+			}
+			if !r.Until.IsZero() && candidate.After(r.Until) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+		start = r.addPeriods(start, r.Interval)
+	}
+	return time.Time{}, false
+}
+
+// periodStart is the first day of the day, week (starting monday), month or year holding t
+func (r *Rule) periodStart(t time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case Monthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case Yearly:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+func (r *Rule) addPeriods(start time.Time, n int) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return start.AddDate(0, 0, 7*n)
+	case Monthly:
+		return start.AddDate(0, n, 0)
+	case Yearly:
+		return start.AddDate(n, 0, 0)
+	}
+	return start.AddDate(0, 0, n)
+}
+
+// candidates lists the days of the period starting at start which match the rule, in order,
+// anchor supplies the weekday, day or month when the rule does not name one
+func (r *Rule) candidates(start, anchor time.Time) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		if r.matchesWeekday(start) && r.matchesMonthDay(start) {
+			days = append(days, start)
+		}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() != anchor.Weekday() {
+				continue
+			}
+			if r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			// months without the anchor day, like the 31st in april, are skipped
+			day := time.Date(start.Year(), start.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC)
+			if day.Month() == start.Month() {
+				days = append(days, day)
+			}
+			break
+		}
+		for day := start; day.Month() == start.Month(); day = day.AddDate(0, 0, 1) {
+			if r.matchesWeekday(day) && r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+	case Yearly:
+		day := time.Date(start.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC)
+		// the 29th of february only comes around in leap years
+		if day.Month() == anchor.Month() {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Day != day.Weekday() {
+			continue
+		}
+		if weekday.Ordinal == 0 {
+			return true
+		}
+		if weekday.Ordinal > 0 && (day.Day()-1)/7+1 == weekday.Ordinal {
+			return true
+		}
+		if weekday.Ordinal < 0 && (daysInMonth(day)-day.Day())/7+1 == -weekday.Ordinal {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay > 0 && day.Day() == monthDay {
+			return true
+		}
+		if monthDay < 0 && daysInMonth(day)+monthDay+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
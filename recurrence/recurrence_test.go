@@ -0,0 +1,154 @@
+package recurrence
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	rule, err := Parse(" rrule:FREQ=MONTHLY;interval=2;BYDAY=MO,-1FR;UNTIL=20261231T235959Z ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Rule{
+		Freq:     Monthly,
+		Interval: 2,
+		ByDay:    []Weekday{{Day: time.Monday}, {Ordinal: -1, Day: time.Friday}},
+		Until:    date(2026, time.December, 31),
+	}
+	if !reflect.DeepEqual(rule, want) {
+		t.Fatalf("Parse = %+v, want %+v", rule, want)
+	}
+	rule, err = Parse("FREQ=DAILY")
+	if err != nil || rule.Interval != 1 || rule.Count != 0 || !rule.Until.IsZero() {
+		t.Fatalf("Parse(FREQ=DAILY) = %+v, %v", rule, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"", "recurrence needs a FREQ"},
+		{"INTERVAL=2", "recurrence needs a FREQ"},
+		{"FREQ", `invalid recurrence part "FREQ"`},
+		{"FREQ=", `invalid recurrence part "FREQ="`},
+		{"FREQ=HOURLY", `unsupported recurrence frequency "HOURLY"`},
+		{"FREQ=DAILY;BYSETPOS=1", `unsupported recurrence part "BYSETPOS"`},
+		{"FREQ=DAILY;INTERVAL=0", "recurrence interval must be a positive number"},
+		{"FREQ=DAILY;INTERVAL=two", "recurrence interval must be a positive number"},
+		{"FREQ=DAILY;COUNT=-1", "recurrence count must be a positive number"},
+		{"FREQ=DAILY;UNTIL=tomorrow", `invalid recurrence until "TOMORROW"`},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20260101", "recurrence can not have both COUNT and UNTIL"},
+		{"FREQ=WEEKLY;BYDAY=XX", `invalid recurrence weekday "XX"`},
+		{"FREQ=MONTHLY;BYDAY=6MO", `invalid recurrence weekday "6MO"`},
+		{"FREQ=MONTHLY;BYDAY=0MO", `invalid recurrence weekday "0MO"`},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", `invalid recurrence month day "32"`},
+		{"FREQ=MONTHLY;BYMONTHDAY=0", `invalid recurrence month day "0"`},
+		{"FREQ=YEARLY;BYDAY=MO", "BYDAY and BYMONTHDAY are not supported for yearly recurrence"},
+		{"FREQ=WEEKLY;BYDAY=1MO", "numbered BYDAY entries are only supported for monthly recurrence"},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", "BYMONTHDAY is not supported for weekly recurrence"},
+	}
+	for _, test := range tests {
+		rule, err := Parse(test.rule)
+		if err == nil {
+			t.Errorf("Parse(%q) = %+v, want error %q", test.rule, rule, test.want)
+			continue
+		}
+		if err.Error() != test.want {
+			t.Errorf("Parse(%q) error = %q, want %q", test.rule, err, test.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       string
+		after      time.Time
+		occurrence int
+		want       []time.Time
+	}{
+		{"daily", "FREQ=DAILY", date(2026, time.December, 30), 1,
+			[]time.Time{date(2026, time.December, 31), date(2027, time.January, 1)}},
+		{"daily interval", "FREQ=DAILY;INTERVAL=3", date(2026, time.February, 27), 1,
+			[]time.Time{date(2026, time.March, 2), date(2026, time.March, 5)}},
+		{"daily on weekdays", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", date(2026, time.January, 9), 1,
+			[]time.Time{date(2026, time.January, 12), date(2026, time.January, 13)}},
+		{"weekly keeps the weekday", "FREQ=WEEKLY", date(2026, time.January, 7), 1,
+			[]time.Time{date(2026, time.January, 14), date(2026, time.January, 21)}},
+		{"weekly interval with days", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", date(2026, time.January, 5), 1,
+			[]time.Time{date(2026, time.January, 9), date(2026, time.January, 19), date(2026, time.January, 23)}},
+		{"monthly skips the 31st", "FREQ=MONTHLY", date(2026, time.January, 31), 1,
+			[]time.Time{date(2026, time.March, 31), date(2026, time.May, 31), date(2026, time.July, 31)}},
+		{"monthly interval", "FREQ=MONTHLY;INTERVAL=3", date(2026, time.November, 15), 1,
+			[]time.Time{date(2027, time.February, 15), date(2027, time.May, 15)}},
+		{"last friday", "FREQ=MONTHLY;BYDAY=-1FR", date(2026, time.January, 30), 1,
+			[]time.Time{date(2026, time.February, 27), date(2026, time.March, 27), date(2026, time.April, 24)}},
+		{"second tuesday", "FREQ=MONTHLY;BYDAY=2TU", date(2026, time.January, 13), 1,
+			[]time.Time{date(2026, time.February, 10), date(2026, time.March, 10)}},
+		{"last day of the month", "FREQ=MONTHLY;BYMONTHDAY=-1", date(2026, time.January, 31), 1,
+			[]time.Time{date(2026, time.February, 28), date(2026, time.March, 31), date(2026, time.April, 30)}},
+		{"first and fifteenth", "FREQ=MONTHLY;BYMONTHDAY=1,15", date(2026, time.January, 1), 1,
+			[]time.Time{date(2026, time.January, 15), date(2026, time.February, 1)}},
+		{"yearly", "FREQ=YEARLY", date(2026, time.March, 10), 1,
+			[]time.Time{date(2027, time.March, 10), date(2028, time.March, 10)}},
+		{"yearly on feb 29", "FREQ=YEARLY", date(2024, time.February, 29), 1,
+			[]time.Time{date(2028, time.February, 29), date(2032, time.February, 29)}},
+		{"count", "FREQ=DAILY;COUNT=3", date(2026, time.January, 1), 1,
+			[]time.Time{date(2026, time.January, 2), date(2026, time.January, 3)}},
+		{"until is inclusive", "FREQ=WEEKLY;UNTIL=20260115", date(2026, time.January, 1), 1,
+			[]time.Time{date(2026, time.January, 8), date(2026, time.January, 15)}},
+		{"count reached", "FREQ=DAILY;COUNT=2", date(2026, time.January, 1), 2, nil},
+		{"until passed", "FREQ=DAILY;UNTIL=20260101", date(2026, time.January, 1), 1, nil},
+	}
+	for _, test := range tests {
+		rule, err := Parse(test.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var got []time.Time
+		after, occurrence := test.after, test.occurrence
+		for len(got) <= len(test.want) {
+			next, ok := rule.Next(after, occurrence)
+			if !ok {
+				break
+			}
+			got = append(got, next)
+			after, occurrence = next, occurrence+1
+		}
+		// the series has to end right after the expected occurrences when it is limited
+		limited := rule.Count != 0 || !rule.Until.IsZero()
+		if !limited && len(got) > len(test.want) {
+			got = got[:len(test.want)]
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Next from %s = %v, want %v", test.name, test.after.Format("2006-01-02"), got, test.want)
+		}
+	}
+}
+
+func TestNextDropsTimeOfDay(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, ok := rule.Next(time.Date(2026, time.January, 1, 23, 30, 0, 0, time.UTC), 1)
+	if !ok || !next.Equal(date(2026, time.January, 2)) {
+		t.Fatalf("Next = %v, %v", next, ok)
+	}
+}
+
+func TestNextNeverMatching(t *testing.T) {
+	// the 30th of february never comes, the search gives up instead of looping
+	rule := &Rule{Freq: Monthly, Interval: 12, ByMonthDay: []int{30}}
+	if next, ok := rule.Next(date(2026, time.February, 1), 1); ok {
+		t.Fatalf("Next = %v, want no occurrence", next)
+	}
+}
@@ -1,16 +1,34 @@
 package router
 
 import (
+	"os"
+	"strings"
+	"time"
+
+	"todo/auth"
+	"todo/blobstore"
+	"todo/constants"
 	"todo/controller"
 	"todo/database"
+	"todo/lockout"
+	"todo/mailer"
 	"todo/middleware"
+	"todo/notifier"
+	"todo/scheduler"
 	"todo/services"
+	"todo/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
+//SetupRouter wires up the routes, the returned scheduler delivers reminders and the purger empties
+//the trash once started
+func SetupRouter() (*gin.Engine, *scheduler.Scheduler, *scheduler.TrashPurger) {
 	router := gin.Default()
+	// Load the jwt signing keys
+	if err := auth.InitKeys(); err != nil {
+		panic(err)
+	}
 	// Get Db connection
 	db, err := database.InitDB()
 	if err != nil {
@@ -18,21 +36,171 @@ func SetupRouter() *gin.Engine {
 	}
 	//create access variables
 	todoDatabase := database.NewTodoDatabase(db)
-	todoService := services.NewTodoService(todoDatabase)
+	// promote the configured admin accounts
+	for _, email := range strings.Split(os.Getenv(constants.AdminEmailsEnv), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			if _, err := todoDatabase.SetUserRoleByEmail(email, constants.RoleAdmin); err != nil {
+				panic(err)
+			}
+		}
+	}
+	mail, err := mailer.NewMailer()
+	if err != nil {
+		panic(err)
+	}
+	// track failed signins per account and per client ip
+	var attempts lockout.Store
+	if os.Getenv(constants.LockoutStoreEnv) == "memory" {
+		attempts = lockout.NewMemoryStore()
+	} else {
+		attempts = database.NewLockoutStore(db)
+	}
+	lockoutDuration := utils.EnvDuration(constants.LoginLockoutDurationEnv, constants.LoginLockoutDuration)
+	accountGuard := lockout.NewGuard(attempts, lockout.Policy{
+		FreeAttempts:    utils.EnvInt(constants.LoginFreeAttemptsEnv, 3),
+		BaseDelay:       utils.EnvDuration(constants.LoginBackoffBaseEnv, time.Second),
+		MaxDelay:        time.Minute,
+		Threshold:       utils.EnvInt(constants.LoginMaxAttemptsEnv, 10),
+		LockoutDuration: lockoutDuration,
+	})
+	ipGuard := lockout.NewGuard(attempts, lockout.Policy{
+		FreeAttempts:    utils.EnvInt(constants.LoginFreeAttemptsEnv, 3) * 5,
+		BaseDelay:       utils.EnvDuration(constants.LoginBackoffBaseEnv, time.Second),
+		MaxDelay:        time.Minute,
+		Threshold:       utils.EnvInt(constants.LoginIPMaxAttemptsEnv, 50),
+		LockoutDuration: lockoutDuration,
+	})
+	// every password reset request counts, whether the account exists or not
+	resetGuard := lockout.NewGuard(attempts, lockout.Policy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Minute * 15,
+		Threshold:       utils.EnvInt(constants.PasswordResetMaxRequestsEnv, 10),
+		LockoutDuration: constants.PasswordResetLockoutDuration,
+	})
+	notifiers, err := notifier.NewNotifiers(mail)
+	if err != nil {
+		panic(err)
+	}
+	reminders := scheduler.New(database.NewReminderStore(db), notifiers,
+		utils.EnvDuration(constants.ReminderPollIntervalEnv, constants.ReminderPollInterval))
+	assignmentNotifiers, err := notifier.NewAssignmentNotifiers(mail)
+	if err != nil {
+		panic(err)
+	}
+	blobs, err := blobstore.NewBlobStore()
+	if err != nil {
+		panic(err)
+	}
+	todoService := services.NewTodoService(todoDatabase, mail, accountGuard, ipGuard, resetGuard, assignmentNotifiers, blobs)
+	trash := scheduler.NewTrashPurger(todoService,
+		utils.EnvDuration(constants.TrashRetentionEnv, constants.TrashRetention),
+		utils.EnvDuration(constants.TrashPurgeIntervalEnv, constants.TrashPurgeInterval))
 	ctrl := controller.NewTodoController(todoService)
+	authorized := middleware.TokenAuthMiddleware(todoDatabase)
+	verified := middleware.VerifiedEmailMiddleware(todoDatabase)
+	session := middleware.SessionOnly()
+	admin := middleware.RequireRole(constants.RoleAdmin)
+	readTodos := middleware.RequireScope(constants.ScopeTodosRead)
+	writeTodos := middleware.RequireScope(constants.ScopeTodosWrite)
+	readCategories := middleware.RequireScope(constants.ScopeCategoriesRead)
+	writeCategories := middleware.RequireScope(constants.ScopeCategoriesWrite)
+	readTags := middleware.RequireScope(constants.ScopeTagsRead)
+	writeTags := middleware.RequireScope(constants.ScopeTagsWrite)
+	readLists := middleware.RequireScope(constants.ScopeListsRead)
+	writeLists := middleware.RequireScope(constants.ScopeListsWrite)
 	todo := router.Group("/api/todo/v1/")
 	{
 		todo.POST("/signup", ctrl.SignUpController)
 		todo.POST("/signin", ctrl.SignInController)
-		todo.POST("/addtodo", middleware.TokenAuthMiddleware(), ctrl.AddTodoController)
-		todo.DELETE("/deletetodo", middleware.TokenAuthMiddleware(), ctrl.DeleteTodoController)
-		todo.PUT("/edittodo", middleware.TokenAuthMiddleware(), ctrl.EditTodoController)
-		todo.GET("/getalltodos", middleware.TokenAuthMiddleware(), ctrl.GetAllTodosController)
-		todo.GET("/gettodobycategory", middleware.TokenAuthMiddleware(), ctrl.GetTodoByCategoryController)
-		todo.POST("/marktodo", middleware.TokenAuthMiddleware(), ctrl.MarkTodoController)
-		todo.POST("/addcategory", middleware.TokenAuthMiddleware(), ctrl.AddCategoryController)
-		todo.GET("/getcategory", middleware.TokenAuthMiddleware(), ctrl.GetCategoryController)
-		todo.DELETE("/deletecategory", middleware.TokenAuthMiddleware(), ctrl.DeleteCategoryController)
-	}
-	return router
+		todo.POST("/signin/2fa", ctrl.SignInTwoFactorController)
+		todo.POST("/token/refresh", ctrl.RefreshTokenController)
+		todo.POST("/signout", authorized, session, ctrl.SignOutController)
+		todo.GET("/keys", ctrl.KeysController)
+		todo.POST("/password/forgot", ctrl.ForgotPasswordController)
+		todo.POST("/password/reset", ctrl.ResetPasswordController)
+		todo.GET("/verify-email", ctrl.VerifyEmailController)
+		todo.POST("/verify-email/resend", authorized, session, ctrl.ResendVerificationController)
+		todo.POST("/2fa/enroll", authorized, session, ctrl.EnrollTOTPController)
+		todo.POST("/2fa/confirm", authorized, session, ctrl.ConfirmTOTPController)
+		todo.POST("/2fa/disable", authorized, session, ctrl.DisableTOTPController)
+		todo.GET("/me", authorized, session, ctrl.GetMeController)
+		todo.PATCH("/me", authorized, session, ctrl.UpdateMeController)
+		todo.POST("/me/password", authorized, session, ctrl.ChangePasswordController)
+		todo.DELETE("/me", authorized, session, ctrl.DeleteMeController)
+		todo.GET("/me/export", authorized, session, ctrl.ExportDataController)
+		todo.POST("/me/import", authorized, session, verified, ctrl.ImportDataController)
+		todo.POST("/tokens", authorized, session, ctrl.CreatePersonalAccessTokenController)
+		todo.GET("/tokens", authorized, session, ctrl.GetPersonalAccessTokensController)
+		todo.DELETE("/tokens", authorized, session, ctrl.RevokePersonalAccessTokenController)
+		todo.POST("/addtodo", authorized, writeTodos, verified, ctrl.AddTodoController)
+		todo.DELETE("/deletetodo", authorized, writeTodos, verified, ctrl.DeleteTodoController)
+		todo.PUT("/edittodo", authorized, writeTodos, verified, ctrl.EditTodoController)
+		todo.GET("/getalltodos", authorized, readTodos, ctrl.GetAllTodosController)
+		todo.GET("/todos/search", authorized, readTodos, ctrl.SearchTodosController)
+		todo.GET("/todos/next", authorized, readTodos, ctrl.NextTodosController)
+		todo.GET("/todos/assigned", authorized, readTodos, ctrl.GetAssignedTodosController)
+		todo.GET("/todos/created", authorized, readTodos, ctrl.GetCreatedTodosController)
+		todo.POST("/subtasks", authorized, writeTodos, verified, ctrl.AddSubtaskController)
+		todo.GET("/subtasks", authorized, readTodos, ctrl.GetSubtasksController)
+		todo.POST("/dependencies", authorized, writeTodos, verified, ctrl.AddDependencyController)
+		todo.GET("/dependencies", authorized, readTodos, ctrl.GetDependenciesController)
+		todo.DELETE("/dependencies", authorized, writeTodos, verified, ctrl.RemoveDependencyController)
+		todo.POST("/recurrence/skip", authorized, writeTodos, verified, ctrl.SkipOccurrenceController)
+		todo.POST("/recurrence/end", authorized, writeTodos, verified, ctrl.EndSeriesController)
+		todo.POST("/reminders", authorized, writeTodos, verified, ctrl.AddReminderController)
+		todo.GET("/reminders", authorized, readTodos, ctrl.GetRemindersController)
+		todo.DELETE("/reminders", authorized, writeTodos, verified, ctrl.DeleteReminderController)
+		todo.GET("/gettodobycategory", authorized, readTodos, ctrl.GetTodoByCategoryController)
+		todo.POST("/marktodo", authorized, writeTodos, verified, ctrl.MarkTodoController)
+		todo.POST("/status", authorized, writeTodos, verified, ctrl.ChangeStatusController)
+		todo.GET("/status/history", authorized, readTodos, ctrl.GetStatusHistoryController)
+		todo.POST("/assign", authorized, writeTodos, verified, ctrl.AssignTodoController)
+		todo.GET("/assign/history", authorized, readTodos, ctrl.GetAssignmentHistoryController)
+		todo.POST("/comments", authorized, writeTodos, verified, ctrl.AddCommentController)
+		todo.GET("/comments", authorized, readTodos, ctrl.GetCommentsController)
+		todo.PUT("/comments", authorized, writeTodos, verified, ctrl.EditCommentController)
+		todo.DELETE("/comments", authorized, writeTodos, verified, ctrl.DeleteCommentController)
+		todo.GET("/activity", authorized, readTodos, ctrl.GetActivityController)
+		todo.POST("/attachments", authorized, writeTodos, verified, ctrl.AddAttachmentController)
+		todo.GET("/attachments", authorized, readTodos, ctrl.GetAttachmentsController)
+		todo.GET("/attachments/download", authorized, readTodos, ctrl.DownloadAttachmentController)
+		todo.DELETE("/attachments", authorized, writeTodos, verified, ctrl.DeleteAttachmentController)
+		todo.GET("/attachments/usage", authorized, readTodos, ctrl.GetStorageUsageController)
+		todo.GET("/workflow", authorized, readTodos, ctrl.GetWorkflowController)
+		todo.PUT("/workflow", authorized, writeTodos, verified, ctrl.SetWorkflowController)
+		todo.DELETE("/workflow", authorized, writeTodos, verified, ctrl.DeleteWorkflowController)
+		todo.POST("/addcategory", authorized, writeCategories, verified, ctrl.AddCategoryController)
+		todo.GET("/getcategory", authorized, readCategories, ctrl.GetCategoryController)
+		todo.DELETE("/deletecategory", authorized, writeCategories, verified, ctrl.DeleteCategoryController)
+		todo.GET("/trash", authorized, readTodos, readCategories, ctrl.GetTrashController)
+		todo.POST("/trash/todos/restore", authorized, writeTodos, verified, ctrl.RestoreTodoController)
+		todo.DELETE("/trash/todos", authorized, writeTodos, verified, ctrl.PurgeTodoController)
+		todo.POST("/trash/categories/restore", authorized, writeCategories, verified, ctrl.RestoreCategoryController)
+		todo.DELETE("/trash/categories", authorized, writeCategories, verified, ctrl.PurgeCategoryController)
+		todo.POST("/members", authorized, writeCategories, verified, ctrl.InviteMemberController)
+		todo.GET("/members", authorized, readCategories, ctrl.GetMembersController)
+		todo.PUT("/members", authorized, writeCategories, verified, ctrl.EditMemberController)
+		todo.DELETE("/members", authorized, writeCategories, verified, ctrl.RemoveMemberController)
+		todo.POST("/tags", authorized, writeTags, verified, ctrl.AddTagController)
+		todo.GET("/tags", authorized, readTags, ctrl.GetTagsController)
+		todo.PUT("/tags", authorized, writeTags, verified, ctrl.EditTagController)
+		todo.DELETE("/tags", authorized, writeTags, verified, ctrl.DeleteTagController)
+		todo.POST("/lists", authorized, writeLists, verified, ctrl.AddSmartListController)
+		todo.GET("/lists", authorized, readLists, ctrl.GetSmartListsController)
+		todo.PUT("/lists", authorized, writeLists, verified, ctrl.EditSmartListController)
+		todo.DELETE("/lists", authorized, writeLists, verified, ctrl.DeleteSmartListController)
+		todo.GET("/lists/:id/todos", authorized, readLists, readTodos, ctrl.GetSmartListTodosController)
+	}
+	admins := router.Group("/api/todo/v1/admin/", authorized, session, admin)
+	{
+		admins.GET("/users", ctrl.SearchUsersController)
+		admins.POST("/disableuser", ctrl.DisableUserController)
+		admins.POST("/enableuser", ctrl.EnableUserController)
+		admins.POST("/forcepasswordreset", ctrl.ForcePasswordResetController)
+		admins.POST("/setrole", ctrl.SetUserRoleController)
+		admins.GET("/stats", ctrl.GetStatsController)
+		admins.GET("/invalidduedates", ctrl.GetInvalidDueDatesController)
+	}
+	return router, reminders, trash
 }
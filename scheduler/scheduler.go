@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"todo/notifier"
+)
+
+// Store hands out due reminders and records their delivery, it is implemented on the sqlite database
+type Store interface {
+	// ClaimDueReminders marks up to limit reminders due at now as being sent and returns them,
+	// a claimed reminder is never handed out again
+	ClaimDueReminders(now time.Time, limit int) ([]notifier.Notification, error)
+	// FinishReminder records the outcome of a claimed reminder, an empty deliveryError means it was sent
+	FinishReminder(id int, now time.Time, deliveryError string) error
+	// AbandonClaimedReminders fails the reminders left claimed by a previous run, it is unknown whether
+	// they went out, and sending them again could notify twice
+	AbandonClaimedReminders(now time.Time) (int, error)
+}
+
+// batchSize is the most reminders handled in one tick
+const batchSize = 100
+
+// Scheduler polls the store for due reminders and delivers them through the notifiers
+type Scheduler struct {
+	store     Store
+	notifiers []notifier.Notifier
+	interval  time.Duration
+	now       func() time.Time
+	stop      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+}
+
+//New creates a scheduler which checks for due reminders every interval
+func New(store Store, notifiers []notifier.Notifier, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		notifiers: notifiers,
+		interval:  interval,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in its own goroutine until Stop is called
+func (s *Scheduler) Start() {
+	if n, err := s.store.AbandonClaimedReminders(s.now()); err != nil {
+		log.Println(err)
+	} else if n > 0 {
+		log.Printf("%d reminders were interrupted by a restart and are not sent again", n)
+	}
+	go s.run()
+}
+
+// Stop waits for the reminders being delivered, or for the context to end
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.tick()
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick delivers the due reminders, a full batch is followed by the next one right away
+func (s *Scheduler) tick() {
+	for {
+		reminders, err := s.store.ClaimDueReminders(s.now(), batchSize)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		for _, reminder := range reminders {
+			s.deliver(reminder)
+		}
+		if len(reminders) < batchSize {
+			return
+		}
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+// deliver hands the reminder to every notifier once, failures are recorded rather than retried
+func (s *Scheduler) deliver(reminder notifier.Notification) {
+	var failures []string
+	for _, n := range s.notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := n.Notify(ctx, reminder); err != nil {
+			failures = append(failures, n.Name()+": "+err.Error())
+		}
+		cancel()
+	}
+	if len(failures) > 0 {
+		log.Printf("reminder %d: %s", reminder.ReminderId, strings.Join(failures, "; "))
+	}
+	if err := s.store.FinishReminder(reminder.ReminderId, s.now(), strings.Join(failures, "; ")); err != nil {
+		log.Println(err)
+	}
+}
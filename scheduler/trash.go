@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// TrashStore deletes trashed todos and categories for good, it is implemented by the todo service
+// so that the attachments of purged todos go too
+type TrashStore interface {
+	// PurgeTrash deletes what was trashed before the given time and returns the number of items purged
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+}
+
+// TrashPurger periodically purges the trash of everything older than the retention
+type TrashPurger struct {
+	store     TrashStore
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+	stop      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+	cancel    context.CancelFunc
+}
+
+//NewTrashPurger creates a purger which looks for expired trash every interval
+func NewTrashPurger(store TrashStore, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the purger in its own goroutine until Stop is called
+func (p *TrashPurger) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.run(ctx)
+}
+
+// Stop interrupts a running purge and waits for it, or for the context to end
+func (p *TrashPurger) Stop(ctx context.Context) error {
+	p.once.Do(func() {
+		close(p.stop)
+		if p.cancel != nil {
+			p.cancel()
+		}
+	})
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *TrashPurger) run(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.tick(ctx)
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick purges what was trashed longer than the retention ago, items which fail are tried again next time
+func (p *TrashPurger) tick(ctx context.Context) {
+	n, err := p.store.PurgeTrash(ctx, p.now().Add(-p.retention))
+	if err != nil && ctx.Err() == nil {
+		log.Println(err)
+	}
+	if n > 0 {
+		log.Printf("purged %d items from the trash", n)
+	}
+}
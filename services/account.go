@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"todo/model"
+	"todo/utils"
+
+	"github.com/badoux/checkmail"
+	"github.com/gin-gonic/gin"
+)
+
+//GetMe method fetches the profile of the current user
+func (ds todoService) GetMe(ctxt *gin.Context) (*model.User, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	user.Password = ""
+	return user, nil
+}
+
+//UpdateMe method changes the name, email and time zone of the current user, changing the email takes
+//the current password and the new email has to be verified again
+func (ds todoService) UpdateMe(ctxt *gin.Context, input model.UpdateProfile) (*model.User, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if name := strings.TrimSpace(input.Name); name != "" {
+		user.Name = name
+	}
+	if input.TimeZone != "" {
+		if err := checkTimeZone(input.TimeZone); err != nil {
+			return nil, err
+		}
+		user.TimeZone = input.TimeZone
+	}
+	emailChanged := false
+	if email := strings.TrimSpace(input.Email); email != "" && email != user.Email {
+		if err := checkmail.ValidateFormat(email); err != nil {
+			return nil, err
+		}
+		if input.Password == "" {
+			return nil, errors.New("please provide your current password to change the email")
+		}
+		if err := utils.VerifyPassword(user.Password, input.Password); err != nil {
+			return nil, errors.New("current password is incorrect")
+		}
+		if ds.todoDatabase.CheckEmailExists(email) {
+			return nil, errors.New("email is already in use")
+		}
+		user.Email = email
+		user.EmailVerified = false
+		emailChanged = true
+	}
+	err = ds.todoDatabase.UpdateUserProfile(user)
+	if err != nil {
+		return nil, errors.New("unable to update profile")
+	}
+	if emailChanged {
+		// tokens sent to the old email must not verify the new one
+		if err := ds.todoDatabase.DeleteEmailVerifications(user.ID); err != nil {
+			log.Println(err)
+		}
+		if err := ds.sendVerification(user); err != nil {
+			log.Println(err)
+		}
+	}
+	user.Password = ""
+	return user, nil
+}
+
+//ChangePassword method sets a new password after checking the current one, other sessions are signed out
+//and personal access tokens revoked
+func (ds todoService) ChangePassword(ctxt *gin.Context, input model.ChangePassword) error {
+	// fetch the user-id and session-id from context
+	id, _ := ctxt.Get("user-id")
+	sessionId, _ := ctxt.Get("session-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := utils.VerifyPassword(user.Password, input.CurrentPassword); err != nil {
+		return errors.New("current password is incorrect")
+	}
+	hashed, err := model.Hash(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	err = ds.todoDatabase.UpdateUserPassword(user.ID, string(hashed))
+	if err != nil {
+		return errors.New("unable to change password")
+	}
+	err = ds.todoDatabase.RevokeOtherSessions(user.ID, sessionId.(int))
+	if err != nil {
+		return errors.New("unable to revoke sessions")
+	}
+	err = ds.todoDatabase.RevokeUserPersonalAccessTokens(user.ID)
+	if err != nil {
+		return errors.New("unable to revoke tokens")
+	}
+	return nil
+}
+
+//DeleteMe method deletes the current user together with all their data and tokens
+func (ds todoService) DeleteMe(ctxt *gin.Context, password string) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := utils.VerifyPassword(user.Password, password); err != nil {
+		return errors.New("password is incorrect")
+	}
+	// the blobs of the attachments can only be found before their rows go
+	keys, err := ds.todoDatabase.GetUserAttachmentKeys(user.ID)
+	if err != nil {
+		return errors.New("unable to delete account")
+	}
+	err = ds.todoDatabase.DeleteUser(user.ID)
+	if err != nil {
+		return errors.New("unable to delete account")
+	}
+	ds.deleteBlobs(keys)
+	return nil
+}
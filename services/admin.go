@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//SearchUsers method lists the users whose name or email contains the query
+func (ds todoService) SearchUsers(ctxt *gin.Context, query string, limit, offset int) (*[]model.User, error) {
+	users, err := ds.todoDatabase.SearchUsers(query, limit, offset)
+	if err != nil {
+		return nil, errors.New("unable to fetch users")
+	}
+	// never hand out password hashes
+	for i := range *users {
+		(*users)[i].Password = ""
+	}
+	return users, nil
+}
+
+//SetUserDisabled method disables or enables an account, disabling signs the user out everywhere
+func (ds todoService) SetUserDisabled(ctxt *gin.Context, userId int, disabled bool) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if id == userId {
+		return errors.New("you can not disable your own account")
+	}
+	effect, err := ds.todoDatabase.SetUserDisabled(userId, disabled)
+	if err != nil {
+		return errors.New("unable to update user")
+	}
+	if effect == 0 {
+		return errors.New("user not found")
+	}
+	if disabled {
+		return ds.revokeAllTokens(userId)
+	}
+	return nil
+}
+
+//ForcePasswordReset method blocks signin until the user has chosen a new password through the mailed link
+func (ds todoService) ForcePasswordReset(ctxt *gin.Context, userId int) error {
+	user, err := ds.todoDatabase.GetUserById(userId)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	_, err = ds.todoDatabase.SetMustResetPassword(userId)
+	if err != nil {
+		return errors.New("unable to update user")
+	}
+	err = ds.revokeAllTokens(userId)
+	if err != nil {
+		return err
+	}
+	return ds.sendPasswordReset(user)
+}
+
+//SetUserRole method changes the role of a user and signs it out everywhere, the tokens carrying
+//the old role stop working at once
+func (ds todoService) SetUserRole(ctxt *gin.Context, input model.UserRole) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if input.Role != constants.RoleUser && input.Role != constants.RoleAdmin {
+		return errors.New("invalid role " + input.Role)
+	}
+	if id == input.ID {
+		return errors.New("you can not change your own role")
+	}
+	effect, err := ds.todoDatabase.SetUserRole(input.ID, input.Role)
+	if err != nil {
+		return errors.New("unable to update user")
+	}
+	if effect == 0 {
+		return errors.New("user not found")
+	}
+	return ds.revokeAllTokens(input.ID)
+}
+
+//GetStats method counts users, todos and sessions
+func (ds todoService) GetStats(ctxt *gin.Context) (*model.Stats, error) {
+	stats, err := ds.todoDatabase.GetStats()
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("unable to fetch stats")
+	}
+	return stats, nil
+}
+
+// revokeAllTokens signs the user out of every session and revokes the personal access tokens
+func (ds todoService) revokeAllTokens(userId int) error {
+	err := ds.todoDatabase.RevokeUserSessions(userId)
+	if err != nil {
+		return errors.New("unable to revoke sessions")
+	}
+	err = ds.todoDatabase.RevokeUserPersonalAccessTokens(userId)
+	if err != nil {
+		return errors.New("unable to revoke tokens")
+	}
+	return nil
+}
+
+//GetInvalidDueDates method lists the todos whose due date could not be migrated to a typed date
+func (ds todoService) GetInvalidDueDates(ctxt *gin.Context) (*[]model.InvalidDueDate, error) {
+	invalid, err := ds.todoDatabase.GetInvalidDueDates()
+	if err != nil {
+		return nil, errors.New("unable to fetch due dates")
+	}
+	return invalid, nil
+}
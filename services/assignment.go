@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+	"todo/notifier"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AssignTodo method hands a todo the current user may change to a user with access to its list,
+//or to nobody
+func (ds todoService) AssignTodo(ctxt *gin.Context, input model.AssignTodo) (*model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, input.ID, accessEdit)
+	if err != nil {
+		return nil, err
+	}
+	if input.AssigneeId != nil && *input.AssigneeId == 0 {
+		input.AssigneeId = nil
+	}
+	if !sameAssignee(todo.AssigneeId, input.AssigneeId) {
+		if err := ds.checkAssignee(todo, input.AssigneeId); err != nil {
+			return nil, err
+		}
+		if err := ds.assign(id.(int), todo, input.AssigneeId); err != nil {
+			return nil, err
+		}
+	}
+	todos, err := ds.prepareTodos(id.(int), &[]model.Todo{*todo})
+	if err != nil {
+		return nil, err
+	}
+	return &(*todos)[0], nil
+}
+
+//GetAssignmentHistory method lists the assignees a todo the current user can see went through, oldest first
+func (ds todoService) GetAssignmentHistory(ctxt *gin.Context, todoId int) (*[]model.Assignment, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, todoId, accessView)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := ds.todoDatabase.GetAssignments(todo.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch assignment history")
+	}
+	return assignments, nil
+}
+
+//GetAssignedTodos method fetches a page of the todos assigned to the current user matching the query
+func (ds todoService) GetAssignedTodos(ctxt *gin.Context, query model.TodoQuery) (*model.TodoPage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	userId := id.(int)
+	query.AssigneeId = &userId
+	return ds.listTodos(userId, query, nil)
+}
+
+//GetCreatedTodos method fetches a page of the todos the current user created matching the query
+func (ds todoService) GetCreatedTodos(ctxt *gin.Context, query model.TodoQuery) (*model.TodoPage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	userId := id.(int)
+	query.CreatorId = &userId
+	return ds.listTodos(userId, query, nil)
+}
+
+// checkAssignee makes sure the assignee exists and can see the todo, nil assigns it to nobody
+func (ds todoService) checkAssignee(todo *model.Todo, assigneeId *int) error {
+	if assigneeId == nil {
+		return nil
+	}
+	if _, err := ds.todoDatabase.GetUserById(*assigneeId); err != nil {
+		return &model.FieldError{Field: "assigneeId", Message: "no such user"}
+	}
+	if ds.todoAccess(*assigneeId, todo) < accessView {
+		return &model.FieldError{Field: "assigneeId", Message: "has no access to the list of this todo"}
+	}
+	return nil
+}
+
+// assign saves the new assignee of the todo with the change on behalf of the user,
+// and lets the new assignee know unless it assigned the todo itself
+func (ds todoService) assign(userId int, todo *model.Todo, assigneeId *int) error {
+	assignment := &model.Assignment{
+		TodoId:    todo.ID,
+		UserId:    userId,
+		From:      todo.AssigneeId,
+		To:        assigneeId,
+		ChangedAt: time.Now().Unix(),
+	}
+	if err := ds.todoDatabase.AssignTodo(assignment); err != nil {
+		return errors.New("unable to assign todo")
+	}
+	todo.AssigneeId = assigneeId
+	if assigneeId != nil && *assigneeId != userId {
+		ds.notifyAssignment(userId, todo)
+	}
+	return nil
+}
+
+// notifyAssignment hands the assignment to the assignment notifiers in the background,
+// failures are only logged
+func (ds todoService) notifyAssignment(userId int, todo *model.Todo) {
+	if len(ds.notifiers) == 0 {
+		return
+	}
+	assignee, err := ds.todoDatabase.GetUserById(*todo.AssigneeId)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	assignedBy, err := ds.todoDatabase.GetUserById(userId)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	notification := notifier.Notification{
+		Kind:       constants.NotificationAssignment,
+		TodoId:     todo.ID,
+		Title:      todo.Title,
+		UserId:     assignee.ID,
+		UserName:   assignee.Name,
+		UserEmail:  assignee.Email,
+		FireAt:     time.Now().UTC(),
+		AssignedBy: assignedBy.Name,
+	}
+	if !todo.DueDate.IsZero() {
+		dueDate := todo.DueDate
+		if !todo.AllDay {
+			dueDate.Time = dueDate.In(ds.userLocation(assignee.ID))
+		}
+		notification.DueDate = dueDate.String()
+	}
+	go func() {
+		var failures []string
+		for _, n := range ds.notifiers {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := n.Notify(ctx, notification); err != nil {
+				failures = append(failures, n.Name()+": "+err.Error())
+			}
+			cancel()
+		}
+		if len(failures) > 0 {
+			log.Printf("assignment of todo %d: %s", notification.TodoId, strings.Join(failures, "; "))
+		}
+	}()
+}
+
+func sameAssignee(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"todo/auth"
+	"todo/blobstore"
+	"todo/constants"
+	"todo/model"
+	"todo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrQuotaExceeded is returned when an upload does not fit in the attachment quota of the user
+var ErrQuotaExceeded = errors.New("attachment quota exceeded")
+
+//AddAttachment method attaches an uploaded file to a todo the current user may change. The content type
+//is sniffed from the file itself, the size counts against the quota of the current user.
+func (ds todoService) AddAttachment(ctxt *gin.Context, todoId int, file *multipart.FileHeader) (*model.Attachment, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, todoId, accessEdit)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size == 0 {
+		return nil, &model.FieldError{Field: "file", Message: "is empty"}
+	}
+	if maxSize := attachmentMaxSize(); file.Size > maxSize {
+		return nil, &model.FieldError{Field: "file", Message: fmt.Sprintf("must be at most %d bytes", maxSize)}
+	}
+	quota := attachmentQuota()
+	used, err := ds.todoDatabase.GetUsedStorage(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch storage usage")
+	}
+	if used+file.Size > quota {
+		return nil, ErrQuotaExceeded
+	}
+	content, err := file.Open()
+	if err != nil {
+		return nil, errors.New("unable to read the uploaded file")
+	}
+	defer content.Close()
+	contentType, err := sniffContentType(content)
+	if err != nil {
+		return nil, err
+	}
+	token, err := auth.NewRandomToken()
+	if err != nil {
+		return nil, errors.New("unable to add attachment")
+	}
+	attachment := &model.Attachment{
+		TodoId:      todo.ID,
+		UserId:      id.(int),
+		FileName:    cleanFileName(file.Filename),
+		ContentType: contentType,
+		Size:        file.Size,
+		Key:         fmt.Sprintf("attachments/%d/%s", todo.ID, token),
+		CreatedAt:   time.Now().Unix(),
+	}
+	if err := ds.blobs.Put(ctxt.Request.Context(), attachment.Key, content, attachment.Size, attachment.ContentType); err != nil {
+		log.Println(err)
+		return nil, errors.New("unable to store attachment")
+	}
+	// the quota is checked again with the insert, uploads running side by side could have used it up
+	added, err := ds.todoDatabase.AddAttachment(attachment, quota)
+	if err != nil || !added {
+		ds.deleteBlobs([]string{attachment.Key})
+		if err != nil {
+			return nil, errors.New("unable to add attachment")
+		}
+		return nil, ErrQuotaExceeded
+	}
+	return attachment, nil
+}
+
+//GetAttachments method lists the attachments of a todo the current user can see, oldest first
+func (ds todoService) GetAttachments(ctxt *gin.Context, todoId int) (*[]model.Attachment, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, todoId, accessView)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := ds.todoDatabase.GetAttachments(todo.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch attachments")
+	}
+	return attachments, nil
+}
+
+//DownloadAttachment method opens the content of an attachment of a todo the current user can see,
+//the caller closes it
+func (ds todoService) DownloadAttachment(ctxt *gin.Context, attachmentId int) (*model.Attachment, io.ReadCloser, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	attachment, err := ds.todoDatabase.GetAttachmentById(attachmentId)
+	if err != nil {
+		return nil, nil, errors.New("attachment does not exist")
+	}
+	if _, err := ds.authorizedTodo(id, attachment.TodoId, accessView); err != nil {
+		return nil, nil, err
+	}
+	content, err := ds.blobs.Get(ctxt.Request.Context(), attachment.Key)
+	if err != nil {
+		log.Println(err)
+		if err == blobstore.ErrNotFound {
+			return nil, nil, errors.New("attachment content is missing")
+		}
+		return nil, nil, errors.New("unable to fetch attachment")
+	}
+	return attachment, content, nil
+}
+
+//DeleteAttachment method deletes an attachment the current user uploaded, or any attachment of a todo it manages
+func (ds todoService) DeleteAttachment(ctxt *gin.Context, attachmentId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	attachment, err := ds.todoDatabase.GetAttachmentById(attachmentId)
+	if err != nil {
+		return errors.New("attachment does not exist")
+	}
+	todo, err := ds.authorizedTodo(id, attachment.TodoId, accessView)
+	if err != nil {
+		return err
+	}
+	if attachment.UserId != id && ds.todoAccess(id.(int), todo) < accessManage {
+		return errors.New("not Authorized to delete this attachment")
+	}
+	effect, err := ds.todoDatabase.DeleteAttachment(attachment.ID)
+	if err != nil || effect == 0 {
+		return errors.New("unable to delete attachment")
+	}
+	ds.deleteBlobs([]string{attachment.Key})
+	return nil
+}
+
+//GetStorageUsage method tells how much of its attachment quota the current user has used
+func (ds todoService) GetStorageUsage(ctxt *gin.Context) (*model.StorageUsage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	used, err := ds.todoDatabase.GetUsedStorage(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch storage usage")
+	}
+	return &model.StorageUsage{Used: used, Quota: attachmentQuota()}, nil
+}
+
+// deleteBlobs removes the content of deleted attachments, the rows are gone already so
+// failures are only logged and leave an orphaned blob behind
+func (ds todoService) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := ds.blobs.Delete(ctx, key); err != nil {
+			log.Printf("unable to delete blob %s: %v", key, err)
+		}
+		cancel()
+	}
+}
+
+func attachmentMaxSize() int64 {
+	return int64(utils.EnvInt(constants.AttachmentMaxSizeEnv, constants.AttachmentMaxSize))
+}
+
+func attachmentQuota() int64 {
+	return int64(utils.EnvInt(constants.AttachmentQuotaEnv, constants.AttachmentQuota))
+}
+
+// sniffContentType reads the start of the file to tell its type, whatever the client claims,
+// and rewinds it for the upload
+func sniffContentType(content multipart.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", errors.New("unable to read the uploaded file")
+	}
+	contentType := http.DetectContentType(head[:n])
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", errors.New("unable to read the uploaded file")
+	}
+	for _, allowed := range constants.AttachmentTypes {
+		if contentType == allowed {
+			return contentType, nil
+		}
+	}
+	return "", &model.FieldError{Field: "file", Message: "type " + contentType + " is not allowed"}
+}
+
+// cleanFileName keeps the base name of the uploaded file without control characters,
+// it ends up in the Content-Disposition header of downloads
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	if name == "" || name == "." || name == ".." || name == "/" {
+		name = "attachment"
+	}
+	return name
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mention is an @ followed by the name of a user, written without its spaces like @JaneDoe
+var mention = regexp.MustCompile(`@([\p{L}\p{N}_.-]+)`)
+
+//AddComment method comments on a todo the current user may change, the body is markdown and
+//may @mention the users who can access the todo
+func (ds todoService) AddComment(ctxt *gin.Context, comment *model.Comment) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, comment.TodoId, accessEdit)
+	if err != nil {
+		return err
+	}
+	body, err := checkCommentBody(comment.Body)
+	if err != nil {
+		return err
+	}
+	mentions, err := ds.commentMentions(todo.ID, body)
+	if err != nil {
+		return err
+	}
+	comment.UserId = id.(int)
+	comment.Body = body
+	comment.Mentions = mentions
+	comment.CreatedAt = time.Now().Unix()
+	comment.UpdatedAt = 0
+	if user, err := ds.todoDatabase.GetUserById(id.(int)); err == nil {
+		comment.UserName = user.Name
+	}
+	err = ds.todoDatabase.AddComment(comment)
+	if err != nil {
+		return errors.New("unable to add comment")
+	}
+	return nil
+}
+
+//EditComment method changes the body of a comment of the current user
+func (ds todoService) EditComment(ctxt *gin.Context, input model.EditComment) (*model.Comment, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	comment, err := ds.todoDatabase.GetCommentById(input.ID)
+	if err != nil {
+		return nil, errors.New("comment does not exist")
+	}
+	if _, err := ds.authorizedTodo(id, comment.TodoId, accessView); err != nil {
+		return nil, err
+	}
+	if comment.UserId != id {
+		return nil, errors.New("not Authorized to edit this comment")
+	}
+	body, err := checkCommentBody(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := ds.commentMentions(comment.TodoId, body)
+	if err != nil {
+		return nil, err
+	}
+	comment.Body = body
+	comment.Mentions = mentions
+	comment.UpdatedAt = time.Now().Unix()
+	err = ds.todoDatabase.UpdateComment(comment)
+	if err != nil {
+		return nil, errors.New("unable to edit comment")
+	}
+	return comment, nil
+}
+
+//DeleteComment method deletes a comment of the current user, or any comment on a todo it manages
+func (ds todoService) DeleteComment(ctxt *gin.Context, commentId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	comment, err := ds.todoDatabase.GetCommentById(commentId)
+	if err != nil {
+		return errors.New("comment does not exist")
+	}
+	todo, err := ds.authorizedTodo(id, comment.TodoId, accessView)
+	if err != nil {
+		return err
+	}
+	if comment.UserId != id && ds.todoAccess(id.(int), todo) < accessManage {
+		return errors.New("not Authorized to delete this comment")
+	}
+	err = ds.todoDatabase.DeleteComment(comment.ID)
+	if err != nil {
+		return errors.New("unable to delete comment")
+	}
+	return nil
+}
+
+//GetComments method fetches a page of the comments on a todo the current user can see, oldest first
+func (ds todoService) GetComments(ctxt *gin.Context, todoId, limit, offset int) (*model.CommentPage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, todoId, accessView)
+	if err != nil {
+		return nil, err
+	}
+	page, err := ds.todoDatabase.GetComments(todo.ID, limit, offset)
+	if err != nil {
+		return nil, errors.New("unable to fetch comments")
+	}
+	return page, nil
+}
+
+//GetActivity method fetches a page of the activity feed of a todo the current user can see: its creation,
+//comments, status changes, assignments, moves between categories and to the trash, oldest first
+func (ds todoService) GetActivity(ctxt *gin.Context, todoId, limit, offset int) (*model.ActivityPage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, todoId, accessView)
+	if err != nil {
+		return nil, err
+	}
+	page, err := ds.todoDatabase.GetTodoActivity(todo.ID, limit, offset)
+	if err != nil {
+		return nil, errors.New("unable to fetch activity")
+	}
+	workflow := ds.workflowFor(todo.UserId, todo.Category)
+	for i := range *page.Items {
+		item := &(*page.Items)[i]
+		item.Summary = activitySummary(workflow, item)
+	}
+	return page, nil
+}
+
+// activitySummary describes the entry in words, status changes are read with the current workflow of the todo
+func activitySummary(workflow *model.Workflow, item *model.Activity) string {
+	switch item.Kind {
+	case constants.ActivityCreated:
+		return "created the todo"
+	case constants.ActivityComment:
+		return "commented"
+	case constants.ActivityStatus:
+		from, _ := findState(workflow, item.From)
+		to, _ := findState(workflow, item.To)
+		if to.Final && !from.Final {
+			return "marked complete as " + item.To
+		}
+		if from.Final && !to.Final {
+			return "reopened as " + item.To
+		}
+		return fmt.Sprintf("changed the status from %s to %s", item.From, item.To)
+	case constants.ActivityAssignee:
+		if item.To == "" {
+			return strings.TrimSpace("unassigned " + item.From)
+		}
+		if item.From == "" {
+			return "assigned to " + item.To
+		}
+		return fmt.Sprintf("reassigned from %s to %s", item.From, item.To)
+	case constants.ActivityCategory:
+		if item.To == "" {
+			return "removed from category " + item.From
+		}
+		if item.From == "" {
+			return "moved to category " + item.To
+		}
+		return fmt.Sprintf("moved from category %s to %s", item.From, item.To)
+	case constants.ActivityTrashed:
+		return "moved the todo to the trash"
+	case constants.ActivityRestored:
+		return "restored the todo from the trash"
+	}
+	return item.Kind
+}
+
+// recordEvent adds a change no other history keeps to the activity feed of the todo, failures are only logged
+func (ds todoService) recordEvent(userId, todoId int, kind, from, to string) {
+	event := &model.TodoEvent{
+		TodoId:    todoId,
+		UserId:    userId,
+		Kind:      kind,
+		From:      from,
+		To:        to,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := ds.todoDatabase.AddTodoEvent(event); err != nil {
+		log.Println(err)
+	}
+}
+
+// categoryName is the name of the category for the activity feed, empty for no category
+func (ds todoService) categoryName(categoryId int) string {
+	if categoryId == 0 {
+		return ""
+	}
+	owner, err := ds.todoDatabase.GetCategoryUserById(categoryId)
+	if err != nil {
+		return ""
+	}
+	category, err := ds.todoDatabase.GetCategoryById(*owner, categoryId)
+	if err != nil {
+		return ""
+	}
+	return category.Name
+}
+
+func checkCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", &model.FieldError{Field: "body", Message: "is required"}
+	}
+	if len(body) > constants.MaxCommentLength {
+		return "", &model.FieldError{Field: "body", Message: fmt.Sprintf("must be at most %d bytes", constants.MaxCommentLength)}
+	}
+	return body, nil
+}
+
+// commentMentions finds the users who can access the todo among the @mentions of the body. A mention
+// matches the name of a user without its spaces, ignoring case. Mentions of other users, and names
+// shared by several users, stay plain text.
+func (ds todoService) commentMentions(todoId int, body string) ([]int, error) {
+	participants, err := ds.todoDatabase.GetTodoParticipants(todoId)
+	if err != nil {
+		return nil, errors.New("unable to fetch the users of this todo")
+	}
+	names := map[string][]int{}
+	for _, user := range *participants {
+		name := strings.ToLower(strings.Join(strings.Fields(user.Name), ""))
+		names[name] = append(names[name], user.ID)
+	}
+	mentions := []int{}
+	seen := map[int]bool{}
+	for _, match := range mention.FindAllStringSubmatch(body, -1) {
+		users := names[strings.ToLower(strings.TrimRight(match[1], "._-"))]
+		if len(users) != 1 || seen[users[0]] {
+			continue
+		}
+		seen[users[0]] = true
+		mentions = append(mentions, users[0])
+	}
+	return mentions, nil
+}
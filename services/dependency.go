@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddDependency method makes a todo the current user may change wait for another one it can see,
+//a dependency closing a cycle is refused
+func (ds todoService) AddDependency(ctxt *gin.Context, dependency *model.Dependency) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if dependency.TodoId == dependency.BlockerId {
+		return errors.New("a todo can not depend on itself")
+	}
+	if _, err := ds.authorizedTodo(id, dependency.TodoId, accessEdit); err != nil {
+		return err
+	}
+	if _, err := ds.authorizedTodo(id, dependency.BlockerId, accessView); err != nil {
+		return err
+	}
+	blockers, err := ds.todoDatabase.GetBlockers(dependency.TodoId, id.(int))
+	if err != nil {
+		return errors.New("unable to fetch dependencies")
+	}
+	for _, blocker := range *blockers {
+		if blocker.ID == dependency.BlockerId {
+			return errors.New("todo already depends on this todo")
+		}
+	}
+	// the blocker must not wait for the todo already, or nothing in the loop could ever start
+	cycle, err := ds.todoDatabase.DependsOn(dependency.BlockerId, dependency.TodoId)
+	if err != nil {
+		return errors.New("unable to fetch dependencies")
+	}
+	if cycle {
+		return errors.New("the dependency would create a cycle")
+	}
+	dependency.UserId = id.(int)
+	dependency.CreatedAt = time.Now().Unix()
+	err = ds.todoDatabase.AddDependency(dependency)
+	if err != nil {
+		return errors.New("unable to add dependency")
+	}
+	return nil
+}
+
+//RemoveDependency method lets a todo the current user may change stop waiting for another one
+func (ds todoService) RemoveDependency(ctxt *gin.Context, todoId, blockerId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if _, err := ds.authorizedTodo(id, todoId, accessEdit); err != nil {
+		return err
+	}
+	effect, err := ds.todoDatabase.DeleteDependency(todoId, blockerId)
+	if err != nil {
+		return errors.New("unable to remove dependency")
+	}
+	if effect == 0 {
+		return errors.New("todo does not depend on this todo")
+	}
+	return nil
+}
+
+//GetDependencies method fetches the todos a todo the current user can see waits for and the ones waiting for it
+func (ds todoService) GetDependencies(ctxt *gin.Context, todoId int) (*model.TodoDependencies, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if _, err := ds.authorizedTodo(id, todoId, accessView); err != nil {
+		return nil, err
+	}
+	blockers, err := ds.todoDatabase.GetBlockers(todoId, id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch dependencies")
+	}
+	dependents, err := ds.todoDatabase.GetDependents(todoId, id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch dependencies")
+	}
+	if _, err := ds.prepareTodos(id.(int), blockers); err != nil {
+		return nil, err
+	}
+	if _, err := ds.prepareTodos(id.(int), dependents); err != nil {
+		return nil, err
+	}
+	return &model.TodoDependencies{
+		Blockers:   append([]model.Todo{}, *blockers...),
+		Dependents: append([]model.Todo{}, *dependents...),
+	}, nil
+}
+
+//NextTodos method lists the open todos the current user can see in an order where every todo comes after
+//the todos it waits for, among the todos free to go the earliest due comes first. Only the todos which
+//can be worked on now are listed, unless all is set.
+func (ds todoService) NextTodos(ctxt *gin.Context, all bool) (*[]model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todos, err := ds.todoDatabase.GetVisibleTodos(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch todos")
+	}
+	dependencies, err := ds.todoDatabase.GetDependencies(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch dependencies")
+	}
+	open := map[int]*model.Todo{}
+	for i := range *todos {
+		if todo := &(*todos)[i]; !todo.Completed {
+			open[todo.ID] = todo
+		}
+	}
+	waiting := map[int]int{}
+	dependents := map[int][]int{}
+	for _, dependency := range *dependencies {
+		if open[dependency.TodoId] != nil && open[dependency.BlockerId] != nil {
+			waiting[dependency.TodoId]++
+			dependents[dependency.BlockerId] = append(dependents[dependency.BlockerId], dependency.TodoId)
+		}
+	}
+	var free []*model.Todo
+	for _, todo := range open {
+		setDependencyFlags(todo, waiting[todo.ID])
+		if waiting[todo.ID] == 0 {
+			free = append(free, todo)
+		}
+	}
+	// Kahn's algorithm, a todo becomes free once the last todo it waits for is done
+	next := []model.Todo{}
+	for len(free) > 0 {
+		sort.Slice(free, func(i, j int) bool { return dueFirst(free[i], free[j]) })
+		todo := free[0]
+		free = free[1:]
+		if all || *todo.Ready {
+			next = append(next, *todo)
+		}
+		for _, dependent := range dependents[todo.ID] {
+			if waiting[dependent]--; waiting[dependent] == 0 {
+				free = append(free, open[dependent])
+			}
+		}
+	}
+	return ds.prepareTodos(id.(int), &next)
+}
+
+// dueFirst orders todos by due date, todos without one last, and then by id
+func dueFirst(a, b *model.Todo) bool {
+	if a.DueDate.IsZero() != b.DueDate.IsZero() {
+		return !a.DueDate.IsZero()
+	}
+	if !a.DueDate.Equal(b.DueDate.Time) {
+		return a.DueDate.Before(b.DueDate.Time)
+	}
+	return a.ID < b.ID
+}
+
+// setDependencyFlags marks a todo waiting for open todos as blocked, and an open one which is not as ready
+func setDependencyFlags(todo *model.Todo, openBlockers int) {
+	blocked := openBlockers > 0
+	ready := !blocked && !todo.Completed
+	todo.Blocked, todo.Ready = &blocked, &ready
+}
+
+// attachDependencyFlags fills in the blocked and ready flags of the todos the user can see
+func (ds todoService) attachDependencyFlags(userId int, todos *[]model.Todo) error {
+	openBlockers, err := ds.openBlockers(userId)
+	if err != nil {
+		return err
+	}
+	for i := range *todos {
+		setDependencyFlags(&(*todos)[i], openBlockers[(*todos)[i].ID])
+	}
+	return nil
+}
+
+// openBlockers counts for every todo the user can see the todos it waits for which are still open
+func (ds todoService) openBlockers(userId int) (map[int]int, error) {
+	todos, err := ds.todoDatabase.GetVisibleTodos(userId)
+	if err != nil {
+		return nil, errors.New("unable to fetch todos")
+	}
+	dependencies, err := ds.todoDatabase.GetDependencies(userId)
+	if err != nil {
+		return nil, errors.New("unable to fetch dependencies")
+	}
+	completed := map[int]bool{}
+	for _, todo := range *todos {
+		completed[todo.ID] = todo.Completed
+	}
+	openBlockers := map[int]int{}
+	for _, dependency := range *dependencies {
+		// blockers the user can not see are not counted, they would stay open for it forever
+		if done, visible := completed[dependency.BlockerId]; visible && !done {
+			openBlockers[dependency.TodoId]++
+		}
+	}
+	return openBlockers, nil
+}
+
+// checkBlockers refuses to complete a todo while todos it waits for are still open, only the blockers
+// the user can see are checked
+func (ds todoService) checkBlockers(userId int, todo *model.Todo) error {
+	blockers, err := ds.todoDatabase.GetBlockers(todo.ID, userId)
+	if err != nil {
+		return errors.New("unable to fetch dependencies")
+	}
+	var open []string
+	for _, blocker := range *blockers {
+		if !blocker.Completed {
+			open = append(open, fmt.Sprint(blocker.ID))
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("todo is blocked by the open todos %s, complete them first or force it", strings.Join(open, ", "))
+	}
+	return nil
+}
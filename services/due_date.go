@@ -0,0 +1,100 @@
+package services
+
+import (
+	"time"
+
+	"todo/model"
+	"todo/recurrence"
+)
+
+// userLocation is the time zone of the user, UTC when it is unknown
+func (ds todoService) userLocation(userId int) *time.Location {
+	user, err := ds.todoDatabase.GetUserById(userId)
+	if err != nil {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// checkTimeZone makes sure the time zone is a known IANA name like Europe/Berlin
+func checkTimeZone(timeZone string) error {
+	if timeZone == "" || timeZone == "Local" {
+		return &model.FieldError{Field: "timeZone", Message: "must be an IANA time zone like Europe/Berlin"}
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return &model.FieldError{Field: "timeZone", Message: "must be an IANA time zone like Europe/Berlin"}
+	}
+	return nil
+}
+
+// normalizeDueDate prepares a due date for storage. All day todos keep the date as midnight UTC, so the
+// date does not move when the user changes time zone, a timestamp given for an all day todo is first
+// taken to the date it falls on for the user. Other due dates are stored as the moment they name.
+func normalizeDueDate(dueDate *model.DueDate, allDay *bool, location *time.Location) error {
+	if dueDate.IsZero() {
+		return &model.FieldError{Field: "dueDate", Message: "is required"}
+	}
+	if dueDate.DateOnly || *allDay {
+		date := dueDate.Time
+		if !dueDate.DateOnly {
+			date = date.In(location)
+		}
+		*dueDate = model.DueDate{Time: recurrence.Date(date), DateOnly: true}
+		*allDay = true
+		return nil
+	}
+	dueDate.Time = dueDate.UTC()
+	return nil
+}
+
+// localizeDueDate shows a due date in the time zone of the user, all day todos show their date
+func localizeDueDate(todo *model.Todo, location *time.Location) {
+	if todo.DueDate.IsZero() {
+		return
+	}
+	if todo.AllDay {
+		todo.DueDate.DateOnly = true
+		return
+	}
+	todo.DueDate.Time = todo.DueDate.In(location)
+}
+
+// nextDueDate is the due date of the occurrence after the due date, or after today when counting from
+// completion. Days are counted in the time zone of the user and a todo due at a time of day keeps that time.
+func nextDueDate(todo *model.Todo, rule *recurrence.Rule, location *time.Location, fromCompletion bool) (model.DueDate, bool) {
+	// all day due dates are already a plain date
+	from := todo.DueDate.Time
+	if !todo.AllDay {
+		from = from.In(location)
+	}
+	if fromCompletion {
+		from = time.Now().In(location)
+	}
+	next, found := rule.Next(from, todo.Occurrence)
+	if !found {
+		return model.DueDate{}, false
+	}
+	if todo.AllDay {
+		return model.DueDate{Time: next, DateOnly: true}, true
+	}
+	local := todo.DueDate.In(location)
+	at := time.Date(next.Year(), next.Month(), next.Day(), local.Hour(), local.Minute(), local.Second(), 0, location)
+	return model.DueDate{Time: at.UTC()}, true
+}
+
+// dueBound resolves a due date filter for both kinds of todos. A date starts at midnight for the user,
+// a timestamp limits all day todos by the date it falls on for the user.
+func dueBound(dueDate model.DueDate, location *time.Location) model.DueBound {
+	if dueDate.DateOnly {
+		date := recurrence.Date(dueDate.Time)
+		return model.DueBound{
+			At:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location),
+			Date: date,
+		}
+	}
+	return model.DueBound{At: dueDate.Time, Date: recurrence.Date(dueDate.In(location))}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"todo/auth"
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//VerifyEmail method marks the email the verification token was sent to as verified, if it still is the email of the user
+func (ds todoService) VerifyEmail(ctxt *gin.Context, token string) error {
+	if token == "" {
+		return errors.New("please provide the verification token")
+	}
+	verification, err := ds.todoDatabase.GetEmailVerificationByToken(auth.HashToken(token))
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+	effect, err := ds.todoDatabase.UseEmailVerification(verification.ID, time.Now().Unix())
+	if err != nil || effect == 0 {
+		return errors.New("invalid or expired verification token")
+	}
+	effect, err = ds.todoDatabase.SetEmailVerified(verification.UserId, verification.Email)
+	if err != nil {
+		return errors.New("unable to verify email")
+	}
+	// the user changed its email since the token was sent
+	if effect == 0 {
+		return errors.New("invalid or expired verification token")
+	}
+	return nil
+}
+
+//ResendVerification method sends a new verification mail to the current user
+func (ds todoService) ResendVerification(ctxt *gin.Context) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerified {
+		return errors.New("email is already verified")
+	}
+	return ds.sendVerification(user)
+}
+
+// sendVerification stores a new verification token for the user and mails the link to it
+func (ds todoService) sendVerification(user *model.User) error {
+	token, err := auth.NewRandomToken()
+	if err != nil {
+		return err
+	}
+	verification := model.EmailVerification{
+		UserId:    user.ID,
+		Email:     user.Email,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(constants.EmailVerificationTTL).Unix(),
+		CreatedAt: time.Now().Unix(),
+	}
+	err = ds.todoDatabase.CreateEmailVerification(&verification)
+	if err != nil {
+		return errors.New("unable to create email verification")
+	}
+	body := fmt.Sprintf("Hi %s,\n\nplease confirm your email address by opening the link below, it expires in %s.\n\n%s",
+		user.Name, constants.EmailVerificationTTL, link("/api/todo/v1/verify-email", token))
+	err = ds.mailer.Send(user.Email, "Verify your email address", body)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to send verification mail")
+	}
+	return nil
+}
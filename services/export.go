@@ -0,0 +1,446 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/filter"
+	"todo/model"
+	"todo/recurrence"
+	"todo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//ExportData method collects everything stored for the current user, without the password hash.
+//Attachments are only described, their files are not exported. Activity, status and assignment
+//history, category members, sessions and tokens are left out as they belong to this server.
+func (ds todoService) ExportData(ctxt *gin.Context) (*model.Export, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	categories, err := ds.todoDatabase.GetCategory(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch categories")
+	}
+	tags, err := ds.todoDatabase.GetTags(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch tags")
+	}
+	todos, err := ds.todoDatabase.GetAllTodo(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch todos")
+	}
+	workflows, err := ds.todoDatabase.GetWorkflows(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch workflows")
+	}
+	reminders, err := ds.todoDatabase.GetUserReminders(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch reminders")
+	}
+	lists, err := ds.todoDatabase.GetSmartLists(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch smart lists")
+	}
+	dependencies, err := ds.todoDatabase.GetDependencies(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch dependencies")
+	}
+	comments, err := ds.todoDatabase.GetUserComments(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch comments")
+	}
+	attachments, err := ds.todoDatabase.GetUserAttachments(user.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch attachments")
+	}
+	export := &model.Export{
+		Version:    constants.ExportVersion,
+		ExportedAt: time.Now().Unix(),
+		Profile: model.ExportProfile{
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		},
+		Categories:   []model.Category{},
+		Workflows:    *workflows,
+		Tags:         *tags,
+		Todos:        []model.Todo{},
+		Reminders:    *reminders,
+		SmartLists:   *lists,
+		Dependencies: []model.Dependency{},
+		Comments:     []model.Comment{},
+		Attachments:  []model.Attachment{},
+	}
+	if categories != nil {
+		export.Categories = append(export.Categories, *categories...)
+	}
+	if todos != nil {
+		if _, err := ds.prepareTodos(user.ID, todos); err != nil {
+			return nil, err
+		}
+		export.Todos = append(export.Todos, *todos...)
+	}
+	// dependencies, comments and attachments on todos of shared categories stay behind with those todos
+	exported := map[int]bool{}
+	for _, todo := range export.Todos {
+		exported[todo.ID] = true
+	}
+	for _, dependency := range *dependencies {
+		if exported[dependency.TodoId] && exported[dependency.BlockerId] {
+			export.Dependencies = append(export.Dependencies, dependency)
+		}
+	}
+	for _, comment := range *comments {
+		if exported[comment.TodoId] {
+			export.Comments = append(export.Comments, comment)
+		}
+	}
+	for _, attachment := range *attachments {
+		if exported[attachment.TodoId] {
+			export.Attachments = append(export.Attachments, attachment)
+		}
+	}
+	return export, nil
+}
+
+//ImportData method restores an export into the current user, which must not have any data yet,
+//attachments in the export are skipped as their files are not part of it
+func (ds todoService) ImportData(ctxt *gin.Context, export *model.Export) (*model.ImportResult, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if export.Version > constants.ExportVersion {
+		return nil, fmt.Errorf("export version %d is not supported", export.Version)
+	}
+	categories, err := ds.todoDatabase.GetCategory(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch categories")
+	}
+	todos, err := ds.todoDatabase.GetAllTodo(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch todos")
+	}
+	tags, err := ds.todoDatabase.GetTags(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch tags")
+	}
+	lists, err := ds.todoDatabase.GetSmartLists(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch smart lists")
+	}
+	if len(*categories) != 0 || len(*todos) != 0 || len(*tags) != 0 || len(*lists) != 0 {
+		return nil, errors.New("import is only possible into an account without todos, categories, tags and smart lists")
+	}
+	// ids link the records of the export, one used twice would merge them
+	categoryIds := map[int]bool{}
+	for _, category := range export.Categories {
+		if strings.TrimSpace(category.Name) == "" {
+			return nil, errors.New("export has a category without a name")
+		}
+		if categoryIds[category.ID] {
+			return nil, fmt.Errorf("export has the category id %d twice", category.ID)
+		}
+		categoryIds[category.ID] = true
+	}
+	for i := range export.Workflows {
+		if err := checkWorkflow(&export.Workflows[i]); err != nil {
+			return nil, fmt.Errorf("export has an invalid workflow: %v", err)
+		}
+	}
+	tagIds := map[int]bool{}
+	for i := range export.Tags {
+		tag := &export.Tags[i]
+		if strings.TrimSpace(tag.Name) == "" {
+			return nil, errors.New("export has a tag without a name")
+		}
+		if tagIds[tag.ID] {
+			return nil, fmt.Errorf("export has the tag id %d twice", tag.ID)
+		}
+		tagIds[tag.ID] = true
+		if tag.Color == "" {
+			tag.Color = constants.DefaultTagColor
+		}
+		if !tagColor.MatchString(tag.Color) {
+			return nil, errors.New("export has a tag with an invalid color")
+		}
+	}
+	location := ds.userLocation(id.(int))
+	todoIds := map[int]bool{}
+	for i := range export.Todos {
+		todo := &export.Todos[i]
+		if strings.TrimSpace(todo.Title) == "" {
+			return nil, errors.New("export has a todo without a title")
+		}
+		if todoIds[todo.ID] {
+			return nil, fmt.Errorf("export has the todo id %d twice", todo.ID)
+		}
+		todoIds[todo.ID] = true
+		if err := checkRecurrence(todo.Recurrence, &todo.RecurrenceAnchor); err != nil {
+			return nil, fmt.Errorf("export has a todo with an invalid recurrence: %v", err)
+		}
+		// todos pointing at a category missing from the export lose the category, and follow the workflow without it
+		category := todo.Category
+		if !categoryIds[category] {
+			category = 0
+		}
+		workflow := exportWorkflow(export.Workflows, category)
+		// exports from before workflows only knew completed
+		if todo.Status == "" {
+			status, err := completedStatus(workflow, todo, todo.Completed)
+			if err != nil {
+				return nil, fmt.Errorf("export has a todo with no state to import it in: %v", err)
+			}
+			todo.Status = status
+		}
+		state, found := findState(workflow, todo.Status)
+		if !found {
+			return nil, fmt.Errorf("export has a todo in the state %s, which its workflow does not have", todo.Status)
+		}
+		todo.Completed = state.Final
+		// version 1 exports only knew dates, which came out as midnight UTC
+		if export.Version < 2 && !todo.DueDate.IsZero() && todo.DueDate.Equal(recurrence.Date(todo.DueDate.Time)) {
+			todo.DueDate.DateOnly = true
+			todo.AllDay = true
+		}
+		if !todo.DueDate.IsZero() {
+			normalizeDueDate(&todo.DueDate, &todo.AllDay, location)
+		}
+	}
+	if exportHasCycle(export.Todos) {
+		return nil, errors.New("export has subtasks nested in a cycle")
+	}
+	if maxDepth := utils.EnvInt(constants.MaxTodoDepthEnv, constants.MaxTodoDepth); exportDepth(export.Todos) > maxDepth {
+		return nil, fmt.Errorf("export has todos nested deeper than %d levels", maxDepth)
+	}
+	listNames := map[string]bool{}
+	for i := range export.SmartLists {
+		list := &export.SmartLists[i]
+		list.Name = strings.TrimSpace(list.Name)
+		if list.Name == "" {
+			return nil, errors.New("export has a smart list without a name")
+		}
+		if listNames[strings.ToLower(list.Name)] {
+			return nil, errors.New("export has the smart list " + list.Name + " twice")
+		}
+		listNames[strings.ToLower(list.Name)] = true
+		if expr, err := filter.Parse(list.Query); err != nil || expr == nil {
+			return nil, errors.New("export has a smart list with an invalid query")
+		}
+	}
+	reminders, err := importReminders(export)
+	if err != nil {
+		return nil, err
+	}
+	export.Reminders = reminders
+	dependencies, err := importDependencies(export)
+	if err != nil {
+		return nil, err
+	}
+	export.Dependencies = dependencies
+	comments, err := importComments(export)
+	if err != nil {
+		return nil, err
+	}
+	export.Comments = comments
+	err = ds.todoDatabase.ImportData(id.(int), export)
+	if err != nil {
+		return nil, errors.New("unable to import data")
+	}
+	return &model.ImportResult{
+		Categories:   len(export.Categories),
+		Tags:         len(export.Tags),
+		Todos:        len(export.Todos),
+		Reminders:    len(export.Reminders),
+		SmartLists:   len(export.SmartLists),
+		Dependencies: len(export.Dependencies),
+		Comments:     len(export.Comments),
+	}, nil
+}
+
+// importComments checks the exported comments and keeps the ones on exported todos,
+// they are imported as comments of the current user
+func importComments(export *model.Export) ([]model.Comment, error) {
+	todos := map[int]bool{}
+	for _, todo := range export.Todos {
+		todos[todo.ID] = true
+	}
+	comments := []model.Comment{}
+	for _, comment := range export.Comments {
+		body, err := checkCommentBody(comment.Body)
+		if err != nil {
+			return nil, fmt.Errorf("export has an invalid comment: %v", err)
+		}
+		if !todos[comment.TodoId] {
+			continue
+		}
+		comment.Body = body
+		if comment.CreatedAt == 0 {
+			comment.CreatedAt = time.Now().Unix()
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// importDependencies keeps the exported dependencies between exported todos and refuses a cycle among them
+func importDependencies(export *model.Export) ([]model.Dependency, error) {
+	todos := map[int]bool{}
+	for _, todo := range export.Todos {
+		todos[todo.ID] = true
+	}
+	dependencies := []model.Dependency{}
+	blockers := map[int][]int{}
+	seen := map[[2]int]bool{}
+	for _, dependency := range export.Dependencies {
+		if dependency.TodoId == dependency.BlockerId {
+			return nil, errors.New("export has a todo depending on itself")
+		}
+		edge := [2]int{dependency.TodoId, dependency.BlockerId}
+		if !todos[dependency.TodoId] || !todos[dependency.BlockerId] || seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		if dependency.CreatedAt == 0 {
+			dependency.CreatedAt = time.Now().Unix()
+		}
+		dependencies = append(dependencies, dependency)
+		blockers[dependency.TodoId] = append(blockers[dependency.TodoId], dependency.BlockerId)
+	}
+	// a todo still on the path being walked is reached again only through a cycle
+	const (
+		walking = 1
+		walked  = 2
+	)
+	state := map[int]int{}
+	var cycle func(id int) bool
+	cycle = func(id int) bool {
+		switch state[id] {
+		case walking:
+			return true
+		case walked:
+			return false
+		}
+		state[id] = walking
+		for _, blocker := range blockers[id] {
+			if cycle(blocker) {
+				return true
+			}
+		}
+		state[id] = walked
+		return false
+	}
+	for id := range blockers {
+		if cycle(id) {
+			return nil, errors.New("export has dependencies in a cycle")
+		}
+	}
+	return dependencies, nil
+}
+
+// importReminders checks the exported reminders and keeps the ones of exported todos,
+// reminders which were not delivered yet are sent again from the importing server
+func importReminders(export *model.Export) ([]model.Reminder, error) {
+	todos := map[int]bool{}
+	for _, todo := range export.Todos {
+		todos[todo.ID] = true
+	}
+	reminders := []model.Reminder{}
+	for _, reminder := range export.Reminders {
+		if (reminder.RemindAt == nil) == (reminder.OffsetMinutes == nil) {
+			return nil, errors.New("export has a reminder without either remindAt or offsetMinutes")
+		}
+		if reminder.OffsetMinutes != nil && *reminder.OffsetMinutes < 0 {
+			return nil, errors.New("export has a reminder with a negative offset")
+		}
+		if !todos[reminder.TodoId] {
+			continue
+		}
+		if reminder.Status != constants.ReminderSent && reminder.Status != constants.ReminderFailed {
+			reminder.Status = constants.ReminderPending
+			reminder.SentAt = 0
+		}
+		if reminder.CreatedAt == 0 {
+			reminder.CreatedAt = time.Now().Unix()
+		}
+		reminders = append(reminders, reminder)
+	}
+	return reminders, nil
+}
+
+// exportWorkflow is the workflow of the exported category, or else the exported one of the user, or else the default one
+func exportWorkflow(workflows []model.Workflow, category int) *model.Workflow {
+	var userWorkflow *model.Workflow
+	for i := range workflows {
+		if workflows[i].Category == category {
+			return &workflows[i]
+		}
+		if workflows[i].Category == 0 {
+			userWorkflow = &workflows[i]
+		}
+	}
+	if userWorkflow != nil {
+		return userWorkflow
+	}
+	return defaultWorkflow()
+}
+
+// exportDepth is the number of levels of the deepest todo tree in the export, it must be free of cycles.
+// A todo whose parent is missing from the export is imported at the top.
+func exportDepth(todos []model.Todo) int {
+	parents := map[int]int{}
+	for _, todo := range todos {
+		if todo.ParentId != nil {
+			parents[todo.ID] = *todo.ParentId
+		}
+	}
+	exported := map[int]bool{}
+	for _, todo := range todos {
+		exported[todo.ID] = true
+	}
+	depth := 0
+	for _, todo := range todos {
+		levels := 1
+		for id := todo.ID; ; levels++ {
+			parent, found := parents[id]
+			if !found || !exported[parent] {
+				break
+			}
+			id = parent
+		}
+		if levels > depth {
+			depth = levels
+		}
+	}
+	return depth
+}
+
+// exportHasCycle reports whether following the parents of the exported todos ever loops
+func exportHasCycle(todos []model.Todo) bool {
+	parents := map[int]int{}
+	for _, todo := range todos {
+		if todo.ParentId != nil {
+			parents[todo.ID] = *todo.ParentId
+		}
+	}
+	for _, todo := range todos {
+		id := todo.ID
+		for steps := 0; ; steps++ {
+			parent, found := parents[id]
+			if !found {
+				break
+			}
+			if steps > len(todos) {
+				return true
+			}
+			id = parent
+		}
+	}
+	return false
+}
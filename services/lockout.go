@@ -0,0 +1,38 @@
+package services
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attemptKeys are the lockout keys for a signin attempt, one for the account and one for the client ip
+func attemptKeys(ctxt *gin.Context, email string) (string, string) {
+	return "account:" + strings.ToLower(strings.TrimSpace(email)), "ip:" + ctxt.ClientIP()
+}
+
+// checkAttempts returns a *lockout.Error when the account or the client ip has to wait
+func (ds todoService) checkAttempts(accountKey, ipKey string) error {
+	if err := ds.accountGuard.Check(accountKey); err != nil {
+		return err
+	}
+	return ds.ipGuard.Check(ipKey)
+}
+
+func (ds todoService) failAttempt(accountKey, ipKey string) {
+	if err := ds.accountGuard.Fail(accountKey); err != nil {
+		log.Println(err)
+	}
+	if err := ds.ipGuard.Fail(ipKey); err != nil {
+		log.Println(err)
+	}
+}
+
+// succeedAttempt clears the account failures, the ip ones wear off on their own
+// so one valid account can not be used to keep guessing others
+func (ds todoService) succeedAttempt(accountKey string) {
+	if err := ds.accountGuard.Succeed(accountKey); err != nil {
+		log.Println(err)
+	}
+}
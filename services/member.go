@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//InviteMember method shares a category the current user manages with another user, only the owner
+//of the category can make admins. It answers the same whether an account has the email or not.
+func (ds todoService) InviteMember(ctxt *gin.Context, input model.InviteMember) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if err := checkMemberRole(input.Role); err != nil {
+		return err
+	}
+	if err := ds.authorizeRole(id, input.CategoryId, input.Role); err != nil {
+		return err
+	}
+	owner, err := ds.todoDatabase.GetCategoryUserById(input.CategoryId)
+	if err != nil {
+		return errors.New("category does not exist for this user")
+	}
+	// an unknown email, the owner and members are skipped quietly, so inviting does not tell which emails have accounts
+	user, err := ds.todoDatabase.FindUserByEmail(strings.TrimSpace(input.Email))
+	if err != nil {
+		log.Printf("category %d shared with unknown email %s", input.CategoryId, input.Email)
+		return nil
+	}
+	if user.ID == *owner {
+		return nil
+	}
+	if _, err := ds.todoDatabase.GetCategoryMember(input.CategoryId, user.ID); err == nil {
+		return nil
+	}
+	member := &model.CategoryMember{
+		CategoryId: input.CategoryId,
+		UserId:     user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       input.Role,
+		InvitedBy:  id.(int),
+		CreatedAt:  time.Now().Unix(),
+	}
+	err = ds.todoDatabase.AddCategoryMember(member)
+	if err != nil {
+		return errors.New("unable to add member")
+	}
+	// the member is added either way, a mail which can not be sent is only logged
+	if category, err := ds.todoDatabase.GetCategoryById(*owner, input.CategoryId); err == nil {
+		body := fmt.Sprintf("Hi %s,\n\nthe list %q was shared with you as %s, you will find its todos next to yours.",
+			user.Name, category.Name, input.Role)
+		if err := ds.mailer.Send(user.Email, "A list was shared with you", body); err != nil {
+			log.Println(err)
+		}
+	}
+	return nil
+}
+
+//GetMembers method lists the members of a category the current user can see
+func (ds todoService) GetMembers(ctxt *gin.Context, categoryId int) (*[]model.CategoryMember, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if err := ds.authorizeCategory(id, categoryId, accessView); err != nil {
+		return nil, err
+	}
+	members, err := ds.todoDatabase.GetCategoryMembers(categoryId)
+	if err != nil {
+		return nil, errors.New("unable to fetch members")
+	}
+	return members, nil
+}
+
+//EditMember method changes the role of a member of a category the current user manages, only the owner
+//of the category can make or unmake admins
+func (ds todoService) EditMember(ctxt *gin.Context, input model.EditMember) (*model.CategoryMember, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if err := checkMemberRole(input.Role); err != nil {
+		return nil, err
+	}
+	if err := ds.authorizeRole(id, input.CategoryId, input.Role); err != nil {
+		return nil, err
+	}
+	member, err := ds.todoDatabase.GetCategoryMember(input.CategoryId, input.UserId)
+	if err != nil {
+		return nil, errors.New("user is no member of the category")
+	}
+	if err := ds.authorizeRole(id, input.CategoryId, member.Role); err != nil {
+		return nil, err
+	}
+	err = ds.todoDatabase.UpdateCategoryMember(input.CategoryId, input.UserId, input.Role)
+	if err != nil {
+		return nil, errors.New("unable to change member")
+	}
+	member.Role = input.Role
+	return member, nil
+}
+
+//RemoveMember method stops sharing a category the current user manages with a member, any member
+//can remove itself to leave the category
+func (ds todoService) RemoveMember(ctxt *gin.Context, categoryId, userId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	member, err := ds.todoDatabase.GetCategoryMember(categoryId, userId)
+	if userId != id {
+		role := ""
+		if member != nil {
+			role = member.Role
+		}
+		if err := ds.authorizeRole(id, categoryId, role); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return errors.New("user is no member of the category")
+	}
+	_, err = ds.todoDatabase.DeleteCategoryMember(categoryId, userId)
+	if err != nil {
+		return errors.New("unable to remove member")
+	}
+	return nil
+}
+
+// authorizeRole checks the user may hand out or take back the role in the category,
+// admins are up to the owner
+func (ds todoService) authorizeRole(userId interface{}, categoryId int, role string) error {
+	if err := ds.authorizeCategory(userId, categoryId, accessManage); err != nil {
+		return err
+	}
+	if role == constants.MemberAdmin && ds.categoryAccess(userId.(int), categoryId) < accessOwn {
+		return errors.New("only the owner of the category can make or unmake admins")
+	}
+	return nil
+}
+
+func checkMemberRole(role string) error {
+	if _, ok := memberAccess[role]; !ok {
+		return &model.FieldError{Field: "role", Message: fmt.Sprintf("must be %s, %s or %s",
+			constants.MemberViewer, constants.MemberEditor, constants.MemberAdmin)}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"todo/auth"
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//ForgotPassword method mails a single use password reset token to the user. It answers the same whether
+//the account exists or not, only a *lockout.Error is returned when the email or client ip asked too often.
+func (ds todoService) ForgotPassword(ctxt *gin.Context, email string) error {
+	emailKey := "reset:" + strings.ToLower(strings.TrimSpace(email))
+	ipKey := "reset-ip:" + ctxt.ClientIP()
+	if err := ds.resetGuard.Check(emailKey); err != nil {
+		return err
+	}
+	if err := ds.resetGuard.Check(ipKey); err != nil {
+		return err
+	}
+	for _, key := range []string{emailKey, ipKey} {
+		if err := ds.resetGuard.Fail(key); err != nil {
+			log.Println(err)
+		}
+	}
+	user, err := ds.todoDatabase.FindUserByEmail(email)
+	if err != nil {
+		// do not reveal whether the account exists
+		log.Printf("password reset requested for unknown email %s", email)
+		return nil
+	}
+	// nor whether the mail could be sent to it
+	if err := ds.sendPasswordReset(user); err != nil {
+		log.Printf("password reset for user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// sendPasswordReset stores a new reset token for the user and mails the link to it
+func (ds todoService) sendPasswordReset(user *model.User) error {
+	token, err := auth.NewRandomToken()
+	if err != nil {
+		return err
+	}
+	reset := model.PasswordReset{
+		UserId:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(constants.PasswordResetTTL).Unix(),
+		CreatedAt: time.Now().Unix(),
+	}
+	err = ds.todoDatabase.CreatePasswordReset(&reset)
+	if err != nil {
+		return errors.New("unable to create password reset")
+	}
+	body := fmt.Sprintf("Hi %s,\n\nuse the link below to choose a new password, it expires in %s.\n\n%s\n\nIf you did not ask for this, you can ignore this mail.",
+		user.Name, constants.PasswordResetTTL, link("/reset-password", token))
+	err = ds.mailer.Send(user.Email, "Reset your password", body)
+	if err != nil {
+		log.Println(err)
+		return errors.New("unable to send password reset mail")
+	}
+	return nil
+}
+
+//ResetPassword method sets a new password using a reset token and signs the user out everywhere
+func (ds todoService) ResetPassword(ctxt *gin.Context, input model.ResetPassword) error {
+	reset, err := ds.todoDatabase.GetPasswordResetByToken(auth.HashToken(input.Token))
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+	// mark the token as used first, so it can never be used twice
+	effect, err := ds.todoDatabase.UsePasswordReset(reset.ID, time.Now().Unix())
+	if err != nil || effect == 0 {
+		return errors.New("invalid or expired reset token")
+	}
+	hashed, err := model.Hash(input.Password)
+	if err != nil {
+		return err
+	}
+	err = ds.todoDatabase.UpdateUserPassword(reset.UserId, string(hashed))
+	if err != nil {
+		return errors.New("unable to reset password")
+	}
+	// any other outstanding reset token, every session and every personal access token of the user stop working
+	err = ds.todoDatabase.InvalidateUserPasswordResets(reset.UserId)
+	if err != nil {
+		return err
+	}
+	return ds.revokeAllTokens(reset.UserId)
+}
+
+// link builds an absolute link carrying a token, for mails sent to the user
+func link(path, token string) string {
+	base := os.Getenv(constants.BaseURLEnv)
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return base + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"todo/constants"
+	"todo/model"
+)
+
+// what a user may do with a todo or a category, every level includes the ones before it
+const (
+	accessNone = iota
+	accessView
+	accessEdit
+	// accessManage covers the members and the workflow of a category
+	accessManage
+	// accessOwn covers deleting the category and handing out the admin role
+	accessOwn
+)
+
+// memberAccess is what each member role allows in a shared category
+var memberAccess = map[string]int{
+	constants.MemberViewer: accessView,
+	constants.MemberEditor: accessEdit,
+	constants.MemberAdmin:  accessManage,
+}
+
+var accessVerbs = map[int]string{
+	accessView:   "view",
+	accessEdit:   "change",
+	accessManage: "manage",
+	accessOwn:    "delete",
+}
+
+// categoryAccess is what the user may do in the category: everything as its owner,
+// what the role allows as a member and nothing otherwise
+func (ds todoService) categoryAccess(userId, categoryId int) int {
+	owner, err := ds.todoDatabase.GetCategoryUserById(categoryId)
+	if err != nil {
+		return accessNone
+	}
+	if *owner == userId {
+		return accessOwn
+	}
+	member, err := ds.todoDatabase.GetCategoryMember(categoryId, userId)
+	if err != nil {
+		return accessNone
+	}
+	return memberAccess[member.Role]
+}
+
+// categoryOwner finds the owner of a category, also while it is in the trash, found is false once
+// the category is purged and its todos fall back to the users who created them
+func (ds todoService) categoryOwner(categoryId int) (owner int, found bool) {
+	if userId, err := ds.todoDatabase.GetCategoryUserById(categoryId); err == nil {
+		return *userId, true
+	}
+	if category, err := ds.todoDatabase.GetTrashedCategoryById(categoryId); err == nil {
+		return category.UserId, true
+	}
+	return 0, false
+}
+
+// authorizeCategory checks the user may do what need stands for in the category
+func (ds todoService) authorizeCategory(userId interface{}, categoryId int, need int) error {
+	access := ds.categoryAccess(userId.(int), categoryId)
+	if access == accessNone {
+		return errors.New("category does not exist for this user")
+	}
+	if access < need {
+		return fmt.Errorf("not Authorized to %s this category", accessVerbs[need])
+	}
+	return nil
+}
+
+// todoAccess is what the user may do with the todo: in a category of another user what the category
+// allows, also with the todos the user created there, and everything with its own todos otherwise
+func (ds todoService) todoAccess(userId int, todo *model.Todo) int {
+	if todo.Category != 0 {
+		// a member who left or was demoted keeps no more than the membership gives on its own todos
+		if owner, found := ds.categoryOwner(todo.Category); found && owner != userId {
+			return ds.categoryAccess(userId, todo.Category)
+		}
+	}
+	if todo.UserId == userId {
+		return accessOwn
+	}
+	if todo.Category == 0 {
+		return accessNone
+	}
+	return ds.categoryAccess(userId, todo.Category)
+}
+
+// authorizedTodo fetches a todo the user may do what need stands for with
+func (ds todoService) authorizedTodo(userId interface{}, todoId int, need int) (*model.Todo, error) {
+	todo, err := ds.todoDatabase.GetTodoById(fmt.Sprint(todoId))
+	if err != nil {
+		return nil, errors.New("todo does not exist")
+	}
+	if ds.todoAccess(userId.(int), todo) < need {
+		return nil, fmt.Errorf("not Authorized to %s this todo", accessVerbs[need])
+	}
+	return todo, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"todo/constants"
+	"todo/database"
+	"todo/model"
+)
+
+// permissionDatabase answers the category lookups of the permission checks from maps,
+// any other call panics on the nil TodoDatabase
+type permissionDatabase struct {
+	database.TodoDatabase
+	owners  map[int]int
+	trashed map[int]int
+	members map[int]map[int]string
+}
+
+// GetCategoryUserById finds categories in the trash as well, like the sql it stands for
+func (d permissionDatabase) GetCategoryUserById(id int) (*int, error) {
+	owner, found := d.owners[id]
+	if !found {
+		owner, found = d.trashed[id]
+	}
+	if !found {
+		return nil, errors.New("no category")
+	}
+	return &owner, nil
+}
+
+func (d permissionDatabase) GetTrashedCategoryById(categoryId int) (*model.Category, error) {
+	owner, found := d.trashed[categoryId]
+	if !found {
+		return nil, errors.New("no category")
+	}
+	return &model.Category{ID: categoryId, UserId: owner}, nil
+}
+
+func (d permissionDatabase) GetCategoryMember(categoryId, userId int) (*model.CategoryMember, error) {
+	role, found := d.members[categoryId][userId]
+	if !found {
+		return nil, errors.New("no member")
+	}
+	return &model.CategoryMember{CategoryId: categoryId, UserId: userId, Role: role}, nil
+}
+
+func TestTodoAccess(t *testing.T) {
+	const (
+		owner = iota + 1
+		viewer
+		editor
+		admin
+		stranger
+		former
+	)
+	const (
+		shared  = 10
+		trashed = 11
+		purged  = 12
+	)
+	ds := todoService{todoDatabase: permissionDatabase{
+		owners:  map[int]int{shared: owner},
+		trashed: map[int]int{trashed: owner},
+		members: map[int]map[int]string{
+			shared: {
+				viewer: constants.MemberViewer,
+				editor: constants.MemberEditor,
+				admin:  constants.MemberAdmin,
+			},
+			trashed: {viewer: constants.MemberViewer},
+		},
+	}}
+	tests := []struct {
+		name     string
+		user     int
+		creator  int
+		category int
+		want     int
+	}{
+		{"own todo without category", owner, owner, 0, accessOwn},
+		{"todo of another user without category", stranger, owner, 0, accessNone},
+		{"owner in its category", owner, owner, shared, accessOwn},
+		{"viewer", viewer, owner, shared, accessView},
+		{"editor", editor, owner, shared, accessEdit},
+		{"admin", admin, owner, shared, accessManage},
+		{"stranger in a shared category", stranger, owner, shared, accessNone},
+		// creating a todo in a shared category gives no more than the role
+		{"editor on its own todo", editor, editor, shared, accessEdit},
+		{"viewer on its own todo", viewer, viewer, shared, accessView},
+		{"owner on a todo of a member", owner, editor, shared, accessOwn},
+		{"member who left on its own todo", former, former, shared, accessNone},
+		// the category is still shared while it is in the trash
+		{"owner in its trashed category", owner, owner, trashed, accessOwn},
+		{"viewer in a trashed category", viewer, owner, trashed, accessView},
+		// once the category is purged its todos are back with the users who created them
+		{"creator after a purge", viewer, viewer, purged, accessOwn},
+		{"owner after a purge", owner, viewer, purged, accessNone},
+	}
+	for _, test := range tests {
+		todo := &model.Todo{ID: 1, UserId: test.creator, Category: test.category}
+		if got := ds.todoAccess(test.user, todo); got != test.want {
+			t.Errorf("%s: todoAccess = %d, want %d", test.name, got, test.want)
+		}
+	}
+}
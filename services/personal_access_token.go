@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"todo/auth"
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//CreatePersonalAccessToken method creates a named token for scripts, the token itself is only returned once
+func (ds todoService) CreatePersonalAccessToken(ctxt *gin.Context, input model.NewPersonalAccessToken) (*model.PersonalAccessTokenResponse, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if len(input.Scopes) == 0 {
+		return nil, errors.New("please provide at least one scope")
+	}
+	for _, scope := range input.Scopes {
+		if !validScope(scope) {
+			return nil, errors.New("invalid scope " + scope)
+		}
+	}
+	if input.ExpiresInDays < 0 {
+		return nil, errors.New("expiresInDays can not be negative")
+	}
+	random, err := auth.NewRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	token := constants.PersonalAccessTokenPrefix + random
+	pat := model.PersonalAccessToken{
+		UserId:    id.(int),
+		Name:      input.Name,
+		TokenHash: auth.HashToken(token),
+		Scopes:    input.Scopes,
+		CreatedAt: time.Now().Unix(),
+	}
+	// no expiry unless asked for
+	if input.ExpiresInDays > 0 {
+		pat.ExpiresAt = time.Now().AddDate(0, 0, input.ExpiresInDays).Unix()
+	}
+	err = ds.todoDatabase.CreatePersonalAccessToken(&pat)
+	if err != nil {
+		return nil, errors.New("unable to create token")
+	}
+	return &model.PersonalAccessTokenResponse{
+		Token:               token,
+		PersonalAccessToken: pat,
+	}, nil
+}
+
+//GetPersonalAccessTokens method lists the personal access tokens of the current user
+func (ds todoService) GetPersonalAccessTokens(ctxt *gin.Context) (*[]model.PersonalAccessToken, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	tokens, err := ds.todoDatabase.GetPersonalAccessTokens(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch tokens")
+	}
+	return tokens, nil
+}
+
+//RevokePersonalAccessToken method revokes one of the personal access tokens of the current user
+func (ds todoService) RevokePersonalAccessToken(ctxt *gin.Context, tokenId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	effect, err := ds.todoDatabase.RevokePersonalAccessToken(id.(int), tokenId)
+	if err != nil {
+		return errors.New("unable to revoke token")
+	}
+	if effect == 0 {
+		return errors.New("token not found")
+	}
+	return nil
+}
+
+func validScope(scope string) bool {
+	for _, known := range constants.Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"todo/constants"
+	"todo/model"
+	"todo/recurrence"
+
+	"github.com/gin-gonic/gin"
+)
+
+//SkipOccurrence method moves a recurring todo on to its next occurrence without completing it
+func (ds todoService) SkipOccurrence(ctxt *gin.Context, todoId int) (*model.Todo, error) {
+	todo, rule, err := ds.getRecurringTodo(ctxt, todoId)
+	if err != nil {
+		return nil, err
+	}
+	if todo.DueDate.IsZero() {
+		return nil, errors.New("todo has no due date")
+	}
+	next, found := nextDueDate(todo, rule, ds.userLocation(todo.UserId), false)
+	if !found {
+		return nil, errors.New("this is the last occurrence of the series, end the series instead")
+	}
+	todo.DueDate = next
+	todo.Occurrence++
+	err = ds.todoDatabase.UpdateTodoOccurrence(todo.ID, todo.DueDate.Time, todo.Occurrence)
+	if err != nil {
+		return nil, errors.New("unable to skip occurrence")
+	}
+	todos, err := ds.prepareTodos(todo.UserId, &[]model.Todo{*todo})
+	if err != nil {
+		return nil, err
+	}
+	return &(*todos)[0], nil
+}
+
+//EndSeries method stops a recurring todo, the open occurrence stays as the last one of the series
+func (ds todoService) EndSeries(ctxt *gin.Context, todoId int) error {
+	todo, _, err := ds.getRecurringTodo(ctxt, todoId)
+	if err != nil {
+		return err
+	}
+	err = ds.todoDatabase.EndSeries(seriesOf(todo))
+	if err != nil {
+		return errors.New("unable to end series")
+	}
+	return nil
+}
+
+// getRecurringTodo fetches a recurring todo of the current user with its rule
+func (ds todoService) getRecurringTodo(ctxt *gin.Context, todoId int) (*model.Todo, *recurrence.Rule, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.todoDatabase.GetTodoById(fmt.Sprint(todoId))
+	if err != nil {
+		return nil, nil, errors.New("todo does not exist")
+	}
+	// check if the current user may change the todo
+	if ds.todoAccess(id.(int), todo) < accessEdit {
+		return nil, nil, errors.New("not Authorized to change this todo")
+	}
+	if todo.Recurrence == "" {
+		return nil, nil, errors.New("todo does not recur")
+	}
+	rule, err := recurrence.Parse(todo.Recurrence)
+	if err != nil {
+		return nil, nil, err
+	}
+	return todo, rule, nil
+}
+
+// checkRecurrence validates a recurrence rule and fills in the default anchor
+func checkRecurrence(rule string, anchor *string) error {
+	if *anchor == "" {
+		*anchor = constants.RecurrenceAnchorDue
+	}
+	if *anchor != constants.RecurrenceAnchorDue && *anchor != constants.RecurrenceAnchorCompletion {
+		return fmt.Errorf("recurrenceAnchor must be %s or %s", constants.RecurrenceAnchorDue, constants.RecurrenceAnchorCompletion)
+	}
+	if rule == "" {
+		return nil
+	}
+	_, err := recurrence.Parse(rule)
+	return err
+}
+
+// seriesOf is the id of the first todo of the series the todo belongs to
+func seriesOf(todo *model.Todo) int {
+	if todo.SeriesId != nil {
+		return *todo.SeriesId
+	}
+	return todo.ID
+}
+
+// nextOccurrence creates the next todo of the series once the user completes an occurrence,
+// completing the same occurrence again does not create a second one
+func (ds todoService) nextOccurrence(userId int, todo *model.Todo) {
+	if todo.Recurrence == "" {
+		return
+	}
+	rule, err := recurrence.Parse(todo.Recurrence)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if todo.DueDate.IsZero() {
+		return
+	}
+	fromCompletion := todo.RecurrenceAnchor == constants.RecurrenceAnchorCompletion
+	next, found := nextDueDate(todo, rule, ds.userLocation(todo.UserId), fromCompletion)
+	if !found {
+		return
+	}
+	series := seriesOf(todo)
+	if _, err := ds.todoDatabase.GetSeriesOccurrence(series, todo.Occurrence+1); err == nil {
+		return
+	}
+	nextTodo := model.Todo{
+		Title:            todo.Title,
+		Description:      todo.Description,
+		DueDate:          next,
+		AllDay:           todo.AllDay,
+		Priority:         todo.Priority,
+		Status:           ds.initialStatus(todo.UserId, todo.Category, false),
+		UserId:           todo.UserId,
+		Category:         todo.Category,
+		ParentId:         todo.ParentId,
+		Recurrence:       todo.Recurrence,
+		RecurrenceAnchor: todo.RecurrenceAnchor,
+		SeriesId:         &series,
+		Occurrence:       todo.Occurrence + 1,
+	}
+	if err := ds.todoDatabase.AddTodo(&nextTodo); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := ds.todoDatabase.CopyOffsetReminders(todo.ID, nextTodo.ID); err != nil {
+		log.Println(err)
+	}
+	// the assignee carries over like any assignment, unless it lost access to the list meanwhile
+	if todo.AssigneeId != nil {
+		if err := ds.checkAssignee(&nextTodo, todo.AssigneeId); err != nil {
+			log.Println(err)
+		} else if err := ds.assign(userId, &nextTodo, todo.AssigneeId); err != nil {
+			log.Println(err)
+		}
+	}
+	todoTags, err := ds.todoDatabase.GetTodoTags(todo.UserId)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if len(todoTags[todo.ID]) > 0 {
+		if err := ds.todoDatabase.SetTodoTags(todo.UserId, nextTodo.ID, todoTags[todo.ID]); err != nil {
+			log.Println(err)
+		}
+	}
+}
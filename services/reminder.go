@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddReminder method attaches a reminder for the current user to a todo it can see, either at a fixed time
+//or a number of minutes before the due date
+func (ds todoService) AddReminder(ctxt *gin.Context, todoId int, input model.NewReminder) (*model.Reminder, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.getReminderTodo(ctxt, todoId)
+	if err != nil {
+		return nil, err
+	}
+	if (input.RemindAt == nil) == (input.OffsetMinutes == nil) {
+		return nil, errors.New("please provide either remindAt or offsetMinutes")
+	}
+	if input.RemindAt != nil && !input.RemindAt.After(time.Now()) {
+		return nil, errors.New("remindAt must be in the future")
+	}
+	if input.OffsetMinutes != nil {
+		if *input.OffsetMinutes < 0 {
+			return nil, errors.New("offsetMinutes can not be negative")
+		}
+		if todo.DueDate.IsZero() {
+			return nil, errors.New("todo has no due date to remind before")
+		}
+	}
+	reminder := model.Reminder{
+		TodoId:        todo.ID,
+		UserId:        id.(int),
+		RemindAt:      input.RemindAt,
+		OffsetMinutes: input.OffsetMinutes,
+		Status:        constants.ReminderPending,
+		CreatedAt:     time.Now().Unix(),
+	}
+	err = ds.todoDatabase.AddReminder(&reminder)
+	if err != nil {
+		return nil, errors.New("unable to add reminder")
+	}
+	return ds.todoDatabase.GetReminderById(id.(int), reminder.ID)
+}
+
+//GetReminders method fetches the reminders the current user set on a todo it can see
+func (ds todoService) GetReminders(ctxt *gin.Context, todoId int) (*[]model.Reminder, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.getReminderTodo(ctxt, todoId)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := ds.todoDatabase.GetReminders(todo.ID, id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch reminders")
+	}
+	location := ds.userLocation(id.(int))
+	for i := range *reminders {
+		if at := (*reminders)[i].FireAt; at != nil {
+			*at = at.In(location)
+		}
+		if at := (*reminders)[i].RemindAt; at != nil {
+			*at = at.In(location)
+		}
+	}
+	return reminders, nil
+}
+
+//DeleteReminder method removes a reminder of the current user
+func (ds todoService) DeleteReminder(ctxt *gin.Context, reminderId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if _, err := ds.todoDatabase.GetReminderById(id.(int), reminderId); err != nil {
+		return errors.New("reminder does not exist for this user")
+	}
+	err := ds.todoDatabase.DeleteReminder(reminderId)
+	if err != nil {
+		return errors.New("unable to delete reminder")
+	}
+	return nil
+}
+
+// getReminderTodo fetches a todo the current user can see to attach reminders to, the reminders
+// go to the user who set them
+func (ds todoService) getReminderTodo(ctxt *gin.Context, todoId int) (*model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	return ds.authorizedTodo(id, todoId, accessView)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"todo/constants"
+	"todo/database"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrSearchUnavailable is returned when the server was built without full-text search
+var ErrSearchUnavailable = errors.New("search is not available on this server")
+
+//SearchTodos method searches the titles and descriptions of the todos of the current user, best matches first
+func (ds todoService) SearchTodos(ctxt *gin.Context, input string, limit int) (*[]model.SearchResult, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if strings.TrimSpace(input) == "" {
+		return nil, &model.FieldError{Field: "q", Message: "is required"}
+	}
+	if limit == 0 {
+		limit = constants.DefaultSearchLimit
+	}
+	if limit < 0 || limit > constants.MaxPageSize {
+		return nil, &model.FieldError{Field: "limit", Message: "must be between 1 and 200"}
+	}
+	results, err := ds.todoDatabase.SearchTodos(id.(int), input, limit)
+	if errors.Is(err, database.ErrSearchUnavailable) {
+		return nil, ErrSearchUnavailable
+	}
+	if err != nil {
+		return nil, errors.New("unable to search todos")
+	}
+	todos := make([]model.Todo, len(*results))
+	for i, result := range *results {
+		todos[i] = result.Todo
+	}
+	prepared, err := ds.prepareTodos(id.(int), &todos)
+	if err != nil {
+		return nil, err
+	}
+	for i := range *results {
+		(*results)[i].Todo = (*prepared)[i]
+	}
+	return results, nil
+}
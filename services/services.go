@@ -1,11 +1,21 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"time"
+	"todo/blobstore"
 	"todo/database"
+	"todo/lockout"
+	"todo/mailer"
+	"todo/notifier"
 
 	"todo/auth"
+	"todo/constants"
 	"todo/model"
 	"todo/utils"
 
@@ -18,22 +28,119 @@ type TodoService interface {
 	SignIn(ctxt *gin.Context, user *model.UserLogin) (*model.SignInResponse, error)
 	AddTodo(ctxt *gin.Context, todo *model.Todo) error
 	DeleteTodo(ctxt *gin.Context, todo string) error
-	GetAlltodo(ctxt *gin.Context) (*[]model.Todo, error)
+	GetAlltodo(ctxt *gin.Context, query model.TodoQuery) (*model.TodoPage, error)
 	MarkTodo(ctxt *gin.Context, todoToMark model.MarkTodo) error
 	AddCategory(ctxt *gin.Context, category model.Category) error
 	EditTodo(ctxt *gin.Context, todoInput model.EditTodo) error
 	GetTodoByCategory(ctxt *gin.Context, category_id int) (*[]model.Todo, error)
 	GetCategory(ctxt *gin.Context) (*[]model.Category, error)
 	DeleteCategory(ctxt *gin.Context, category *int) error
+	RefreshToken(ctxt *gin.Context, refreshToken string) (*model.TokenResponse, error)
+	SignOut(ctxt *gin.Context) error
+	ForgotPassword(ctxt *gin.Context, email string) error
+	ResetPassword(ctxt *gin.Context, input model.ResetPassword) error
+	VerifyEmail(ctxt *gin.Context, token string) error
+	ResendVerification(ctxt *gin.Context) error
+	CreatePersonalAccessToken(ctxt *gin.Context, input model.NewPersonalAccessToken) (*model.PersonalAccessTokenResponse, error)
+	GetPersonalAccessTokens(ctxt *gin.Context) (*[]model.PersonalAccessToken, error)
+	RevokePersonalAccessToken(ctxt *gin.Context, tokenId int) error
+	EnrollTOTP(ctxt *gin.Context) (*model.TOTPEnrollment, error)
+	ConfirmTOTP(ctxt *gin.Context, code string) (*model.RecoveryCodes, error)
+	DisableTOTP(ctxt *gin.Context, code string) error
+	SignInTwoFactor(ctxt *gin.Context, input model.TwoFactorSignIn) (*model.SignInResponse, error)
+	SearchUsers(ctxt *gin.Context, query string, limit, offset int) (*[]model.User, error)
+	SetUserDisabled(ctxt *gin.Context, userId int, disabled bool) error
+	ForcePasswordReset(ctxt *gin.Context, userId int) error
+	SetUserRole(ctxt *gin.Context, input model.UserRole) error
+	GetStats(ctxt *gin.Context) (*model.Stats, error)
+	GetMe(ctxt *gin.Context) (*model.User, error)
+	UpdateMe(ctxt *gin.Context, input model.UpdateProfile) (*model.User, error)
+	ChangePassword(ctxt *gin.Context, input model.ChangePassword) error
+	DeleteMe(ctxt *gin.Context, password string) error
+	ExportData(ctxt *gin.Context) (*model.Export, error)
+	ImportData(ctxt *gin.Context, export *model.Export) (*model.ImportResult, error)
+	AddSubtask(ctxt *gin.Context, parentId int, todo *model.Todo) error
+	GetSubtasks(ctxt *gin.Context, parentId int) (*[]model.Todo, error)
+	GetTodoTree(ctxt *gin.Context) (*[]model.Todo, error)
+	AddTag(ctxt *gin.Context, tag *model.Tag) error
+	GetTags(ctxt *gin.Context) (*[]model.Tag, error)
+	EditTag(ctxt *gin.Context, input model.EditTag) error
+	DeleteTag(ctxt *gin.Context, tagId int) error
+	SkipOccurrence(ctxt *gin.Context, todoId int) (*model.Todo, error)
+	EndSeries(ctxt *gin.Context, todoId int) error
+	GetInvalidDueDates(ctxt *gin.Context) (*[]model.InvalidDueDate, error)
+	AddReminder(ctxt *gin.Context, todoId int, input model.NewReminder) (*model.Reminder, error)
+	GetReminders(ctxt *gin.Context, todoId int) (*[]model.Reminder, error)
+	DeleteReminder(ctxt *gin.Context, reminderId int) error
+	SearchTodos(ctxt *gin.Context, input string, limit int) (*[]model.SearchResult, error)
+	AddSmartList(ctxt *gin.Context, list *model.SmartList) error
+	GetSmartLists(ctxt *gin.Context) (*[]model.SmartList, error)
+	EditSmartList(ctxt *gin.Context, input model.EditSmartList) error
+	DeleteSmartList(ctxt *gin.Context, listId int) error
+	GetSmartListTodos(ctxt *gin.Context, listId int, query model.TodoQuery) (*model.TodoPage, error)
+	GetWorkflow(ctxt *gin.Context, category int) (*model.Workflow, error)
+	SetWorkflow(ctxt *gin.Context, workflow *model.Workflow) error
+	DeleteWorkflow(ctxt *gin.Context, category int) error
+	ChangeStatus(ctxt *gin.Context, input model.ChangeStatus) (*model.Todo, error)
+	GetStatusHistory(ctxt *gin.Context, todoId int) (*[]model.StatusChange, error)
+	AddDependency(ctxt *gin.Context, dependency *model.Dependency) error
+	RemoveDependency(ctxt *gin.Context, todoId, blockerId int) error
+	GetDependencies(ctxt *gin.Context, todoId int) (*model.TodoDependencies, error)
+	NextTodos(ctxt *gin.Context, all bool) (*[]model.Todo, error)
+	InviteMember(ctxt *gin.Context, input model.InviteMember) error
+	GetMembers(ctxt *gin.Context, categoryId int) (*[]model.CategoryMember, error)
+	EditMember(ctxt *gin.Context, input model.EditMember) (*model.CategoryMember, error)
+	RemoveMember(ctxt *gin.Context, categoryId, userId int) error
+	AssignTodo(ctxt *gin.Context, input model.AssignTodo) (*model.Todo, error)
+	GetAssignmentHistory(ctxt *gin.Context, todoId int) (*[]model.Assignment, error)
+	GetAssignedTodos(ctxt *gin.Context, query model.TodoQuery) (*model.TodoPage, error)
+	GetCreatedTodos(ctxt *gin.Context, query model.TodoQuery) (*model.TodoPage, error)
+	AddComment(ctxt *gin.Context, comment *model.Comment) error
+	EditComment(ctxt *gin.Context, input model.EditComment) (*model.Comment, error)
+	DeleteComment(ctxt *gin.Context, commentId int) error
+	GetComments(ctxt *gin.Context, todoId, limit, offset int) (*model.CommentPage, error)
+	GetActivity(ctxt *gin.Context, todoId, limit, offset int) (*model.ActivityPage, error)
+	AddAttachment(ctxt *gin.Context, todoId int, file *multipart.FileHeader) (*model.Attachment, error)
+	GetAttachments(ctxt *gin.Context, todoId int) (*[]model.Attachment, error)
+	DownloadAttachment(ctxt *gin.Context, attachmentId int) (*model.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctxt *gin.Context, attachmentId int) error
+	GetStorageUsage(ctxt *gin.Context) (*model.StorageUsage, error)
+	GetTrash(ctxt *gin.Context) (*model.Trash, error)
+	RestoreTodo(ctxt *gin.Context, todoId int) (*model.Todo, error)
+	PurgeTodo(ctxt *gin.Context, todoId int) error
+	RestoreCategory(ctxt *gin.Context, categoryId int) error
+	PurgeCategory(ctxt *gin.Context, categoryId int) error
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
 }
 
+// errors signin reports to the user as they are, every other signin error stays vague
+var (
+	ErrAccountDisabled       = errors.New("account is disabled")
+	ErrPasswordResetRequired = errors.New("password reset required, please check your email")
+)
+
 type todoService struct {
 	todoDatabase database.TodoDatabase
+	mailer       mailer.Mailer
+	accountGuard *lockout.Guard
+	ipGuard      *lockout.Guard
+	// resetGuard throttles password reset mails per email and client ip
+	resetGuard *lockout.Guard
+	// notifiers tell users about todos assigned to them
+	notifiers []notifier.Notifier
+	// blobs keeps the content of attachments
+	blobs blobstore.BlobStore
 }
 
-func NewTodoService(todoDb database.TodoDatabase) TodoService {
+func NewTodoService(todoDb database.TodoDatabase, mail mailer.Mailer, accountGuard, ipGuard, resetGuard *lockout.Guard, notifiers []notifier.Notifier, blobs blobstore.BlobStore) TodoService {
 	return todoService{
 		todoDatabase: todoDb,
+		mailer:       mail,
+		accountGuard: accountGuard,
+		ipGuard:      ipGuard,
+		resetGuard:   resetGuard,
+		notifiers:    notifiers,
+		blobs:        blobs,
 	}
 }
 
@@ -44,6 +151,9 @@ func (ds todoService) SignUp(ctxt *gin.Context, user *model.User) error {
 	if err := checkmail.ValidateFormat(user.Email); err != nil {
 		return err
 	}
+	if err := checkTimeZone(user.TimeZone); err != nil {
+		return err
+	}
 	//check if email already exists
 	EmailExists := ds.todoDatabase.CheckEmailExists(user.Email)
 	if EmailExists {
@@ -55,30 +165,61 @@ func (ds todoService) SignUp(ctxt *gin.Context, user *model.User) error {
 	if errCreate != nil {
 		return errCreate
 	}
+	// the account is usable right away, a failed mail can be resent later
+	if err := ds.sendVerification(user); err != nil {
+		log.Println(err)
+	}
 	return nil
 }
 
 //SignIn methods gives the jwt token and user details provided if user credentials are valid.
 func (ds todoService) SignIn(ctxt *gin.Context, user *model.UserLogin) (*model.SignInResponse, error) {
+	// refuse while the account or client ip is backing off after failed attempts
+	accountKey, ipKey := attemptKeys(ctxt, user.Email)
+	if err := ds.checkAttempts(accountKey, ipKey); err != nil {
+		return nil, err
+	}
 	//fetch the user details from database
 	getuser, err := ds.todoDatabase.FindUserByEmail(user.Email)
 	if err != nil {
+		ds.failAttempt(accountKey, ipKey)
 		return nil, err
 	}
 	// verify the password from database with incoming passwors in request
 	err = utils.VerifyPassword(getuser.Password, user.Password)
 	if err != nil {
+		ds.failAttempt(accountKey, ipKey)
 		return nil, err
 	}
-	// if credentials are validated, create jwt token for the user
-	token, err := auth.CreateToken(getuser.ID)
+	if getuser.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	if getuser.MustResetPassword {
+		return nil, ErrPasswordResetRequired
+	}
+	// with two factor authentication the password alone only earns a challenge token
+	if ds.twoFactorEnabled(getuser.ID) {
+		challenge, err := auth.CreateChallengeToken(getuser.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.SignInResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+	// the account failures are only cleared once a session is issued, not by the password alone
+	ds.succeedAttempt(accountKey)
+	// if credentials are validated, start a session and create jwt token for the user
+	tokens, err := ds.newSession(getuser)
 	if err != nil {
 		return nil, err
 	}
 	// return the response
 	return &model.SignInResponse{
-		Token: token,
-		User:  *getuser,
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		User:         getuser.Public(),
 	}, nil
 }
 
@@ -87,28 +228,78 @@ func (ds todoService) AddTodo(ctxt *gin.Context, todo *model.Todo) error {
 	// fetch the user id from gin context
 	id, _ := ctxt.Get("user-id")
 	todo.UserId = id.(int)
-	// check the user has the category which is mentioned in the request data
+	// check the user may add todos to the category which is mentioned in the request data
 	if todo.Category != 0 {
-		category_user_id, err := ds.todoDatabase.GetCategoryUserById(todo.Category)
+		if err := ds.authorizeCategory(id, todo.Category, accessEdit); err != nil {
+			return err
+		}
+	}
+	// a subtask needs a parent of the same user, and inherits its category
+	if todo.ParentId != nil && *todo.ParentId == 0 {
+		todo.ParentId = nil
+	}
+	if todo.ParentId != nil {
+		parent, err := ds.checkParent(id, 0, *todo.ParentId)
 		if err != nil {
-			return errors.New("category not found for this user")
+			return err
 		}
-		//if the category is not valid, return error
-		if id != *category_user_id {
-			return errors.New("invalid category ID")
+		if todo.Category == 0 {
+			todo.Category = parent.Category
 		}
 	}
+	tags, err := ds.checkTodoTags(id.(int), todo.Tags)
+	if err != nil {
+		return err
+	}
+	// the assignee is saved with its history once the todo exists
+	assigneeId := todo.AssigneeId
+	if assigneeId != nil && *assigneeId == 0 {
+		assigneeId = nil
+	}
+	if err := ds.checkAssignee(todo, assigneeId); err != nil {
+		return err
+	}
+	todo.AssigneeId = nil
+	if err := normalizeDueDate(&todo.DueDate, &todo.AllDay, ds.userLocation(id.(int))); err != nil {
+		return err
+	}
+	// a new todo always starts a series of its own
+	if err := checkRecurrence(todo.Recurrence, &todo.RecurrenceAnchor); err != nil {
+		return err
+	}
+	todo.SeriesId = nil
+	todo.Occurrence = 1
+	// the todo starts in the first state of its workflow unless it asks for another one
+	if todo.Status == "" {
+		todo.Status = ds.initialStatus(id.(int), todo.Category, todo.Completed)
+	}
+	workflow := ds.workflowFor(id.(int), todo.Category)
+	state, found := findState(workflow, todo.Status)
+	if !found {
+		return &model.FieldError{Field: "status", Message: "must be one of " + stateNames(workflow)}
+	}
+	todo.Completed = state.Final
 	// if the request data are all valid, add the todo and save it in the database
-	err := ds.todoDatabase.AddTodo(todo)
+	err = ds.todoDatabase.AddTodo(todo)
 	if err != nil {
 		if err != nil {
 			return err
 		}
 	}
+	if len(tags) > 0 {
+		if err := ds.todoDatabase.SetTodoTags(id.(int), todo.ID, tags); err != nil {
+			return errors.New("unable to tag todo")
+		}
+	}
+	if assigneeId != nil {
+		if err := ds.assign(id.(int), todo, assigneeId); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-//DeleteTodo method deletes a todo
+//DeleteTodo method moves a todo with its subtasks to the trash
 func (ds todoService) DeleteTodo(ctxt *gin.Context, todo string) error {
 	// fetch the user-id from context
 	id, _ := ctxt.Get("user-id")
@@ -124,15 +315,16 @@ func (ds todoService) DeleteTodo(ctxt *gin.Context, todo string) error {
 	if getTodo == nil {
 		return errors.New("todo not found")
 	}
-	//check if the todo belongs to the current user
-	if id == getTodo.UserId {
-		effect, err := ds.todoDatabase.DeleteTodo(todo)
+	//check if the current user may change the todo
+	if ds.todoAccess(id.(int), getTodo) >= accessEdit {
+		effect, err := ds.todoDatabase.TrashTodo(getTodo.ID, time.Now().Unix())
 		if err != nil {
 			return errors.New("unable to delete todo")
 		}
 		if effect == 0 {
 			return errors.New("unable to delete todo")
 		}
+		ds.recordEvent(id.(int), getTodo.ID, constants.ActivityTrashed, "", "")
 
 	} else {
 		return errors.New("not Authorized to delete this todo")
@@ -140,18 +332,28 @@ func (ds todoService) DeleteTodo(ctxt *gin.Context, todo string) error {
 	return nil
 }
 
-//GetAlltodo method fetches all todo that belongs to the current user
-func (ds todoService) GetAlltodo(ctxt *gin.Context) (*[]model.Todo, error) {
-	// fetch the user-id from context
-	id, _ := ctxt.Get("user-id")
-	todos, err := ds.todoDatabase.GetAllTodo(id.(int))
+// prepareTodos fills in what the todo rows do not hold for todos handed out to the user: the tag ids,
+// and the due dates in the time zone of the user. Nested subtasks are included.
+func (ds todoService) prepareTodos(userId int, todos *[]model.Todo) (*[]model.Todo, error) {
+	todoTags, err := ds.todoDatabase.GetTodoTags(userId)
 	if err != nil {
-		return nil, errors.New("unable to fetch todos")
-	}
-	if len(*todos) == 0 {
-		return nil, errors.New("no todos found for this user")
-
+		return nil, errors.New("unable to fetch tags")
+	}
+	location := ds.userLocation(userId)
+	var attach func(todos []model.Todo)
+	attach = func(todos []model.Todo) {
+		for i := range todos {
+			localizeDueDate(&todos[i], location)
+			todos[i].Tags = todoTags[todos[i].ID]
+			if todos[i].Tags == nil {
+				todos[i].Tags = []int{}
+			}
+			if todos[i].Children != nil {
+				attach(*todos[i].Children)
+			}
+		}
 	}
+	attach(*todos)
 	return todos, nil
 }
 
@@ -160,7 +362,6 @@ func (ds todoService) MarkTodo(ctxt *gin.Context, todoToMark model.MarkTodo) err
 	// fetch the user-id from context
 	id, _ := ctxt.Get("user-id")
 	var todo *model.Todo
-	var completed int
 	// get the dod from database
 	todo, err := ds.todoDatabase.GetTodoById(fmt.Sprint(todoToMark.ID))
 	if err != nil {
@@ -169,23 +370,18 @@ func (ds todoService) MarkTodo(ctxt *gin.Context, todoToMark model.MarkTodo) err
 	if todo == nil {
 		return errors.New("todo does not exist")
 	}
-	// check if the todo belongs to the current user
-	if todo.UserId != id {
+	// check if the current user may change the todo
+	if ds.todoAccess(id.(int), todo) < accessEdit {
 		return errors.New("not Authorized to mark this todo")
 	}
-	// convert bool to int
-	if todoToMark.Completed {
-		completed = 1
-	} else {
-		completed = 0
-	}
-	// update status in database
-	err = ds.todoDatabase.UpdateCompleted(completed, todoToMark.ID)
-	if err != nil {
-		return errors.New("unable to mark todo")
+	// a todo waiting for open todos is only completed when forced
+	if todoToMark.Completed && !todo.Completed && !todoToMark.Force {
+		if err := ds.checkBlockers(id.(int), todo); err != nil {
+			return err
+		}
 	}
-	return nil
-
+	// completing moves the todo to a final state of its workflow, reopening to an open one
+	return ds.markCompleted(id.(int), todo, todoToMark.Completed)
 }
 
 //AddCategory method used to add a category
@@ -213,17 +409,96 @@ func (ds todoService) EditTodo(ctxt *gin.Context, todoInput model.EditTodo) erro
 	if err != nil {
 		return errors.New("unable to process todo")
 	}
-	// check if the todo belongs to the current user
-	if todo.UserId != id {
+	// check if the current user may change the todo, and move it to the category it asks for
+	if ds.todoAccess(id.(int), todo) < accessEdit {
 		return errors.New("not Authorized to edit this todo")
 	}
+	if todoInput.Category != 0 && todoInput.Category != todo.Category {
+		if err := ds.authorizeCategory(id, todoInput.Category, accessEdit); err != nil {
+			return err
+		}
+		// the assignee has to follow the todo to its new category
+		moved := *todo
+		moved.Category = todoInput.Category
+		if err := ds.checkAssignee(&moved, todo.AssigneeId); err != nil {
+			return err
+		}
+	}
+	// moving the todo below another todo must not create a cycle
+	if todoInput.ParentId != nil && *todoInput.ParentId != 0 && (todo.ParentId == nil || *todo.ParentId != *todoInput.ParentId) {
+		if _, err := ds.checkParent(id, todo.ID, *todoInput.ParentId); err != nil {
+			return err
+		}
+	}
+	if todoInput.Recurrence != nil || todoInput.RecurrenceAnchor != nil {
+		rule, anchor := todo.Recurrence, todo.RecurrenceAnchor
+		if todoInput.Recurrence != nil {
+			rule = *todoInput.Recurrence
+		}
+		if todoInput.RecurrenceAnchor != nil {
+			anchor = *todoInput.RecurrenceAnchor
+		}
+		if err := checkRecurrence(rule, &anchor); err != nil {
+			return err
+		}
+		todoInput.Recurrence, todoInput.RecurrenceAnchor = &rule, &anchor
+	}
+	var tags []int
+	if todoInput.Tags != nil {
+		tags, err = ds.checkTodoTags(id.(int), *todoInput.Tags)
+		if err != nil {
+			return err
+		}
+	}
 	//map the remaining fields from the database with todo from request
 	editTodoPayload := utils.EditTodoMap(todoInput, *todo)
+	// completed moves the todo through its workflow, the state is checked before anything is saved
+	workflow := ds.workflowFor(todo.UserId, editTodoPayload.Category)
+	status, err := completedStatus(workflow, todo, *editTodoPayload.Completed)
+	if err != nil {
+		return err
+	}
+	if state, _ := findState(workflow, status); state.Final && !todo.Completed {
+		if err := ds.checkBlockers(id.(int), todo); err != nil {
+			return err
+		}
+	}
+	editTodoPayload.Completed = &todo.Completed
+	if todoInput.DueDate != nil || todoInput.AllDay != nil {
+		dueDate, allDay := *editTodoPayload.DueDate, *editTodoPayload.AllDay
+		location := ds.userLocation(id.(int))
+		// an all day todo which gets a time of day keeps its date, due at midnight for the user
+		if todoInput.DueDate == nil && todo.AllDay && !allDay {
+			dueDate = model.DueDate{Time: time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, location)}
+		}
+		if err := normalizeDueDate(&dueDate, &allDay, location); err != nil {
+			return err
+		}
+		editTodoPayload.DueDate, editTodoPayload.AllDay = &dueDate, &allDay
+	}
 	// update database
 	err = ds.todoDatabase.UpdateTodo(&editTodoPayload)
 	if err != nil {
 		return errors.New("unable to edit todo")
 	}
+	if editTodoPayload.Category != todo.Category {
+		ds.recordEvent(id.(int), todo.ID, constants.ActivityCategory, ds.categoryName(todo.Category), ds.categoryName(editTodoPayload.Category))
+	}
+	if todoInput.Tags != nil {
+		if err := ds.todoDatabase.SetTodoTags(id.(int), todo.ID, tags); err != nil {
+			return errors.New("unable to tag todo")
+		}
+	}
+	if status != todo.Status {
+		edited, err := ds.todoDatabase.GetTodoById(fmt.Sprint(todo.ID))
+		if err != nil {
+			return errors.New("unable to process todo")
+		}
+		return ds.recordStatus(id.(int), edited, workflow, status)
+	}
+	if todo.Completed {
+		ds.rollupCompletion(editTodoPayload.ParentId)
+	}
 	return nil
 }
 
@@ -231,27 +506,22 @@ func (ds todoService) EditTodo(ctxt *gin.Context, todoInput model.EditTodo) erro
 func (ds todoService) GetTodoByCategory(ctxt *gin.Context, category_id int) (*[]model.Todo, error) {
 	//fetch the  user-id from request
 	id, _ := ctxt.Get("user-id")
-	// fetch the user-id of that particular category to check if it belongs to current user
-	category_user_id, err := ds.todoDatabase.GetCategoryUserById(int(category_id))
-	if err != nil {
-		return nil, errors.New("todo not found")
-	}
-	// if the category mentioned in request does not belong to the current user
-	if id != *category_user_id {
-		return nil, errors.New("not Authorized to view this todos")
+	// check the category belongs to the current user or is shared with it
+	if err := ds.authorizeCategory(id, category_id, accessView); err != nil {
+		return nil, err
 	}
 	//fetch todos based on the same type of category
-	todos, err := ds.todoDatabase.GetAllTodoByCategory(id.(int), category_id)
+	todos, err := ds.todoDatabase.GetAllTodoByCategory(category_id)
 	if err != nil {
 		return nil, errors.New("todo not found")
 	}
 	if len(*todos) == 0 {
 		return nil, errors.New(" no todos found for this category ")
 	}
-	return todos, nil
+	return ds.prepareTodos(id.(int), todos)
 }
 
-//GetCategory fetches all the category that belongs to the logged in user
+//GetCategory fetches all the category that belongs to the logged in user, and the ones shared with it
 func (ds todoService) GetCategory(ctxt *gin.Context) (*[]model.Category, error) {
 	//fetch the user-id from context
 	id, _ := ctxt.Get("user-id")
@@ -259,13 +529,22 @@ func (ds todoService) GetCategory(ctxt *gin.Context) (*[]model.Category, error)
 	if err != nil {
 		return nil, errors.New("no category found for this user")
 	}
+	for i := range *category {
+		(*category)[i].Role = constants.MemberOwner
+	}
+	shared, err := ds.todoDatabase.GetSharedCategories(id.(int))
+	if err != nil {
+		return nil, errors.New("no category found for this user")
+	}
+	*category = append(*category, *shared...)
 	if len(*category) == 0 {
 		return nil, errors.New(" no category found for this user")
 	}
 	return category, nil
 }
 
-//DeleteCategory method deletes the category if provided a valid category id
+//DeleteCategory method moves the category to the trash with its todos if provided a valid category id,
+//its members and workflow stay until it is purged
 func (ds todoService) DeleteCategory(ctxt *gin.Context, category *int) error {
 	// get the user id from context
 	id, _ := ctxt.Get("user-id")
@@ -273,27 +552,17 @@ func (ds todoService) DeleteCategory(ctxt *gin.Context, category *int) error {
 	if category == nil {
 		return errors.New("category id nil")
 	}
-	// get the category and store it in a variable
-	getCategory, err := ds.todoDatabase.GetCategoryById(id.(int), *category)
-	if err != nil {
-		return errors.New("category does not exist for this user")
+	//check if the category actually belongs to the curent user, members can not delete it
+	if err := ds.authorizeCategory(id, *category, accessOwn); err != nil {
+		return err
 	}
-	if getCategory == nil {
-		return errors.New(" category not found")
+	effect, err := ds.todoDatabase.TrashCategory(*category, time.Now().Unix())
+	if err != nil {
+		return errors.New("unable to delete category")
 	}
-	//check if the category actually belongs to the curent user
-	if id == getCategory.UserId {
-		effect, err := ds.todoDatabase.DeleteCategory(*category)
-		if err != nil {
-			return errors.New("unable to delete category")
-		}
-		//if not deleted
-		if effect == 0 {
-			return errors.New("unable to delete category")
-		}
-
-	} else {
-		return errors.New("not Authorized to delete this category")
+	//if not deleted
+	if effect == 0 {
+		return errors.New("unable to delete category")
 	}
 	return nil
 }
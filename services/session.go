@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"todo/auth"
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//RefreshToken method rotates the refresh token of a session and issues a new jwt token for it
+func (ds todoService) RefreshToken(ctxt *gin.Context, refreshToken string) (*model.TokenResponse, error) {
+	// look up the session the refresh token belongs to
+	session, err := ds.todoDatabase.GetSessionByRefreshToken(auth.HashToken(refreshToken))
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+	if session.Revoked {
+		return nil, errors.New("session has been revoked")
+	}
+	if session.ExpiresAt < time.Now().Unix() {
+		return nil, errors.New("refresh token expired")
+	}
+	// the role is read again, so a changed role shows up on the next refresh
+	user, err := ds.todoDatabase.GetUserById(session.UserId)
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	// rotate the refresh token, the old one cannot be used again
+	newRefreshToken, err := auth.NewRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(constants.RefreshTokenTTL).Unix()
+	effect, err := ds.todoDatabase.RotateSession(session.ID, session.RefreshTokenHash, auth.HashToken(newRefreshToken), expiresAt)
+	if err != nil {
+		return nil, errors.New("unable to refresh token")
+	}
+	// a concurrent signout or rotation got there first
+	if effect == 0 {
+		return nil, errors.New("invalid refresh token")
+	}
+	token, err := auth.CreateToken(user.ID, session.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenResponse{
+		Token:        token,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+//SignOut method revokes the session of the current token
+func (ds todoService) SignOut(ctxt *gin.Context) error {
+	// fetch the session-id from context
+	sessionId, _ := ctxt.Get("session-id")
+	err := ds.todoDatabase.RevokeSession(sessionId.(int))
+	if err != nil {
+		return errors.New("unable to sign out")
+	}
+	return nil
+}
+
+// newSession stores a new session for the user and returns its jwt and refresh token
+func (ds todoService) newSession(user *model.User) (*model.TokenResponse, error) {
+	refreshToken, err := auth.NewRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	session := model.Session{
+		UserId:           user.ID,
+		RefreshTokenHash: auth.HashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(constants.RefreshTokenTTL).Unix(),
+		CreatedAt:        time.Now().Unix(),
+	}
+	err = ds.todoDatabase.CreateSession(&session)
+	if err != nil {
+		return nil, err
+	}
+	token, err := auth.CreateToken(user.ID, session.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
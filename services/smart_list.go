@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"todo/filter"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddSmartList method saves a named filter expression for the current user
+func (ds todoService) AddSmartList(ctxt *gin.Context, list *model.SmartList) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	list.UserId = id.(int)
+	if err := ds.checkSmartList(list); err != nil {
+		return err
+	}
+	err := ds.todoDatabase.AddSmartList(list)
+	if err != nil {
+		return errors.New("unable to add smart list")
+	}
+	return nil
+}
+
+//GetSmartLists method fetches all the smart lists of the current user
+func (ds todoService) GetSmartLists(ctxt *gin.Context) (*[]model.SmartList, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	lists, err := ds.todoDatabase.GetSmartLists(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch smart lists")
+	}
+	return lists, nil
+}
+
+//EditSmartList method renames a smart list or changes its query, empty fields keep their value
+func (ds todoService) EditSmartList(ctxt *gin.Context, input model.EditSmartList) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	list, err := ds.todoDatabase.GetSmartListById(id.(int), input.ID)
+	if err != nil {
+		return errors.New("smart list does not exist for this user")
+	}
+	if input.Name != "" {
+		list.Name = input.Name
+	}
+	if input.Query != "" {
+		list.Query = input.Query
+	}
+	if err := ds.checkSmartList(list); err != nil {
+		return err
+	}
+	err = ds.todoDatabase.UpdateSmartList(list)
+	if err != nil {
+		return errors.New("unable to edit smart list")
+	}
+	return nil
+}
+
+//DeleteSmartList method deletes a smart list, its todos are not touched
+func (ds todoService) DeleteSmartList(ctxt *gin.Context, listId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if _, err := ds.todoDatabase.GetSmartListById(id.(int), listId); err != nil {
+		return errors.New("smart list does not exist for this user")
+	}
+	err := ds.todoDatabase.DeleteSmartList(listId)
+	if err != nil {
+		return errors.New("unable to delete smart list")
+	}
+	return nil
+}
+
+//GetSmartListTodos method fetches a page of the todos matching a smart list, the query narrows them further
+func (ds todoService) GetSmartListTodos(ctxt *gin.Context, listId int, query model.TodoQuery) (*model.TodoPage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	list, err := ds.todoDatabase.GetSmartListById(id.(int), listId)
+	if err != nil {
+		return nil, errors.New("smart list does not exist for this user")
+	}
+	expr, err := filter.Parse(list.Query)
+	if err != nil {
+		return nil, filterError("query", err)
+	}
+	return ds.listTodos(id.(int), query, expr)
+}
+
+// checkSmartList validates the name and query of a smart list, names are unique per user regardless of case
+func (ds todoService) checkSmartList(list *model.SmartList) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return errors.New("please provide a smart list name")
+	}
+	expr, err := filter.Parse(list.Query)
+	if err != nil {
+		return filterError("query", err)
+	}
+	if expr == nil {
+		return &model.FieldError{Field: "query", Message: "is required"}
+	}
+	lists, err := ds.todoDatabase.GetSmartLists(list.UserId)
+	if err != nil {
+		return errors.New("unable to fetch smart lists")
+	}
+	for _, other := range *lists {
+		if other.ID != list.ID && strings.EqualFold(other.Name, list.Name) {
+			return errors.New("smart list " + list.Name + " already exists")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"todo/constants"
+	"todo/model"
+	"todo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//AddSubtask method adds a todo below an existing todo of the current user
+func (ds todoService) AddSubtask(ctxt *gin.Context, parentId int, todo *model.Todo) error {
+	todo.ParentId = &parentId
+	return ds.AddTodo(ctxt, todo)
+}
+
+//GetSubtasks method fetches the direct subtasks of a todo
+func (ds todoService) GetSubtasks(ctxt *gin.Context, parentId int) (*[]model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	parent, err := ds.todoDatabase.GetTodoById(fmt.Sprint(parentId))
+	if err != nil {
+		return nil, errors.New("todo does not exist")
+	}
+	// check if the current user may see the todo
+	if ds.todoAccess(id.(int), parent) < accessView {
+		return nil, errors.New("not Authorized to view this todo")
+	}
+	children, err := ds.todoDatabase.GetChildTodos(parentId)
+	if err != nil {
+		return nil, errors.New("unable to fetch subtasks")
+	}
+	if *children == nil {
+		*children = []model.Todo{}
+	}
+	return ds.prepareTodos(id.(int), children)
+}
+
+//GetTodoTree method fetches all todos of the current user and the shared ones nested below their parents
+func (ds todoService) GetTodoTree(ctxt *gin.Context) (*[]model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todos, err := ds.todoDatabase.GetVisibleTodos(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch todos")
+	}
+	if *todos == nil {
+		*todos = []model.Todo{}
+	}
+	todos, err = ds.prepareTodos(id.(int), todos)
+	if err != nil {
+		return nil, err
+	}
+	return buildTodoTree(*todos), nil
+}
+
+// buildTodoTree nests the flat list, todos whose parent is missing from the list stay at the top
+func buildTodoTree(todos []model.Todo) *[]model.Todo {
+	index := map[int]int{}
+	for i, todo := range todos {
+		index[todo.ID] = i
+	}
+	children := map[int][]int{}
+	var roots []int
+	for i, todo := range todos {
+		if todo.ParentId != nil {
+			if _, found := index[*todo.ParentId]; found {
+				children[*todo.ParentId] = append(children[*todo.ParentId], i)
+				continue
+			}
+		}
+		roots = append(roots, i)
+	}
+	var build func(i int) model.Todo
+	build = func(i int) model.Todo {
+		todo := todos[i]
+		if len(children[todo.ID]) > 0 {
+			nested := []model.Todo{}
+			for _, child := range children[todo.ID] {
+				nested = append(nested, build(child))
+			}
+			todo.Children = &nested
+		}
+		return todo
+	}
+	tree := []model.Todo{}
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return &tree
+}
+
+// checkParent makes sure parentId can become the parent of todoId (0 for a new todo) without a cycle
+// or the tree growing deeper than allowed, it returns the parent
+func (ds todoService) checkParent(userId interface{}, todoId int, parentId int) (*model.Todo, error) {
+	if parentId == todoId {
+		return nil, errors.New("a todo can not be its own subtask")
+	}
+	parent, err := ds.todoDatabase.GetTodoById(fmt.Sprint(parentId))
+	if err != nil {
+		return nil, errors.New("parent todo does not exist")
+	}
+	if ds.todoAccess(userId.(int), parent) < accessEdit {
+		return nil, errors.New("not Authorized to add subtasks to this todo")
+	}
+	ancestors, err := ds.todoDatabase.GetTodoAncestors(parentId)
+	if err != nil {
+		return nil, errors.New("unable to process parent todo")
+	}
+	for _, ancestor := range ancestors {
+		if ancestor == todoId {
+			return nil, errors.New("a todo can not be moved below its own subtask")
+		}
+	}
+	height := 1
+	if todoId != 0 {
+		height, err = ds.todoDatabase.GetSubtreeHeight(todoId)
+		if err != nil {
+			return nil, errors.New("unable to process todo")
+		}
+	}
+	maxDepth := utils.EnvInt(constants.MaxTodoDepthEnv, constants.MaxTodoDepth)
+	// the parent sits at level len(ancestors)+1, the todo and its subtasks go below it
+	if len(ancestors)+1+height > maxDepth {
+		return nil, fmt.Errorf("todos can not be nested deeper than %d levels", maxDepth)
+	}
+	return parent, nil
+}
+
+// rollupCompletion completes the parent once all its subtasks are done, if the parent asked for it,
+// completing the parent carries on up the tree
+func (ds todoService) rollupCompletion(parentId *int) {
+	if parentId == nil {
+		return
+	}
+	parent, err := ds.todoDatabase.GetTodoById(fmt.Sprint(*parentId))
+	if err != nil || !parent.AutoComplete || parent.Completed {
+		return
+	}
+	children, err := ds.todoDatabase.GetChildTodos(parent.ID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, child := range *children {
+		if !child.Completed {
+			return
+		}
+	}
+	// a parent waiting for other todos stays open
+	if err := ds.checkBlockers(parent.UserId, parent); err != nil {
+		return
+	}
+	if err := ds.markCompleted(parent.UserId, parent, true); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tagColor is the #rrggbb form tag colors are stored in
+var tagColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+//AddTag method adds a tag for the current user
+func (ds todoService) AddTag(ctxt *gin.Context, tag *model.Tag) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	tag.UserId = id.(int)
+	if err := ds.checkTag(tag); err != nil {
+		return err
+	}
+	err := ds.todoDatabase.AddTag(tag)
+	if err != nil {
+		return errors.New("unable to add tag")
+	}
+	return nil
+}
+
+//GetTags method fetches all the tags of the current user
+func (ds todoService) GetTags(ctxt *gin.Context) (*[]model.Tag, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	tags, err := ds.todoDatabase.GetTags(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch tags")
+	}
+	return tags, nil
+}
+
+//EditTag method renames or recolors a tag, empty fields keep their value
+func (ds todoService) EditTag(ctxt *gin.Context, input model.EditTag) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	tag, err := ds.todoDatabase.GetTagById(id.(int), input.ID)
+	if err != nil {
+		return errors.New("tag does not exist for this user")
+	}
+	if input.Name != "" {
+		tag.Name = input.Name
+	}
+	if input.Color != "" {
+		tag.Color = input.Color
+	}
+	if err := ds.checkTag(tag); err != nil {
+		return err
+	}
+	err = ds.todoDatabase.UpdateTag(tag)
+	if err != nil {
+		return errors.New("unable to edit tag")
+	}
+	return nil
+}
+
+//DeleteTag method deletes a tag, the todos carrying it are kept
+func (ds todoService) DeleteTag(ctxt *gin.Context, tagId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if _, err := ds.todoDatabase.GetTagById(id.(int), tagId); err != nil {
+		return errors.New("tag does not exist for this user")
+	}
+	err := ds.todoDatabase.DeleteTag(tagId)
+	if err != nil {
+		return errors.New("unable to delete tag")
+	}
+	return nil
+}
+
+// checkTag validates the name and color of a tag and fills in the default color,
+// tag names are unique per user regardless of case
+func (ds todoService) checkTag(tag *model.Tag) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return errors.New("please provide a tag name")
+	}
+	if tag.Color == "" {
+		tag.Color = constants.DefaultTagColor
+	}
+	if !tagColor.MatchString(tag.Color) {
+		return errors.New("tag color must look like #rrggbb")
+	}
+	tags, err := ds.todoDatabase.GetTags(tag.UserId)
+	if err != nil {
+		return errors.New("unable to fetch tags")
+	}
+	for _, other := range *tags {
+		if other.ID != tag.ID && strings.EqualFold(other.Name, tag.Name) {
+			return errors.New("tag " + tag.Name + " already exists")
+		}
+	}
+	return nil
+}
+
+// checkTodoTags makes sure every tag belongs to the user and drops duplicates
+func (ds todoService) checkTodoTags(userId int, tagIds []int) ([]int, error) {
+	tags, err := ds.todoDatabase.GetTags(userId)
+	if err != nil {
+		return nil, errors.New("unable to fetch tags")
+	}
+	owned := map[int]bool{}
+	for _, tag := range *tags {
+		owned[tag.ID] = true
+	}
+	seen := map[int]bool{}
+	checked := []int{}
+	for _, tagId := range tagIds {
+		if !owned[tagId] {
+			return nil, errors.New("tag not found for this user")
+		}
+		if !seen[tagId] {
+			seen[tagId] = true
+			checked = append(checked, tagId)
+		}
+	}
+	return checked, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"todo/constants"
+	"todo/database"
+	"todo/filter"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//GetAlltodo method fetches a page of the todos of the current user matching the query
+func (ds todoService) GetAlltodo(ctxt *gin.Context, query model.TodoQuery) (*model.TodoPage, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	return ds.listTodos(id.(int), query, nil)
+}
+
+// listTodos fetches a page of the todos of the user matching the query and the expression of a smart list
+func (ds todoService) listTodos(userId int, query model.TodoQuery, list filter.Expr) (*model.TodoPage, error) {
+	switch query.Sort {
+	case "":
+		query.Sort = constants.TodoSortDue
+	case constants.TodoSortDue, constants.TodoSortPriority, constants.TodoSortCreated, constants.TodoSortTitle:
+	default:
+		return nil, &model.FieldError{Field: "sort", Message: "must be one of due, priority, created or title"}
+	}
+	if query.Limit == 0 {
+		query.Limit = constants.DefaultPageSize
+	}
+	if query.Limit < 0 || query.Limit > constants.MaxPageSize {
+		return nil, &model.FieldError{Field: "limit", Message: "must be between 1 and 200"}
+	}
+	tagIds, err := ds.checkTodoTags(userId, query.TagIds)
+	if err != nil {
+		return nil, err
+	}
+	query.TagIds = tagIds
+	expr, err := filter.Parse(query.Filter)
+	if err != nil {
+		return nil, filterError("filter", err)
+	}
+	if list != nil && expr != nil {
+		expr = filter.And{Exprs: []filter.Expr{list, expr}}
+	} else if list != nil {
+		expr = list
+	}
+
+	location := ds.userLocation(userId)
+	var dueBefore, dueAfter *model.DueBound
+	if query.DueBefore != nil {
+		bound := dueBound(*query.DueBefore, location)
+		dueBefore = &bound
+	}
+	if query.DueAfter != nil {
+		bound := dueBound(*query.DueAfter, location)
+		dueAfter = &bound
+	}
+	// overdue todos are the incomplete ones due before now, all day todos once their day has passed
+	if query.Overdue {
+		if query.Completed != nil && *query.Completed {
+			return nil, &model.FieldError{Field: "overdue", Message: "can not be combined with completed todos"}
+		}
+		completed := false
+		query.Completed = &completed
+		now := dueBound(model.DueDate{Time: time.Now()}, location)
+		if dueBefore == nil {
+			dueBefore = &now
+		}
+		if now.At.Before(dueBefore.At) {
+			dueBefore.At = now.At
+		}
+		if now.Date.Before(dueBefore.Date) {
+			dueBefore.Date = now.Date
+		}
+	}
+
+	filter.Resolve(expr, time.Now(), location)
+	page, err := ds.todoDatabase.ListTodos(userId, query, dueBefore, dueAfter, expr)
+	if errors.Is(err, database.ErrInvalidCursor) {
+		return nil, &model.FieldError{Field: "cursor", Message: "does not belong to this listing, start again without it"}
+	}
+	if err != nil {
+		return nil, errors.New("unable to fetch todos")
+	}
+	page.Todos, err = ds.prepareTodos(userId, page.Todos)
+	if err != nil {
+		return nil, err
+	}
+	if query.Dependencies {
+		if err := ds.attachDependencyFlags(userId, page.Todos); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// filterError reports a parse error of a filter expression as an error of the field holding it
+func filterError(field string, err error) error {
+	var parseErr *filter.Error
+	if errors.As(err, &parseErr) {
+		return &model.FieldError{Field: field, Message: parseErr.Message, Position: parseErr.Pos}
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"todo/auth"
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//EnrollTOTP method creates a new totp secret for the current user, it is only active once confirmed
+func (ds todoService) EnrollTOTP(ctxt *gin.Context) (*model.TOTPEnrollment, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	user, err := ds.todoDatabase.GetUserById(id.(int))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	existing, err := ds.todoDatabase.GetUserTOTP(user.ID)
+	if err == nil && existing.Confirmed {
+		return nil, errors.New("two factor authentication is already enabled")
+	}
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = ds.todoDatabase.SaveUserTOTP(&model.UserTOTP{
+		UserId:    user.ID,
+		Secret:    secret,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, errors.New("unable to enroll two factor authentication")
+	}
+	return &model.TOTPEnrollment{
+		Secret: secret,
+		URI:    auth.TOTPURI(constants.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+//ConfirmTOTP method enables two factor authentication once the user proves the authenticator works,
+//it returns the recovery codes which are not shown again
+func (ds todoService) ConfirmTOTP(ctxt *gin.Context, code string) (*model.RecoveryCodes, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	totp, err := ds.todoDatabase.GetUserTOTP(id.(int))
+	if err != nil {
+		return nil, errors.New("two factor authentication is not enrolled")
+	}
+	if totp.Confirmed {
+		return nil, errors.New("two factor authentication is already enabled")
+	}
+	if err := ds.checkTOTP(totp, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = ds.todoDatabase.ReplaceRecoveryCodes(totp.UserId, hashes)
+	if err != nil {
+		return nil, errors.New("unable to create recovery codes")
+	}
+	err = ds.todoDatabase.ConfirmUserTOTP(totp.UserId)
+	if err != nil {
+		return nil, errors.New("unable to enable two factor authentication")
+	}
+	return &model.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+//DisableTOTP method switches two factor authentication off, it needs a current code or a recovery code
+func (ds todoService) DisableTOTP(ctxt *gin.Context, code string) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if err := ds.verifySecondFactor(id.(int), code); err != nil {
+		return err
+	}
+	err := ds.todoDatabase.DeleteUserTOTP(id.(int))
+	if err != nil {
+		return errors.New("unable to disable two factor authentication")
+	}
+	return nil
+}
+
+//SignInTwoFactor method exchanges a challenge token and a second factor for the real tokens
+func (ds todoService) SignInTwoFactor(ctxt *gin.Context, input model.TwoFactorSignIn) (*model.SignInResponse, error) {
+	userId, err := auth.ParseChallengeToken(input.ChallengeToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+	getuser, err := ds.todoDatabase.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	// the account may have been disabled or flagged since the challenge token was issued
+	if getuser.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	if getuser.MustResetPassword {
+		return nil, ErrPasswordResetRequired
+	}
+	// wrong codes count as failed signins, just like wrong passwords
+	accountKey, ipKey := attemptKeys(ctxt, getuser.Email)
+	if err := ds.checkAttempts(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+	if err := ds.verifySecondFactor(userId, input.Code); err != nil {
+		ds.failAttempt(accountKey, ipKey)
+		return nil, err
+	}
+	ds.succeedAttempt(accountKey)
+	tokens, err := ds.newSession(getuser)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SignInResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		User:         getuser.Public(),
+	}, nil
+}
+
+// twoFactorEnabled reports whether the user has a confirmed authenticator
+func (ds todoService) twoFactorEnabled(userId int) bool {
+	totp, err := ds.todoDatabase.GetUserTOTP(userId)
+	return err == nil && totp.Confirmed
+}
+
+// verifySecondFactor accepts either a totp code or an unused recovery code
+func (ds todoService) verifySecondFactor(userId int, code string) error {
+	totp, err := ds.todoDatabase.GetUserTOTP(userId)
+	if err != nil || !totp.Confirmed {
+		return errors.New("two factor authentication is not enabled")
+	}
+	code = strings.TrimSpace(code)
+	if strings.Contains(code, "-") {
+		effect, err := ds.todoDatabase.UseRecoveryCode(userId, auth.HashToken(strings.ToLower(code)))
+		if err != nil || effect == 0 {
+			return errors.New("invalid recovery code")
+		}
+		return nil
+	}
+	return ds.checkTOTP(totp, code)
+}
+
+func (ds todoService) checkTOTP(totp *model.UserTOTP, code string) error {
+	step, ok := auth.ValidateTOTP(totp.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return errors.New("invalid code")
+	}
+	// a code can only be used once
+	effect, err := ds.todoDatabase.UseTOTPStep(totp.UserId, step)
+	if err != nil || effect == 0 {
+		return errors.New("code has already been used")
+	}
+	return nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	var codes, hashes []string
+	for i := 0; i < constants.RecoveryCodeCount; i++ {
+		code, err := auth.NewRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, auth.HashToken(code))
+	}
+	return codes, hashes, nil
+}
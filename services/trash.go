@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+	"todo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//GetTrash method lists the todos the current user may restore and its own trashed categories,
+//with the time each one is purged at
+func (ds todoService) GetTrash(ctxt *gin.Context) (*model.Trash, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todos, err := ds.todoDatabase.GetTrashedTodos(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch the trash")
+	}
+	categories, err := ds.todoDatabase.GetTrashedCategories(id.(int))
+	if err != nil {
+		return nil, errors.New("unable to fetch the trash")
+	}
+	restorable := []model.Todo{}
+	for _, todo := range *todos {
+		if ds.todoAccess(id.(int), &todo) >= accessEdit {
+			restorable = append(restorable, todo)
+		}
+	}
+	if _, err := ds.prepareTodos(id.(int), &restorable); err != nil {
+		return nil, err
+	}
+	retention := int64(trashRetention().Seconds())
+	trash := &model.Trash{
+		Todos:      []model.TrashedTodo{},
+		Categories: []model.TrashedCategory{},
+	}
+	for _, todo := range restorable {
+		trash.Todos = append(trash.Todos, model.TrashedTodo{Todo: todo, PurgeAt: *todo.DeletedAt + retention})
+	}
+	for _, category := range *categories {
+		category.Role = constants.MemberOwner
+		trash.Categories = append(trash.Categories, model.TrashedCategory{Category: category, PurgeAt: *category.DeletedAt + retention})
+	}
+	return trash, nil
+}
+
+//RestoreTodo method takes a todo the current user may change out of the trash, with the subtasks
+//deleted together with it
+func (ds todoService) RestoreTodo(ctxt *gin.Context, todoId int) (*model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.trashedTodo(id.(int), todoId)
+	if err != nil {
+		return nil, err
+	}
+	// a subtask can not come back below a todo which is still in the trash
+	if todo.ParentId != nil {
+		if _, err := ds.todoDatabase.GetTodoById(fmt.Sprint(*todo.ParentId)); err != nil {
+			return nil, errors.New("the parent of this todo is in the trash, restore it first")
+		}
+	}
+	// nor can a todo come back into a category which is still in the trash
+	if todo.Category != 0 {
+		if _, err := ds.todoDatabase.GetTrashedCategoryById(todo.Category); err == nil {
+			return nil, errors.New("the category of this todo is in the trash, restore it first")
+		}
+	}
+	effect, err := ds.todoDatabase.RestoreTodo(todo.ID)
+	if err != nil || effect == 0 {
+		return nil, errors.New("unable to restore todo")
+	}
+	ds.recordEvent(id.(int), todo.ID, constants.ActivityRestored, "", "")
+	todo.DeletedAt = nil
+	todos, err := ds.prepareTodos(id.(int), &[]model.Todo{*todo})
+	if err != nil {
+		return nil, err
+	}
+	return &(*todos)[0], nil
+}
+
+//PurgeTodo method deletes a todo in the trash the current user may change for good, with its subtasks
+//and attachments
+func (ds todoService) PurgeTodo(ctxt *gin.Context, todoId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.trashedTodo(id.(int), todoId)
+	if err != nil {
+		return err
+	}
+	return ds.purgeTodo(todo.ID)
+}
+
+//RestoreCategory method takes a category of the current user out of the trash, its members, workflow
+//and the todos trashed with it come back with it
+func (ds todoService) RestoreCategory(ctxt *gin.Context, categoryId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	category, err := ds.trashedCategory(id.(int), categoryId)
+	if err != nil {
+		return err
+	}
+	effect, err := ds.todoDatabase.RestoreCategory(category.ID)
+	if err != nil || effect == 0 {
+		return errors.New("unable to restore category")
+	}
+	return nil
+}
+
+//PurgeCategory method deletes a category of the current user in the trash for good, with its members,
+//workflow and the todos trashed with it
+func (ds todoService) PurgeCategory(ctxt *gin.Context, categoryId int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	category, err := ds.trashedCategory(id.(int), categoryId)
+	if err != nil {
+		return err
+	}
+	return ds.purgeCategory(category)
+}
+
+//PurgeTrash method deletes the todos and categories trashed before the given time for good, it is run
+//by the trash purger and returns the number of todos and categories purged
+func (ds todoService) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	todoIds, err := ds.todoDatabase.GetExpiredTodos(before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	categories, err := ds.todoDatabase.GetExpiredCategories(before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, todoId := range todoIds {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		// subtasks go with their parent, they may be gone already
+		if _, err := ds.todoDatabase.GetTrashedTodoById(todoId); err != nil {
+			continue
+		}
+		if err := ds.purgeTodo(todoId); err != nil {
+			log.Printf("unable to purge todo %d: %v", todoId, err)
+			continue
+		}
+		purged++
+	}
+	for i := range *categories {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		category := &(*categories)[i]
+		if err := ds.purgeCategory(category); err != nil {
+			log.Printf("unable to purge category %d: %v", category.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// trashedTodo fetches a todo in the trash the user may change
+func (ds todoService) trashedTodo(userId, todoId int) (*model.Todo, error) {
+	todo, err := ds.todoDatabase.GetTrashedTodoById(todoId)
+	if err != nil {
+		return nil, errors.New("todo is not in the trash")
+	}
+	if ds.todoAccess(userId, todo) < accessEdit {
+		return nil, errors.New("not Authorized to change this todo")
+	}
+	return todo, nil
+}
+
+// trashedCategory fetches a category of the user in the trash, the trashed categories of other users stay hidden
+func (ds todoService) trashedCategory(userId, categoryId int) (*model.Category, error) {
+	category, err := ds.todoDatabase.GetTrashedCategoryById(categoryId)
+	if err != nil || category.UserId != userId {
+		return nil, errors.New("category is not in the trash")
+	}
+	return category, nil
+}
+
+// purgeTodo deletes the todo with its subtasks for good, and then the blobs of their attachments
+func (ds todoService) purgeTodo(todoId int) error {
+	// the blobs of the attachments can only be found before their rows go
+	keys, err := ds.todoDatabase.GetSubtreeAttachmentKeys(todoId)
+	if err != nil {
+		return errors.New("unable to delete todo")
+	}
+	effect, err := ds.todoDatabase.DeleteTodo(fmt.Sprint(todoId))
+	if err != nil || effect == 0 {
+		return errors.New("unable to delete todo")
+	}
+	ds.deleteBlobs(keys)
+	return nil
+}
+
+// purgeCategory deletes the category for good, the workflow, the members and the todos trashed with the
+// category go with it
+func (ds todoService) purgeCategory(category *model.Category) error {
+	todoIds, err := ds.todoDatabase.GetCategoryTrashedTodos(category.ID)
+	if err != nil {
+		return errors.New("unable to delete the todos of the category")
+	}
+	for _, todoId := range todoIds {
+		if err := ds.purgeTodo(todoId); err != nil {
+			return err
+		}
+	}
+	effect, err := ds.todoDatabase.DeleteCategory(category.ID)
+	if err != nil || effect == 0 {
+		return errors.New("unable to delete category")
+	}
+	if _, err := ds.todoDatabase.DeleteWorkflow(category.UserId, category.ID); err != nil {
+		return errors.New("unable to delete the workflow of the category")
+	}
+	if err := ds.todoDatabase.DeleteCategoryMembers(category.ID); err != nil {
+		return errors.New("unable to delete the members of the category")
+	}
+	return nil
+}
+
+func trashRetention() time.Duration {
+	return utils.EnvDuration(constants.TrashRetentionEnv, constants.TrashRetention)
+}
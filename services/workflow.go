@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stateName keeps state names usable in filters and urls: lower case letters, digits and underscores
+var stateName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// defaultWorkflow applies to users and categories without a workflow of their own
+func defaultWorkflow() *model.Workflow {
+	todo, inProgress, blocked := constants.StatusTodo, constants.StatusInProgress, constants.StatusBlocked
+	done, cancelled := constants.StatusDone, constants.StatusCancelled
+	return &model.Workflow{
+		States: []model.WorkflowState{
+			{Name: todo},
+			{Name: inProgress},
+			{Name: blocked},
+			{Name: done, Final: true},
+			{Name: cancelled, Final: true},
+		},
+		Transitions: []model.WorkflowTransition{
+			{From: todo, To: inProgress}, {From: todo, To: blocked}, {From: todo, To: done}, {From: todo, To: cancelled},
+			{From: inProgress, To: todo}, {From: inProgress, To: blocked}, {From: inProgress, To: done}, {From: inProgress, To: cancelled},
+			{From: blocked, To: todo}, {From: blocked, To: inProgress}, {From: blocked, To: done}, {From: blocked, To: cancelled},
+			{From: done, To: todo},
+			{From: cancelled, To: todo},
+		},
+		Default: true,
+	}
+}
+
+//GetWorkflow method fetches the workflow in effect for a category the current user can see, category 0
+//asks for the workflow of the user
+func (ds todoService) GetWorkflow(ctxt *gin.Context, category int) (*model.Workflow, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	if _, err := ds.checkWorkflowCategory(id.(int), category, accessView); err != nil {
+		return nil, err
+	}
+	workflow := ds.workflowFor(id.(int), category)
+	workflow.Category = category
+	return workflow, nil
+}
+
+//SetWorkflow method replaces the workflow of the user or of a category it manages. Todos in a state
+//the new workflow lacks keep it until they move, and may then move to any state.
+func (ds todoService) SetWorkflow(ctxt *gin.Context, workflow *model.Workflow) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	owner, err := ds.checkWorkflowCategory(id.(int), workflow.Category, accessManage)
+	if err != nil {
+		return err
+	}
+	if err := checkWorkflow(workflow); err != nil {
+		return err
+	}
+	err = ds.todoDatabase.SetWorkflow(owner, workflow)
+	if err != nil {
+		return errors.New("unable to save workflow")
+	}
+	return nil
+}
+
+//DeleteWorkflow method removes the workflow of the user or of a category it manages,
+//the category falls back to the workflow of its owner and the user to the default one
+func (ds todoService) DeleteWorkflow(ctxt *gin.Context, category int) error {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	owner, err := ds.checkWorkflowCategory(id.(int), category, accessManage)
+	if err != nil {
+		return err
+	}
+	effect, err := ds.todoDatabase.DeleteWorkflow(owner, category)
+	if err != nil {
+		return errors.New("unable to delete workflow")
+	}
+	if effect == 0 {
+		return errors.New("no workflow is set up here")
+	}
+	return nil
+}
+
+//ChangeStatus method moves a todo to another state of its workflow, if the workflow allows the transition
+func (ds todoService) ChangeStatus(ctxt *gin.Context, input model.ChangeStatus) (*model.Todo, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, input.ID, accessEdit)
+	if err != nil {
+		return nil, err
+	}
+	workflow := ds.workflowFor(todo.UserId, todo.Category)
+	if _, found := findState(workflow, input.Status); !found {
+		return nil, &model.FieldError{Field: "status", Message: "must be one of " + stateNames(workflow)}
+	}
+	if input.Status != todo.Status {
+		if !canMove(workflow, todo.Status, input.Status) {
+			return nil, fmt.Errorf("todo can not move from %s to %s", todo.Status, input.Status)
+		}
+		// a todo waiting for open todos is only completed when forced
+		if state, _ := findState(workflow, input.Status); state.Final && !todo.Completed && !input.Force {
+			if err := ds.checkBlockers(id.(int), todo); err != nil {
+				return nil, err
+			}
+		}
+		if err := ds.recordStatus(id.(int), todo, workflow, input.Status); err != nil {
+			return nil, err
+		}
+	}
+	todos, err := ds.prepareTodos(todo.UserId, &[]model.Todo{*todo})
+	if err != nil {
+		return nil, err
+	}
+	return &(*todos)[0], nil
+}
+
+//GetStatusHistory method lists the transitions of a todo of the current user, oldest first
+func (ds todoService) GetStatusHistory(ctxt *gin.Context, todoId int) (*[]model.StatusChange, error) {
+	// fetch the user-id from context
+	id, _ := ctxt.Get("user-id")
+	todo, err := ds.authorizedTodo(id, todoId, accessView)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := ds.todoDatabase.GetStatusChanges(todo.ID)
+	if err != nil {
+		return nil, errors.New("unable to fetch status history")
+	}
+	return changes, nil
+}
+
+// checkWorkflowCategory checks the user may do what need stands for in the category, 0 stands for the
+// user itself. The workflows of a category are kept with its owner, whose id is returned.
+func (ds todoService) checkWorkflowCategory(userId, category, need int) (int, error) {
+	if category == 0 {
+		return userId, nil
+	}
+	if err := ds.authorizeCategory(userId, category, need); err != nil {
+		return 0, err
+	}
+	owner, err := ds.todoDatabase.GetCategoryUserById(category)
+	if err != nil {
+		return 0, errors.New("category does not exist for this user")
+	}
+	return *owner, nil
+}
+
+// checkWorkflow validates the states and transitions of a workflow. New todos start in the first state,
+// so it can not be final, and a final state is needed to complete todos. Without transitions every move is allowed.
+func checkWorkflow(workflow *model.Workflow) error {
+	if len(workflow.States) == 0 || len(workflow.States) > constants.MaxWorkflowStates {
+		return &model.FieldError{Field: "states", Message: fmt.Sprintf("must have 1 to %d states", constants.MaxWorkflowStates)}
+	}
+	final := false
+	seen := map[string]bool{}
+	for i := range workflow.States {
+		state := &workflow.States[i]
+		state.Name = strings.TrimSpace(state.Name)
+		if !stateName.MatchString(state.Name) {
+			return &model.FieldError{Field: "states", Message: fmt.Sprintf("has an invalid name %q, use lower case letters, digits and _", state.Name)}
+		}
+		if seen[state.Name] {
+			return &model.FieldError{Field: "states", Message: "has " + state.Name + " twice"}
+		}
+		seen[state.Name] = true
+		final = final || state.Final
+	}
+	if workflow.States[0].Final {
+		return &model.FieldError{Field: "states", Message: "must not start with a final state, new todos start in the first one"}
+	}
+	if !final {
+		return &model.FieldError{Field: "states", Message: "needs a final state to complete todos"}
+	}
+	moves := map[model.WorkflowTransition]bool{}
+	for _, transition := range workflow.Transitions {
+		if !seen[transition.From] || !seen[transition.To] {
+			return &model.FieldError{Field: "transitions", Message: fmt.Sprintf("has %s to %s between unknown states", transition.From, transition.To)}
+		}
+		if transition.From == transition.To || moves[transition] {
+			return &model.FieldError{Field: "transitions", Message: fmt.Sprintf("has %s to %s twice or from a state to itself", transition.From, transition.To)}
+		}
+		moves[transition] = true
+	}
+	workflow.Default = false
+	return nil
+}
+
+// workflowFor is the workflow of the category, or else the one of the user, or else the default one.
+// Todos in a category follow the workflows of the category owner, whoever created them.
+func (ds todoService) workflowFor(userId, category int) *model.Workflow {
+	if category != 0 {
+		if owner, err := ds.todoDatabase.GetCategoryUserById(category); err == nil {
+			userId = *owner
+		}
+		if workflow, err := ds.todoDatabase.GetWorkflow(userId, category); err == nil {
+			return workflow
+		}
+	}
+	if workflow, err := ds.todoDatabase.GetWorkflow(userId, 0); err == nil {
+		return workflow
+	}
+	return defaultWorkflow()
+}
+
+func findState(workflow *model.Workflow, name string) (model.WorkflowState, bool) {
+	for _, state := range workflow.States {
+		if state.Name == name {
+			return state, true
+		}
+	}
+	return model.WorkflowState{}, false
+}
+
+func stateNames(workflow *model.Workflow) string {
+	var names []string
+	for _, state := range workflow.States {
+		names = append(names, state.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// canMove tells whether the workflow allows the transition, todos in a state the workflow
+// does not know, after a change of workflow or category, may move anywhere
+func canMove(workflow *model.Workflow, from, to string) bool {
+	if _, found := findState(workflow, from); !found || len(workflow.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range workflow.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// completedStatus is the state that completes or reopens the todo: the current one when it is of the
+// right kind already, else the first final or open state the todo can move to
+func completedStatus(workflow *model.Workflow, todo *model.Todo, completed bool) (string, error) {
+	if state, found := findState(workflow, todo.Status); found && state.Final == completed {
+		return todo.Status, nil
+	}
+	for _, state := range workflow.States {
+		if state.Final == completed && canMove(workflow, todo.Status, state.Name) {
+			return state.Name, nil
+		}
+	}
+	if completed {
+		return "", fmt.Errorf("todo can not move from %s to a final state", todo.Status)
+	}
+	return "", fmt.Errorf("todo can not move from %s to an open state", todo.Status)
+}
+
+// initialStatus is the state new todos start in, or the first final one for todos created as completed
+func (ds todoService) initialStatus(userId, category int, completed bool) string {
+	workflow := ds.workflowFor(userId, category)
+	for _, state := range workflow.States {
+		if state.Final == completed {
+			return state.Name
+		}
+	}
+	return workflow.States[0].Name
+}
+
+// markCompleted moves the todo to a final or open state on behalf of the user, for the clients and
+// features which only know about completed
+func (ds todoService) markCompleted(userId int, todo *model.Todo, completed bool) error {
+	workflow := ds.workflowFor(todo.UserId, todo.Category)
+	status, err := completedStatus(workflow, todo, completed)
+	if err != nil {
+		return err
+	}
+	if status == todo.Status {
+		if completed {
+			ds.rollupCompletion(todo.ParentId)
+		}
+		return nil
+	}
+	return ds.recordStatus(userId, todo, workflow, status)
+}
+
+// recordStatus saves the new state of the todo with the transition, a todo which gets completed
+// completes its parent if asked to and moves a recurring series on
+func (ds todoService) recordStatus(userId int, todo *model.Todo, workflow *model.Workflow, status string) error {
+	state, _ := findState(workflow, status)
+	change := &model.StatusChange{
+		TodoId:    todo.ID,
+		UserId:    userId,
+		From:      todo.Status,
+		To:        status,
+		ChangedAt: time.Now().Unix(),
+	}
+	if err := ds.todoDatabase.UpdateTodoStatus(change, state.Final); err != nil {
+		return errors.New("unable to change status")
+	}
+	wasCompleted := todo.Completed
+	todo.Status, todo.Completed = status, state.Final
+	if todo.Completed {
+		ds.rollupCompletion(todo.ParentId)
+		if !wasCompleted {
+			ds.nextOccurrence(userId, todo)
+		}
+	}
+	return nil
+}
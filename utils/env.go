@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+//EnvBool reads a boolean setting from the environment, falling back to def when unset or invalid
+func EnvBool(name string, def bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %v", name, value, def)
+		return def
+	}
+	return parsed
+}
+
+//EnvInt reads an integer setting from the environment, falling back to def when unset or invalid
+func EnvInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %v", name, value, def)
+		return def
+	}
+	return parsed
+}
+
+//EnvDuration reads a duration setting such as 36h from the environment, falling back to def when unset or invalid
+func EnvDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %v", name, value, def)
+		return def
+	}
+	return parsed
+}
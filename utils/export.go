@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo/constants"
+	"todo/model"
+)
+
+const exportFile = "export.json"
+
+//ExportZip packs the export as a zip holding the json document and a csv file per table
+func ExportZip(export *model.Export) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	document, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(archive, exportFile, document); err != nil {
+		return nil, err
+	}
+	categories := [][]string{{"id", "name"}}
+	for _, category := range export.Categories {
+		categories = append(categories, []string{strconv.Itoa(category.ID), category.Name})
+	}
+	if err := writeZipCSV(archive, "categories.csv", categories); err != nil {
+		return nil, err
+	}
+	tags := [][]string{{"id", "name", "color"}}
+	for _, tag := range export.Tags {
+		tags = append(tags, []string{strconv.Itoa(tag.ID), tag.Name, tag.Color})
+	}
+	if err := writeZipCSV(archive, "tags.csv", tags); err != nil {
+		return nil, err
+	}
+	todos := [][]string{{"id", "title", "description", "dueDate", "allDay", "priority", "completed", "category", "tags"}}
+	for _, todo := range export.Todos {
+		// the tag ids of a todo share one column
+		todoTags := make([]string, len(todo.Tags))
+		for i, tag := range todo.Tags {
+			todoTags[i] = strconv.Itoa(tag)
+		}
+		todos = append(todos, []string{strconv.Itoa(todo.ID), todo.Title, todo.Description, todo.DueDate.String(), strconv.FormatBool(todo.AllDay), todo.Priority, strconv.FormatBool(todo.Completed), strconv.Itoa(todo.Category), strings.Join(todoTags, ";")})
+	}
+	if err := writeZipCSV(archive, "todos.csv", todos); err != nil {
+		return nil, err
+	}
+	reminders := [][]string{{"id", "todoId", "remindAt", "offsetMinutes", "status"}}
+	for _, reminder := range export.Reminders {
+		// a reminder has either a time or an offset before the due date
+		remindAt, offset := "", ""
+		if reminder.RemindAt != nil {
+			remindAt = reminder.RemindAt.UTC().Format(time.RFC3339)
+		}
+		if reminder.OffsetMinutes != nil {
+			offset = strconv.Itoa(*reminder.OffsetMinutes)
+		}
+		reminders = append(reminders, []string{strconv.Itoa(reminder.ID), strconv.Itoa(reminder.TodoId), remindAt, offset, reminder.Status})
+	}
+	if err := writeZipCSV(archive, "reminders.csv", reminders); err != nil {
+		return nil, err
+	}
+	lists := [][]string{{"id", "name", "query"}}
+	for _, list := range export.SmartLists {
+		lists = append(lists, []string{strconv.Itoa(list.ID), list.Name, list.Query})
+	}
+	if err := writeZipCSV(archive, "smart_lists.csv", lists); err != nil {
+		return nil, err
+	}
+	dependencies := [][]string{{"todoId", "blockerId"}}
+	for _, dependency := range export.Dependencies {
+		dependencies = append(dependencies, []string{strconv.Itoa(dependency.TodoId), strconv.Itoa(dependency.BlockerId)})
+	}
+	if err := writeZipCSV(archive, "dependencies.csv", dependencies); err != nil {
+		return nil, err
+	}
+	comments := [][]string{{"id", "todoId", "body", "createdAt"}}
+	for _, comment := range export.Comments {
+		comments = append(comments, []string{strconv.Itoa(comment.ID), strconv.Itoa(comment.TodoId), comment.Body, strconv.FormatInt(comment.CreatedAt, 10)})
+	}
+	if err := writeZipCSV(archive, "comments.csv", comments); err != nil {
+		return nil, err
+	}
+	attachments := [][]string{{"id", "todoId", "fileName", "contentType", "size"}}
+	for _, attachment := range export.Attachments {
+		attachments = append(attachments, []string{strconv.Itoa(attachment.ID), strconv.Itoa(attachment.TodoId), attachment.FileName, attachment.ContentType, strconv.FormatInt(attachment.Size, 10)})
+	}
+	if err := writeZipCSV(archive, "attachments.csv", attachments); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//ParseExport reads an export from either the json document or a zip made by ExportZip
+func ParseExport(data []byte) (*model.Export, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		data = nil
+		for _, file := range archive.File {
+			if file.Name != exportFile {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			// the archive size is bounded by the request, the unpacked document needs a bound of its own
+			data, err = ioutil.ReadAll(io.LimitReader(reader, constants.MaxImportSize+1))
+			reader.Close()
+			if err != nil {
+				return nil, err
+			}
+			if len(data) > constants.MaxImportSize {
+				return nil, fmt.Errorf("%s must be at most %d bytes", exportFile, constants.MaxImportSize)
+			}
+		}
+		if data == nil {
+			return nil, errors.New("archive has no " + exportFile)
+		}
+	}
+	var export model.Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, errors.New("invalid export document")
+	}
+	if export.Version < 1 {
+		return nil, errors.New("export document has no version")
+	}
+	return &export, nil
+}
+
+func writeZipFile(archive *zip.Writer, name string, content []byte) error {
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	return err
+}
+
+func writeZipCSV(archive *zip.Writer, name string, records [][]string) error {
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.WriteAll(records); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
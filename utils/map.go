@@ -9,8 +9,11 @@ func EditTodoMap(todoInput model.EditTodo, todo model.Todo) model.EditTodo {
 	if todoInput.Description == "" {
 		todoInput.Description = todo.Description
 	}
-	if todoInput.DueDate == "" {
-		todoInput.DueDate = todo.DueDate
+	if todoInput.DueDate == nil {
+		todoInput.DueDate = &todo.DueDate
+	}
+	if todoInput.AllDay == nil {
+		todoInput.AllDay = &todo.AllDay
 	}
 	if todoInput.Priority == "" {
 		todoInput.Priority = todo.Priority
@@ -21,6 +24,18 @@ func EditTodoMap(todoInput model.EditTodo, todo model.Todo) model.EditTodo {
 	if todoInput.Category == 0 {
 		todoInput.Category = todo.Category
 	}
+	if todoInput.ParentId == nil {
+		todoInput.ParentId = todo.ParentId
+	}
+	if todoInput.AutoComplete == nil {
+		todoInput.AutoComplete = &todo.AutoComplete
+	}
+	if todoInput.Recurrence == nil {
+		todoInput.Recurrence = &todo.Recurrence
+	}
+	if todoInput.RecurrenceAnchor == nil {
+		todoInput.RecurrenceAnchor = &todo.RecurrenceAnchor
+	}
 	todoInput.UserId = todo.UserId
 	return todoInput
 }